@@ -23,9 +23,12 @@ import (
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 
+	"github.com/jarri-abidi/todo/pkg/blob"
 	"github.com/jarri-abidi/todo/pkg/checklist"
 	"github.com/jarri-abidi/todo/pkg/inmem"
+	"github.com/jarri-abidi/todo/pkg/notify"
 	"github.com/jarri-abidi/todo/pkg/postgres"
+	"github.com/jarri-abidi/todo/pkg/todo"
 )
 
 func main() {
@@ -44,17 +47,39 @@ func main() {
 		ServerWriteTimeout         time.Duration `envconfig:"SERVER_WRITE_TIMEOUT" default:"15s"`
 		ServerReadTimeout          time.Duration `envconfig:"SERVER_READ_TIMEOUT" default:"15s"`
 		ServerIdleTimeout          time.Duration `envconfig:"SERVER_IDLE_TIMEOUT" default:"60s"`
+		ServerMaxBodySize          int64         `envconfig:"SERVER_MAX_BODY_SIZE" default:"1048576"`
 		GracefulShutdownTimeout    time.Duration `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT" default:"30s"`
 		DBSource                   string        `envconfig:"DB_SOURCE"`
 		DBConnectTimeout           time.Duration `envconfig:"DB_CONNECT_TIMEOUT"`
 		OTELExporterJaegerEndpoint string        `envconfig:"OTEL_EXPORTER_JAEGER_ENDPOINT"`
+		MaxSubtaskDepth            int           `envconfig:"MAX_SUBTASK_DEPTH" default:"5"`
+		TrashRetention             time.Duration `envconfig:"TRASH_RETENTION" default:"720h"`
+		ArchiveAfter               time.Duration `envconfig:"ARCHIVE_AFTER" default:"168h"`
+		ArchiveInterval            time.Duration `envconfig:"ARCHIVE_INTERVAL" default:"1h"`
+		ReminderInterval           time.Duration `envconfig:"REMINDER_INTERVAL" default:"1m"`
+		Notifier                   string        `envconfig:"NOTIFIER" default:"log"`
+		WebhookURL                 string        `envconfig:"WEBHOOK_URL"`
+		WebhookTimeout             time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+		SMTPAddress                string        `envconfig:"SMTP_ADDRESS" default:"localhost:1025"`
+		SMTPFrom                   string        `envconfig:"SMTP_FROM" default:"todo@localhost"`
+		SMTPTo                     []string      `envconfig:"SMTP_TO"`
+		BlobStore                  string        `envconfig:"BLOB_STORE" default:"local"`
+		BlobDir                    string        `envconfig:"BLOB_DIR" default:"attachments"`
+		S3Endpoint                 string        `envconfig:"S3_ENDPOINT"`
+		S3Region                   string        `envconfig:"S3_REGION" default:"us-east-1"`
+		S3Bucket                   string        `envconfig:"S3_BUCKET"`
+		S3AccessKey                string        `envconfig:"S3_ACCESS_KEY"`
+		S3SecretKey                string        `envconfig:"S3_SECRET_KEY"`
+		MaxAttachmentSize          int64         `envconfig:"MAX_ATTACHMENT_SIZE" default:"10485760"`
+		AttachmentTypes            []string      `envconfig:"ATTACHMENT_TYPES"`
 	}
 	if err := envconfig.Process("TODOAPP", &config); err != nil {
 		logger.Log("msg", "could not load env vars", "err", err)
 		os.Exit(1)
 	}
 
-	tasks := inmem.NewTaskRepository()
+	repositories := inmem.NewRepositories()
+	unitOfWork := inmem.NewUnitOfWork(repositories)
 
 	if config.DBSource != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), config.DBConnectTimeout)
@@ -70,7 +95,8 @@ func main() {
 			os.Exit(1)
 		}
 
-		tasks = postgres.NewTaskRepository(db)
+		repositories = postgres.NewRepositories(db)
+		unitOfWork = postgres.NewUnitOfWork(db)
 
 		defer func() {
 			if err := db.Close(); err != nil {
@@ -111,12 +137,88 @@ func main() {
 		}()
 	}
 
+	var blobs todo.BlobStore
+	switch config.BlobStore {
+	case "local":
+		var err error
+		if blobs, err = blob.NewLocalStore(config.BlobDir); err != nil {
+			logger.Log("msg", "could not create local blob store", "dir", config.BlobDir, "err", err)
+			os.Exit(1)
+		}
+	case "s3":
+		blobs = blob.NewS3Store(blob.S3Config{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+		}, http.DefaultClient)
+	default:
+		logger.Log("msg", "unknown blob store, expected one of local or s3", "blob_store", config.BlobStore)
+		os.Exit(1)
+	}
+
+	attachmentTypes := checklist.DefaultAttachmentTypes
+	if len(config.AttachmentTypes) > 0 {
+		attachmentTypes = config.AttachmentTypes
+	}
+
 	var service checklist.Service
-	service = checklist.NewService(tasks)
+	service = checklist.NewService(
+		repositories,
+		blobs,
+		checklist.WithMaxSubtaskDepth(config.MaxSubtaskDepth),
+		checklist.WithTrashRetention(config.TrashRetention),
+		checklist.WithArchiveAfter(config.ArchiveAfter),
+		checklist.WithMaxAttachmentSize(config.MaxAttachmentSize),
+		checklist.WithAttachmentTypes(attachmentTypes...),
+		checklist.WithUnitOfWork(unitOfWork),
+	)
 	service = checklist.LoggingMiddleware(logger)(service)
 
+	if config.ArchiveAfter > 0 && config.ArchiveInterval > 0 {
+		archiver := checklist.NewArchiver(service, config.ArchiveInterval)
+		archiver.Start()
+		logger.Log("msg", "started archiver", "after", config.ArchiveAfter, "interval", config.ArchiveInterval)
+
+		defer func() {
+			archiver.Stop()
+			logger.Log("msg", "stopped archiver")
+		}()
+	}
+
+	var notifier todo.Notifier
+	switch config.Notifier {
+	case "log":
+		notifier = notify.NewLogNotifier(log.With(logger, "notifier", "log"))
+	case "webhook":
+		notifier = notify.NewWebhookNotifier(config.WebhookURL, &http.Client{Timeout: config.WebhookTimeout})
+	case "smtp":
+		notifier = notify.NewSMTPNotifier(config.SMTPAddress, config.SMTPFrom, config.SMTPTo)
+	default:
+		logger.Log("msg", "unknown notifier, expected one of log, webhook or smtp", "notifier", config.Notifier)
+		os.Exit(1)
+	}
+
+	if config.ReminderInterval > 0 {
+		scheduler := checklist.NewReminderScheduler(service, notifier, config.ReminderInterval)
+		scheduler.Start()
+		logger.Log("msg", "started reminder scheduler", "notifier", config.Notifier, "interval", config.ReminderInterval)
+
+		defer func() {
+			scheduler.Stop()
+			logger.Log("msg", "stopped reminder scheduler")
+		}()
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle("/checklist/v1/", checklist.NewServer(service, logger))
+	mux.Handle("/checklist/v1/", checklist.NewServer(
+		service,
+		logger,
+		checklist.WithMaxBodySize(config.ServerMaxBodySize),
+		// uploads may be as large as an attachment plus the multipart headers around it
+		checklist.WithRouteMaxBodySize("POST", "/checklist/v1/task/:id/attachments", config.MaxAttachmentSize+1<<20),
+	))
 	mux.Handle("/metrics", promhttp.Handler())
 
 	server := &http.Server{
@@ -0,0 +1,54 @@
+package checklist
+
+import (
+	"context"
+	"time"
+)
+
+// Archiver periodically archives the tasks that have been done for longer
+// than the archive period of its service, see Service.ArchiveCompleted.
+type Archiver struct {
+	service  Service
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewArchiver returns an Archiver that runs every interval, which must be positive.
+// Errors aren't returned but left to be logged by the service's middleware.
+func NewArchiver(service Service, interval time.Duration) *Archiver {
+	return &Archiver{
+		service:  service,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start archives tasks right away and then once every interval until Stop is called.
+func (a *Archiver) Start() {
+	go a.run()
+}
+
+// Stop stops the archiver, waiting for a run that is in progress to finish.
+// It must be called at most once, and only after Start.
+func (a *Archiver) Stop() {
+	close(a.stop)
+	<-a.done
+}
+
+func (a *Archiver) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		a.service.ArchiveCompleted(context.Background())
+
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
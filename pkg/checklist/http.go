@@ -3,8 +3,12 @@ package checklist
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log"
@@ -15,102 +19,2351 @@ import (
 	"github.com/jarri-abidi/todo/pkg/todo"
 )
 
-func NewServer(service Service, logger log.Logger) http.Handler {
-	s := server{service: service}
+// NewServer returns the HTTP handler of the checklist API. Request bodies are limited to
+// DefaultMaxBodySize, or DefaultMaxUploadSize for attachment uploads, unless configured otherwise.
+func NewServer(service Service, logger log.Logger, opts ...ServerOption) http.Handler {
+	s := server{
+		service:     service,
+		maxBodySize: DefaultMaxBodySize,
+		routeMaxBodySizes: map[string]int64{
+			"POST /checklist/v1/task/:id/attachments": DefaultMaxUploadSize,
+		},
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	var handleSaveTask http.Handler
+	handleSaveTask = s.handleSaveTask()
+	handleSaveTask = httpLoggingMiddleware(logger, "handleSaveTask")(handleSaveTask)
+	handleSaveTask = otelhttp.NewHandler(handleSaveTask, "handleSaveTask")
+	// wire other middlewares here
+
+	var handleListTasks http.Handler
+	handleListTasks = s.handleListTasks()
+	handleListTasks = httpLoggingMiddleware(logger, "handleListTasks")(handleListTasks)
+	handleListTasks = otelhttp.NewHandler(handleListTasks, "handleListTasks")
+
+	var handleGetTask http.Handler
+	handleGetTask = s.handleGetTask()
+	handleGetTask = httpLoggingMiddleware(logger, "handleGetTask")(handleGetTask)
+	handleGetTask = otelhttp.NewHandler(handleGetTask, "handleGetTask")
+
+	var handleRemoveTask http.Handler
+	handleRemoveTask = s.handleRemoveTask()
+	handleRemoveTask = httpLoggingMiddleware(logger, "handleRemoveTask")(handleRemoveTask)
+	handleRemoveTask = otelhttp.NewHandler(handleRemoveTask, "handleRemoveTask")
+
+	var handleToggleTask http.Handler
+	handleToggleTask = s.handleToggleTask()
+	handleToggleTask = httpLoggingMiddleware(logger, "handleToggleTask")(handleToggleTask)
+	handleToggleTask = otelhttp.NewHandler(handleToggleTask, "handleToggleTask")
+
+	var handleUpdateTask http.Handler
+	handleUpdateTask = s.handleUpdateTask()
+	handleUpdateTask = httpLoggingMiddleware(logger, "handleUpdateTask")(handleUpdateTask)
+	handleUpdateTask = otelhttp.NewHandler(handleUpdateTask, "handleUpdateTask")
+
+	var handleListTags http.Handler
+	handleListTags = s.handleListTags()
+	handleListTags = httpLoggingMiddleware(logger, "handleListTags")(handleListTags)
+	handleListTags = otelhttp.NewHandler(handleListTags, "handleListTags")
+
+	var handleListSubtasks http.Handler
+	handleListSubtasks = s.handleListSubtasks()
+	handleListSubtasks = httpLoggingMiddleware(logger, "handleListSubtasks")(handleListSubtasks)
+	handleListSubtasks = otelhttp.NewHandler(handleListSubtasks, "handleListSubtasks")
+
+	var handleTransitionTask http.Handler
+	handleTransitionTask = s.handleTransitionTask()
+	handleTransitionTask = httpLoggingMiddleware(logger, "handleTransitionTask")(handleTransitionTask)
+	handleTransitionTask = otelhttp.NewHandler(handleTransitionTask, "handleTransitionTask")
+
+	var handleMoveTask http.Handler
+	handleMoveTask = s.handleMoveTask()
+	handleMoveTask = httpLoggingMiddleware(logger, "handleMoveTask")(handleMoveTask)
+	handleMoveTask = otelhttp.NewHandler(handleMoveTask, "handleMoveTask")
+
+	var handleListTaskHistory http.Handler
+	handleListTaskHistory = s.handleListTaskHistory()
+	handleListTaskHistory = httpLoggingMiddleware(logger, "handleListTaskHistory")(handleListTaskHistory)
+	handleListTaskHistory = otelhttp.NewHandler(handleListTaskHistory, "handleListTaskHistory")
+
+	var handleListBlockers http.Handler
+	handleListBlockers = s.handleListBlockers()
+	handleListBlockers = httpLoggingMiddleware(logger, "handleListBlockers")(handleListBlockers)
+	handleListBlockers = otelhttp.NewHandler(handleListBlockers, "handleListBlockers")
+
+	var handleAddBlocker http.Handler
+	handleAddBlocker = s.handleAddBlocker()
+	handleAddBlocker = httpLoggingMiddleware(logger, "handleAddBlocker")(handleAddBlocker)
+	handleAddBlocker = otelhttp.NewHandler(handleAddBlocker, "handleAddBlocker")
+
+	var handleRemoveBlocker http.Handler
+	handleRemoveBlocker = s.handleRemoveBlocker()
+	handleRemoveBlocker = httpLoggingMiddleware(logger, "handleRemoveBlocker")(handleRemoveBlocker)
+	handleRemoveBlocker = otelhttp.NewHandler(handleRemoveBlocker, "handleRemoveBlocker")
+
+	var handleAddComment http.Handler
+	handleAddComment = s.handleAddComment()
+	handleAddComment = httpLoggingMiddleware(logger, "handleAddComment")(handleAddComment)
+	handleAddComment = otelhttp.NewHandler(handleAddComment, "handleAddComment")
+
+	var handleListComments http.Handler
+	handleListComments = s.handleListComments()
+	handleListComments = httpLoggingMiddleware(logger, "handleListComments")(handleListComments)
+	handleListComments = otelhttp.NewHandler(handleListComments, "handleListComments")
+
+	var handleEditComment http.Handler
+	handleEditComment = s.handleEditComment()
+	handleEditComment = httpLoggingMiddleware(logger, "handleEditComment")(handleEditComment)
+	handleEditComment = otelhttp.NewHandler(handleEditComment, "handleEditComment")
+
+	var handleRemoveComment http.Handler
+	handleRemoveComment = s.handleRemoveComment()
+	handleRemoveComment = httpLoggingMiddleware(logger, "handleRemoveComment")(handleRemoveComment)
+	handleRemoveComment = otelhttp.NewHandler(handleRemoveComment, "handleRemoveComment")
+
+	var handleStartTimer http.Handler
+	handleStartTimer = s.handleStartTimer()
+	handleStartTimer = httpLoggingMiddleware(logger, "handleStartTimer")(handleStartTimer)
+	handleStartTimer = otelhttp.NewHandler(handleStartTimer, "handleStartTimer")
+
+	var handleStopTimer http.Handler
+	handleStopTimer = s.handleStopTimer()
+	handleStopTimer = httpLoggingMiddleware(logger, "handleStopTimer")(handleStopTimer)
+	handleStopTimer = otelhttp.NewHandler(handleStopTimer, "handleStopTimer")
+
+	var handleAddTimeEntry http.Handler
+	handleAddTimeEntry = s.handleAddTimeEntry()
+	handleAddTimeEntry = httpLoggingMiddleware(logger, "handleAddTimeEntry")(handleAddTimeEntry)
+	handleAddTimeEntry = otelhttp.NewHandler(handleAddTimeEntry, "handleAddTimeEntry")
+
+	var handleListTimeEntries http.Handler
+	handleListTimeEntries = s.handleListTimeEntries()
+	handleListTimeEntries = httpLoggingMiddleware(logger, "handleListTimeEntries")(handleListTimeEntries)
+	handleListTimeEntries = otelhttp.NewHandler(handleListTimeEntries, "handleListTimeEntries")
+
+	var handleRemoveTimeEntry http.Handler
+	handleRemoveTimeEntry = s.handleRemoveTimeEntry()
+	handleRemoveTimeEntry = httpLoggingMiddleware(logger, "handleRemoveTimeEntry")(handleRemoveTimeEntry)
+	handleRemoveTimeEntry = otelhttp.NewHandler(handleRemoveTimeEntry, "handleRemoveTimeEntry")
+
+	var handleSummarizeTaskTime http.Handler
+	handleSummarizeTaskTime = s.handleSummarizeTaskTime()
+	handleSummarizeTaskTime = httpLoggingMiddleware(logger, "handleSummarizeTaskTime")(handleSummarizeTaskTime)
+	handleSummarizeTaskTime = otelhttp.NewHandler(handleSummarizeTaskTime, "handleSummarizeTaskTime")
+
+	var handleAddReminder http.Handler
+	handleAddReminder = s.handleAddReminder()
+	handleAddReminder = httpLoggingMiddleware(logger, "handleAddReminder")(handleAddReminder)
+	handleAddReminder = otelhttp.NewHandler(handleAddReminder, "handleAddReminder")
+
+	var handleListReminders http.Handler
+	handleListReminders = s.handleListReminders()
+	handleListReminders = httpLoggingMiddleware(logger, "handleListReminders")(handleListReminders)
+	handleListReminders = otelhttp.NewHandler(handleListReminders, "handleListReminders")
+
+	var handleRemoveReminder http.Handler
+	handleRemoveReminder = s.handleRemoveReminder()
+	handleRemoveReminder = httpLoggingMiddleware(logger, "handleRemoveReminder")(handleRemoveReminder)
+	handleRemoveReminder = otelhttp.NewHandler(handleRemoveReminder, "handleRemoveReminder")
+
+	var handleAddAttachment http.Handler
+	handleAddAttachment = s.handleAddAttachment()
+	handleAddAttachment = httpLoggingMiddleware(logger, "handleAddAttachment")(handleAddAttachment)
+	handleAddAttachment = otelhttp.NewHandler(handleAddAttachment, "handleAddAttachment")
+
+	var handleListAttachments http.Handler
+	handleListAttachments = s.handleListAttachments()
+	handleListAttachments = httpLoggingMiddleware(logger, "handleListAttachments")(handleListAttachments)
+	handleListAttachments = otelhttp.NewHandler(handleListAttachments, "handleListAttachments")
+
+	var handleDownloadAttachment http.Handler
+	handleDownloadAttachment = s.handleDownloadAttachment()
+	handleDownloadAttachment = httpLoggingMiddleware(logger, "handleDownloadAttachment")(handleDownloadAttachment)
+	handleDownloadAttachment = otelhttp.NewHandler(handleDownloadAttachment, "handleDownloadAttachment")
+
+	var handleRemoveAttachment http.Handler
+	handleRemoveAttachment = s.handleRemoveAttachment()
+	handleRemoveAttachment = httpLoggingMiddleware(logger, "handleRemoveAttachment")(handleRemoveAttachment)
+	handleRemoveAttachment = otelhttp.NewHandler(handleRemoveAttachment, "handleRemoveAttachment")
+
+	var handleAssignTask http.Handler
+	handleAssignTask = s.handleAssignTask()
+	handleAssignTask = httpLoggingMiddleware(logger, "handleAssignTask")(handleAssignTask)
+	handleAssignTask = otelhttp.NewHandler(handleAssignTask, "handleAssignTask")
+
+	var handleUnassignTask http.Handler
+	handleUnassignTask = s.handleUnassignTask()
+	handleUnassignTask = httpLoggingMiddleware(logger, "handleUnassignTask")(handleUnassignTask)
+	handleUnassignTask = otelhttp.NewHandler(handleUnassignTask, "handleUnassignTask")
+
+	var handleListMyTasks http.Handler
+	handleListMyTasks = s.handleListMyTasks()
+	handleListMyTasks = httpLoggingMiddleware(logger, "handleListMyTasks")(handleListMyTasks)
+	handleListMyTasks = otelhttp.NewHandler(handleListMyTasks, "handleListMyTasks")
+
+	var handleCreateUser http.Handler
+	handleCreateUser = s.handleCreateUser()
+	handleCreateUser = httpLoggingMiddleware(logger, "handleCreateUser")(handleCreateUser)
+	handleCreateUser = otelhttp.NewHandler(handleCreateUser, "handleCreateUser")
+
+	var handleListUsers http.Handler
+	handleListUsers = s.handleListUsers()
+	handleListUsers = httpLoggingMiddleware(logger, "handleListUsers")(handleListUsers)
+	handleListUsers = otelhttp.NewHandler(handleListUsers, "handleListUsers")
+
+	var handleListTrash http.Handler
+	handleListTrash = s.handleListTrash()
+	handleListTrash = httpLoggingMiddleware(logger, "handleListTrash")(handleListTrash)
+	handleListTrash = otelhttp.NewHandler(handleListTrash, "handleListTrash")
+
+	var handleRestoreTask http.Handler
+	handleRestoreTask = s.handleRestoreTask()
+	handleRestoreTask = httpLoggingMiddleware(logger, "handleRestoreTask")(handleRestoreTask)
+	handleRestoreTask = otelhttp.NewHandler(handleRestoreTask, "handleRestoreTask")
+
+	var handlePurgeTrash http.Handler
+	handlePurgeTrash = s.handlePurgeTrash()
+	handlePurgeTrash = httpLoggingMiddleware(logger, "handlePurgeTrash")(handlePurgeTrash)
+	handlePurgeTrash = otelhttp.NewHandler(handlePurgeTrash, "handlePurgeTrash")
+
+	var handleCreateChecklist http.Handler
+	handleCreateChecklist = s.handleCreateChecklist()
+	handleCreateChecklist = httpLoggingMiddleware(logger, "handleCreateChecklist")(handleCreateChecklist)
+	handleCreateChecklist = otelhttp.NewHandler(handleCreateChecklist, "handleCreateChecklist")
+
+	var handleListChecklists http.Handler
+	handleListChecklists = s.handleListChecklists()
+	handleListChecklists = httpLoggingMiddleware(logger, "handleListChecklists")(handleListChecklists)
+	handleListChecklists = otelhttp.NewHandler(handleListChecklists, "handleListChecklists")
+
+	var handleGetChecklist http.Handler
+	handleGetChecklist = s.handleGetChecklist()
+	handleGetChecklist = httpLoggingMiddleware(logger, "handleGetChecklist")(handleGetChecklist)
+	handleGetChecklist = otelhttp.NewHandler(handleGetChecklist, "handleGetChecklist")
+
+	var handleUpdateChecklist http.Handler
+	handleUpdateChecklist = s.handleUpdateChecklist()
+	handleUpdateChecklist = httpLoggingMiddleware(logger, "handleUpdateChecklist")(handleUpdateChecklist)
+	handleUpdateChecklist = otelhttp.NewHandler(handleUpdateChecklist, "handleUpdateChecklist")
+
+	var handleRemoveChecklist http.Handler
+	handleRemoveChecklist = s.handleRemoveChecklist()
+	handleRemoveChecklist = httpLoggingMiddleware(logger, "handleRemoveChecklist")(handleRemoveChecklist)
+	handleRemoveChecklist = otelhttp.NewHandler(handleRemoveChecklist, "handleRemoveChecklist")
+
+	var handleSummarizeChecklistTime http.Handler
+	handleSummarizeChecklistTime = s.handleSummarizeChecklistTime()
+	handleSummarizeChecklistTime = httpLoggingMiddleware(logger, "handleSummarizeChecklistTime")(handleSummarizeChecklistTime)
+	handleSummarizeChecklistTime = otelhttp.NewHandler(handleSummarizeChecklistTime, "handleSummarizeChecklistTime")
+
+	var handleSaveChecklistTask http.Handler
+	handleSaveChecklistTask = s.handleSaveChecklistTask()
+	handleSaveChecklistTask = httpLoggingMiddleware(logger, "handleSaveChecklistTask")(handleSaveChecklistTask)
+	handleSaveChecklistTask = otelhttp.NewHandler(handleSaveChecklistTask, "handleSaveChecklistTask")
+
+	var handleListChecklistTasks http.Handler
+	handleListChecklistTasks = s.handleListChecklistTasks()
+	handleListChecklistTasks = httpLoggingMiddleware(logger, "handleListChecklistTasks")(handleListChecklistTasks)
+	handleListChecklistTasks = otelhttp.NewHandler(handleListChecklistTasks, "handleListChecklistTasks")
+
+	var handleRemoveChecklistTask http.Handler
+	handleRemoveChecklistTask = s.handleRemoveChecklistTask()
+	handleRemoveChecklistTask = httpLoggingMiddleware(logger, "handleRemoveChecklistTask")(handleRemoveChecklistTask)
+	handleRemoveChecklistTask = otelhttp.NewHandler(handleRemoveChecklistTask, "handleRemoveChecklistTask")
+
+	var handleToggleChecklistTask http.Handler
+	handleToggleChecklistTask = s.handleToggleChecklistTask()
+	handleToggleChecklistTask = httpLoggingMiddleware(logger, "handleToggleChecklistTask")(handleToggleChecklistTask)
+	handleToggleChecklistTask = otelhttp.NewHandler(handleToggleChecklistTask, "handleToggleChecklistTask")
+
+	var handleUpdateChecklistTask http.Handler
+	handleUpdateChecklistTask = s.handleUpdateChecklistTask()
+	handleUpdateChecklistTask = httpLoggingMiddleware(logger, "handleUpdateChecklistTask")(handleUpdateChecklistTask)
+	handleUpdateChecklistTask = otelhttp.NewHandler(handleUpdateChecklistTask, "handleUpdateChecklistTask")
+
+	var handleCreateTemplate http.Handler
+	handleCreateTemplate = s.handleCreateTemplate()
+	handleCreateTemplate = httpLoggingMiddleware(logger, "handleCreateTemplate")(handleCreateTemplate)
+	handleCreateTemplate = otelhttp.NewHandler(handleCreateTemplate, "handleCreateTemplate")
+
+	var handleListTemplates http.Handler
+	handleListTemplates = s.handleListTemplates()
+	handleListTemplates = httpLoggingMiddleware(logger, "handleListTemplates")(handleListTemplates)
+	handleListTemplates = otelhttp.NewHandler(handleListTemplates, "handleListTemplates")
+
+	var handleGetTemplate http.Handler
+	handleGetTemplate = s.handleGetTemplate()
+	handleGetTemplate = httpLoggingMiddleware(logger, "handleGetTemplate")(handleGetTemplate)
+	handleGetTemplate = otelhttp.NewHandler(handleGetTemplate, "handleGetTemplate")
+
+	var handleUpdateTemplate http.Handler
+	handleUpdateTemplate = s.handleUpdateTemplate()
+	handleUpdateTemplate = httpLoggingMiddleware(logger, "handleUpdateTemplate")(handleUpdateTemplate)
+	handleUpdateTemplate = otelhttp.NewHandler(handleUpdateTemplate, "handleUpdateTemplate")
+
+	var handleRemoveTemplate http.Handler
+	handleRemoveTemplate = s.handleRemoveTemplate()
+	handleRemoveTemplate = httpLoggingMiddleware(logger, "handleRemoveTemplate")(handleRemoveTemplate)
+	handleRemoveTemplate = otelhttp.NewHandler(handleRemoveTemplate, "handleRemoveTemplate")
+
+	var handleInstantiateTemplate http.Handler
+	handleInstantiateTemplate = s.handleInstantiateTemplate()
+	handleInstantiateTemplate = httpLoggingMiddleware(logger, "handleInstantiateTemplate")(handleInstantiateTemplate)
+	handleInstantiateTemplate = otelhttp.NewHandler(handleInstantiateTemplate, "handleInstantiateTemplate")
+
+	var handleBatch http.Handler
+	handleBatch = s.handleBatch()
+	handleBatch = httpLoggingMiddleware(logger, "handleBatch")(handleBatch)
+	handleBatch = otelhttp.NewHandler(handleBatch, "handleBatch")
+
+	router := way.NewRouter()
+	handle := func(method, pattern string, handler http.Handler) {
+		router.Handle(method, pattern, bodyLimitMiddleware(s.maxBodySizeOf(method, pattern))(handler))
+	}
+
+	handle("POST", "/checklist/v1/tasks", handleSaveTask)
+	handle("POST", "/checklist/v1/tasks:batch", handleBatch)
+	handle("GET", "/checklist/v1/tasks", handleListTasks)
+	handle("GET", "/checklist/v1/task/:id", handleGetTask)
+	handle("DELETE", "/checklist/v1/task/:id", handleRemoveTask)
+	handle("PATCH", "/checklist/v1/task/:id", handleToggleTask)
+	handle("PUT", "/checklist/v1/task/:id", handleUpdateTask)
+	handle("GET", "/checklist/v1/task/:id/subtasks", handleListSubtasks)
+	handle("POST", "/checklist/v1/task/:id/transition", handleTransitionTask)
+	handle("POST", "/checklist/v1/task/:id/move", handleMoveTask)
+	handle("GET", "/checklist/v1/task/:id/history", handleListTaskHistory)
+	handle("GET", "/checklist/v1/task/:id/blockers", handleListBlockers)
+	handle("PUT", "/checklist/v1/task/:id/blockers/:blockerid", handleAddBlocker)
+	handle("DELETE", "/checklist/v1/task/:id/blockers/:blockerid", handleRemoveBlocker)
+	handle("POST", "/checklist/v1/task/:id/comments", handleAddComment)
+	handle("GET", "/checklist/v1/task/:id/comments", handleListComments)
+	handle("PUT", "/checklist/v1/task/:id/comments/:commentid", handleEditComment)
+	handle("DELETE", "/checklist/v1/task/:id/comments/:commentid", handleRemoveComment)
+	handle("POST", "/checklist/v1/task/:id/timer", handleStartTimer)
+	handle("DELETE", "/checklist/v1/task/:id/timer", handleStopTimer)
+	handle("POST", "/checklist/v1/task/:id/time-entries", handleAddTimeEntry)
+	handle("GET", "/checklist/v1/task/:id/time-entries", handleListTimeEntries)
+	handle("DELETE", "/checklist/v1/task/:id/time-entries/:entryid", handleRemoveTimeEntry)
+	handle("GET", "/checklist/v1/task/:id/time", handleSummarizeTaskTime)
+	handle("POST", "/checklist/v1/task/:id/reminders", handleAddReminder)
+	handle("GET", "/checklist/v1/task/:id/reminders", handleListReminders)
+	handle("DELETE", "/checklist/v1/task/:id/reminders/:reminderid", handleRemoveReminder)
+	handle("POST", "/checklist/v1/task/:id/attachments", handleAddAttachment)
+	handle("GET", "/checklist/v1/task/:id/attachments", handleListAttachments)
+	handle("GET", "/checklist/v1/task/:id/attachments/:attachmentid", handleDownloadAttachment)
+	handle("DELETE", "/checklist/v1/task/:id/attachments/:attachmentid", handleRemoveAttachment)
+	handle("PUT", "/checklist/v1/task/:id/assignee", handleAssignTask)
+	handle("DELETE", "/checklist/v1/task/:id/assignee", handleUnassignTask)
+	handle("GET", "/checklist/v1/tags", handleListTags)
+	handle("GET", "/checklist/v1/trash", handleListTrash)
+	handle("DELETE", "/checklist/v1/trash", handlePurgeTrash)
+	handle("POST", "/checklist/v1/trash/:id/restore", handleRestoreTask)
+
+	handle("POST", "/checklist/v1/users", handleCreateUser)
+	handle("GET", "/checklist/v1/users", handleListUsers)
+	handle("GET", "/checklist/v1/my/tasks", handleListMyTasks)
+
+	handle("POST", "/checklist/v1/lists", handleCreateChecklist)
+	handle("GET", "/checklist/v1/lists", handleListChecklists)
+	handle("GET", "/checklist/v1/list/:listid", handleGetChecklist)
+	handle("PUT", "/checklist/v1/list/:listid", handleUpdateChecklist)
+	handle("DELETE", "/checklist/v1/list/:listid", handleRemoveChecklist)
+	handle("GET", "/checklist/v1/list/:listid/time", handleSummarizeChecklistTime)
+	handle("POST", "/checklist/v1/list/:listid/tasks", handleSaveChecklistTask)
+	handle("GET", "/checklist/v1/list/:listid/tasks", handleListChecklistTasks)
+	handle("DELETE", "/checklist/v1/list/:listid/task/:id", handleRemoveChecklistTask)
+	handle("PATCH", "/checklist/v1/list/:listid/task/:id", handleToggleChecklistTask)
+	handle("PUT", "/checklist/v1/list/:listid/task/:id", handleUpdateChecklistTask)
+
+	handle("POST", "/checklist/v1/templates", handleCreateTemplate)
+	handle("GET", "/checklist/v1/templates", handleListTemplates)
+	handle("GET", "/checklist/v1/template/:templateid", handleGetTemplate)
+	handle("PUT", "/checklist/v1/template/:templateid", handleUpdateTemplate)
+	handle("DELETE", "/checklist/v1/template/:templateid", handleRemoveTemplate)
+	handle("POST", "/checklist/v1/template/:templateid/instantiate", handleInstantiateTemplate)
+
+	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { writeError(w, ErrResourceNotFound) })
+
+	return actorMiddleware(router)
+}
+
+const (
+	contentTypeKey   = "Content-Type"
+	contentTypeValue = "application/json; charset=utf-8"
+)
+
+var (
+	ErrNonNumericTaskID       = errors.New("task id in path must be numeric")
+	ErrNonNumericChecklistID  = errors.New("checklist id in path must be numeric")
+	ErrNonNumericCommentID    = errors.New("comment id in path must be numeric")
+	ErrNonNumericTimeEntryID  = errors.New("time entry id in path must be numeric")
+	ErrNonNumericTemplateID   = errors.New("template id in path must be numeric")
+	ErrNonNumericReminderID   = errors.New("reminder id in path must be numeric")
+	ErrNonNumericAttachmentID = errors.New("attachment id in path must be numeric")
+	ErrMissingFile            = errors.New("request must have a file in its file field")
+	ErrInvalidMoveTarget      = errors.New("exactly one of before and after must be given")
+	ErrResourceNotFound       = errors.New("resource not found")
+	ErrMethodNotAllowed       = errors.New("method not allowed")
+	ErrPreconditionFailed     = errors.New("precondition failed")
+	ErrRequestBodyTooLarge    = errors.New("request body is larger than allowed")
+	ErrInvalidBatchMode       = errors.New("batch mode must be one of all_or_nothing or per_item")
+)
+
+type ErrInvalidRequestBody struct{ err error }
+
+func (e ErrInvalidRequestBody) Error() string { return fmt.Sprintf("invalid request body: %v", e.err) }
+
+type ErrInvalidQueryParam struct {
+	name string
+	err  error
+}
+
+func (e ErrInvalidQueryParam) Error() string {
+	return fmt.Sprintf("invalid query param %q: %v", e.name, e.err)
+}
+
+// taskRequest is the JSON body accepted by the handlers that create or replace a task.
+type taskRequest struct {
+	ChecklistID int64      `json:"checklist_id"`
+	ParentID    int64      `json:"parent_id"`
+	AssigneeID  int64      `json:"assignee_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	Status      string     `json:"status"` // takes precedence over done when given
+	DueDate     *time.Time `json:"due_date"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags"`
+	Recurrence  string     `json:"recurrence"`
+	Estimate    string     `json:"estimate"` // a duration such as 1h30m
+}
+
+// decodeTask reads a taskRequest from the request body and maps it to a todo.Task with the given id.
+func decodeTask(r *http.Request, id int64) (todo.Task, error) {
+	var req taskRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return todo.Task{}, err
+	}
+	return makeTask(req, id)
+}
+
+// makeTask maps the taskRequest to a todo.Task with the given id.
+func makeTask(req taskRequest, id int64) (todo.Task, error) {
+	priority, err := todo.ParsePriority(req.Priority)
+	if err != nil {
+		return todo.Task{}, err
+	}
+
+	recurrence, err := todo.ParseRecurrence(req.Recurrence)
+	if err != nil {
+		return todo.Task{}, err
+	}
+
+	status, err := todo.ParseStatus(req.Status)
+	if err != nil {
+		return todo.Task{}, err
+	}
+
+	var estimate time.Duration
+	if req.Estimate != "" {
+		if estimate, err = time.ParseDuration(req.Estimate); err != nil {
+			return todo.Task{}, todo.ErrInvalidEstimate
+		}
+	}
+
+	return todo.Task{
+		ID:          id,
+		ChecklistID: req.ChecklistID,
+		ParentID:    req.ParentID,
+		AssigneeID:  req.AssigneeID,
+		Name:        req.Name,
+		Description: req.Description,
+		Done:        req.Done,
+		Status:      status,
+		DueDate:     req.DueDate,
+		Priority:    priority,
+		Tags:        req.Tags,
+		Recurrence:  recurrence,
+		Estimate:    estimate,
+	}, nil
+}
+
+// taskResponse is the JSON representation of a todo.Task shared by all handlers.
+type taskResponse struct {
+	ID              int64      `json:"id"`
+	ChecklistID     int64      `json:"checklist_id,omitempty"`
+	ParentID        int64      `json:"parent_id,omitempty"`
+	AssigneeID      int64      `json:"assignee_id,omitempty"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description,omitempty"`
+	DescriptionHTML string     `json:"description_html,omitempty"`
+	Done            bool       `json:"done"`
+	Status          string     `json:"status"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	Priority        string     `json:"priority"`
+	Tags            []string   `json:"tags,omitempty"`
+	Recurrence      string     `json:"recurrence,omitempty"`
+	Estimate        string     `json:"estimate,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+// makeTaskResponse maps the task to its JSON representation, which includes
+// the description rendered as sanitized HTML if html is set.
+func makeTaskResponse(task todo.Task, html bool) taskResponse {
+	var recurrence string
+	if task.Recurrence != nil {
+		recurrence = task.Recurrence.String()
+	}
+
+	var descriptionHTML string
+	if html && task.Description != "" {
+		descriptionHTML = renderMarkdown(task.Description)
+	}
+
+	var estimate string
+	if task.Estimate > 0 {
+		estimate = task.Estimate.String()
+	}
+
+	return taskResponse{
+		ID:              task.ID,
+		ChecklistID:     task.ChecklistID,
+		ParentID:        task.ParentID,
+		AssigneeID:      task.AssigneeID,
+		Name:            task.Name,
+		Description:     task.Description,
+		DescriptionHTML: descriptionHTML,
+		Done:            task.Done,
+		Status:          string(task.Status),
+		DueDate:         task.DueDate,
+		Priority:        task.Priority.String(),
+		Tags:            task.Tags,
+		Recurrence:      recurrence,
+		Estimate:        estimate,
+		CreatedAt:       task.CreatedAt,
+		UpdatedAt:       task.UpdatedAt,
+		CompletedAt:     task.CompletedAt,
+		ArchivedAt:      task.ArchivedAt,
+		DeletedAt:       task.DeletedAt,
+	}
+}
+
+// taskTreeResponse nests subtasks under their parent task.
+type taskTreeResponse struct {
+	taskResponse
+	Subtasks []taskTreeResponse `json:"subtasks,omitempty"`
+}
+
+// makeTaskTree nests every task of the list under its parent. Tasks whose
+// parent isn't part of the list are returned at the top level.
+func makeTaskTree(list []todo.Task, html bool) []taskTreeResponse {
+	inList := make(map[int64]bool, len(list))
+	for _, task := range list {
+		inList[task.ID] = true
+	}
+
+	children := make(map[int64][]todo.Task)
+	var roots []todo.Task
+	for _, task := range list {
+		if task.ParentID != 0 && inList[task.ParentID] {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+
+	var build func([]todo.Task) []taskTreeResponse
+	build = func(tasks []todo.Task) []taskTreeResponse {
+		nodes := make([]taskTreeResponse, 0, len(tasks))
+		for _, task := range tasks {
+			nodes = append(nodes, taskTreeResponse{makeTaskResponse(task, html), build(children[task.ID])})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// encodeTaskList writes the list as a flat JSON array, or nested by parent if "tree=true" is set.
+func encodeTaskList(w http.ResponseWriter, r *http.Request, list []todo.Task) {
+	tree, err := parseBoolParam(r.URL.Query(), "tree")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set(contentTypeKey, contentTypeValue)
+	if tree {
+		json.NewEncoder(w).Encode(makeTaskTree(list, renderHTML(r)))
+		return
+	}
+
+	resp := make([]taskResponse, 0, len(list))
+	for _, v := range list {
+		resp = append(resp, makeTaskResponse(v, renderHTML(r)))
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// renderHTML reports whether "render=html" asks for task descriptions to be
+// rendered as sanitized HTML, which is returned alongside the raw Markdown.
+func renderHTML(r *http.Request) bool {
+	return r.URL.Query().Get("render") == "html"
+}
+
+// commentRequest is the JSON body accepted by the handlers that add or edit a comment.
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+type commentResponse struct {
+	ID        int64     `json:"id"`
+	TaskID    int64     `json:"task_id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func makeCommentResponse(comment todo.Comment) commentResponse {
+	return commentResponse{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
+
+// timeEntryRequest is the JSON body accepted by the handler that adds a time entry manually.
+type timeEntryRequest struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type timeEntryResponse struct {
+	ID       int64      `json:"id"`
+	TaskID   int64      `json:"task_id"`
+	User     string     `json:"user"`
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end,omitempty"`
+	Duration string     `json:"duration,omitempty"` // left out while the timer is running
+}
+
+func makeTimeEntryResponse(entry todo.TimeEntry) timeEntryResponse {
+	resp := timeEntryResponse{
+		ID:     entry.ID,
+		TaskID: entry.TaskID,
+		User:   entry.User,
+		Start:  entry.Start,
+		End:    entry.End,
+	}
+	if !entry.IsRunning() {
+		resp.Duration = entry.Duration(*entry.End).String()
+	}
+	return resp
+}
+
+// timeSummaryResponse holds durations such as 1h30m0s, with the time spent rounded down to the second.
+type timeSummaryResponse struct {
+	Estimate string `json:"estimate"`
+	Spent    string `json:"spent"`
+}
+
+func makeTimeSummaryResponse(summary todo.TimeSummary) timeSummaryResponse {
+	return timeSummaryResponse{
+		Estimate: summary.Estimate.String(),
+		Spent:    summary.Spent.Truncate(time.Second).String(),
+	}
+}
+
+// reminderRequest holds either the time to send the reminder at or
+// how long before the task is due to send it, as a duration such as 1h30m.
+type reminderRequest struct {
+	At     *time.Time `json:"at"`
+	Before string     `json:"before"`
+}
+
+func decodeReminder(req reminderRequest) (todo.Reminder, error) {
+	reminder := todo.Reminder{At: req.At}
+	if req.Before != "" {
+		before, err := time.ParseDuration(req.Before)
+		if err != nil {
+			return todo.Reminder{}, todo.ErrInvalidReminder
+		}
+		reminder.Before = &before
+	}
+	return reminder, nil
+}
+
+type reminderResponse struct {
+	ID        int64      `json:"id"`
+	TaskID    int64      `json:"task_id"`
+	At        *time.Time `json:"at,omitempty"`
+	Before    string     `json:"before,omitempty"`
+	SentAt    *time.Time `json:"sent_at,omitempty"` // left out while the reminder is pending
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+type attachmentResponse struct {
+	ID          int64     `json:"id"`
+	TaskID      int64     `json:"task_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func makeAttachmentResponse(attachment todo.Attachment) attachmentResponse {
+	return attachmentResponse{
+		ID:          attachment.ID,
+		TaskID:      attachment.TaskID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		UploadedBy:  attachment.UploadedBy,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+func makeReminderResponse(reminder todo.Reminder) reminderResponse {
+	resp := reminderResponse{
+		ID:        reminder.ID,
+		TaskID:    reminder.TaskID,
+		At:        reminder.At,
+		SentAt:    reminder.SentAt,
+		Attempts:  reminder.Attempts,
+		LastError: reminder.LastError,
+	}
+	if reminder.Before != nil {
+		resp.Before = reminder.Before.String()
+	}
+	return resp
+}
+
+type userResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func makeUserResponse(user todo.User) userResponse {
+	return userResponse{ID: user.ID, Name: user.Name}
+}
+
+type checklistResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func makeChecklistResponse(checklist todo.Checklist) checklistResponse {
+	return checklistResponse{ID: checklist.ID, Name: checklist.Name}
+}
+
+// templateRequest is the JSON body accepted by the handlers that create or update a template.
+type templateRequest struct {
+	Name  string `json:"name"`
+	Tasks []struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Priority    string   `json:"priority"`
+		Tags        []string `json:"tags"`
+		Estimate    string   `json:"estimate"`   // a duration such as 1h30m
+		DueOffset   string   `json:"due_offset"` // a duration such as 48h, empty if the task has no due date
+	} `json:"tasks"`
+}
+
+// decodeTemplate reads a templateRequest from the request body and maps it to a todo.Template with the given id.
+func decodeTemplate(r *http.Request, id int64) (todo.Template, error) {
+	var req templateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		return todo.Template{}, err
+	}
+
+	template := todo.Template{ID: id, Name: req.Name}
+	for _, t := range req.Tasks {
+		priority, err := todo.ParsePriority(t.Priority)
+		if err != nil {
+			return todo.Template{}, err
+		}
+
+		var estimate time.Duration
+		if t.Estimate != "" {
+			if estimate, err = time.ParseDuration(t.Estimate); err != nil {
+				return todo.Template{}, todo.ErrInvalidEstimate
+			}
+		}
+
+		var dueOffset *time.Duration
+		if t.DueOffset != "" {
+			offset, err := time.ParseDuration(t.DueOffset)
+			if err != nil {
+				return todo.Template{}, todo.ErrInvalidDueOffset
+			}
+			dueOffset = &offset
+		}
+
+		template.Tasks = append(template.Tasks, todo.TemplateTask{
+			Name:        t.Name,
+			Description: t.Description,
+			Priority:    priority,
+			Tags:        t.Tags,
+			Estimate:    estimate,
+			DueOffset:   dueOffset,
+		})
+	}
+	return template, nil
+}
+
+type templateTaskResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Priority    string   `json:"priority"`
+	Tags        []string `json:"tags,omitempty"`
+	Estimate    string   `json:"estimate,omitempty"`
+	DueOffset   string   `json:"due_offset,omitempty"`
+}
+
+type templateResponse struct {
+	ID    int64                  `json:"id"`
+	Name  string                 `json:"name"`
+	Tasks []templateTaskResponse `json:"tasks"`
+}
+
+func makeTemplateResponse(template todo.Template) templateResponse {
+	tasks := make([]templateTaskResponse, 0, len(template.Tasks))
+	for _, task := range template.Tasks {
+		resp := templateTaskResponse{
+			Name:        task.Name,
+			Description: task.Description,
+			Priority:    task.Priority.String(),
+			Tags:        task.Tags,
+		}
+		if task.Estimate > 0 {
+			resp.Estimate = task.Estimate.String()
+		}
+		if task.DueOffset != nil {
+			resp.DueOffset = task.DueOffset.String()
+		}
+		tasks = append(tasks, resp)
+	}
+	return templateResponse{ID: template.ID, Name: template.Name, Tasks: tasks}
+}
+
+// pathID parses the numeric path param with the given name, returning errNonNumeric if it isn't a number.
+func pathID(r *http.Request, name string, errNonNumeric error) (int64, error) {
+	id, err := strconv.ParseInt(way.Param(r.Context(), name), 10, 64)
+	if err != nil {
+		return 0, errNonNumeric
+	}
+	return id, nil
+}
+
+// etag returns the entity tag of a task, which changes whenever the task is updated.
+func etag(task todo.Task) string {
+	return fmt.Sprintf("%q", strconv.FormatInt(task.Version, 10))
+}
+
+// matchesETag reports whether an If-Match or If-None-Match header value lists the
+// entity tag of the current task, or is "*" and the task exists. Weak tags are
+// compared as if they were strong ones.
+func matchesETag(header string, current *todo.Task) bool {
+	if current == nil {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag(*current) {
+			return true
+		}
+	}
+	return false
+}
+
+type server struct {
+	service           Service
+	maxBodySize       int64
+	routeMaxBodySizes map[string]int64 // keyed by the method and pattern of the route
+}
+
+// checkPreconditions evaluates the If-Match and If-None-Match headers against the task
+// with the given id, returning ErrPreconditionFailed if they don't hold. It returns the
+// task it evaluated them against, or nil if there were no such headers or no such task.
+func (s *server) checkPreconditions(r *http.Request, id int64) (*todo.Task, error) {
+	ifMatch, ifNoneMatch := r.Header.Get("If-Match"), r.Header.Get("If-None-Match")
+	if ifMatch == "" && ifNoneMatch == "" {
+		return nil, nil
+	}
+
+	current, err := s.service.Get(r.Context(), id)
+	if err == todo.ErrTaskNotFound {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if ifMatch != "" && !matchesETag(ifMatch, current) {
+		return nil, ErrPreconditionFailed
+	}
+	if ifNoneMatch != "" && matchesETag(ifNoneMatch, current) {
+		return nil, ErrPreconditionFailed
+	}
+	return current, nil
+}
+
+// checkedVersion checks the preconditions like checkPreconditions and returns the version of
+// the task they were checked against, or zero if they weren't. Passing it on makes the change
+// fail with ErrVersionConflict if the task changes after the preconditions were checked.
+func (s *server) checkedVersion(r *http.Request, id int64) (int64, error) {
+	current, err := s.checkPreconditions(r, id)
+	if err != nil || current == nil {
+		return 0, err
+	}
+	return current.Version, nil
+}
+
+func (s *server) handleSaveTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		task, err := decodeTask(r, 0)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		saved, err := s.service.Save(r.Context(), task)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*saved))
+		json.NewEncoder(w).Encode(makeTaskResponse(*saved, renderHTML(r)))
+	}
+}
+
+func (s *server) handleListTasks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, next, err := s.listTasks(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if next != nil {
+			w.Header().Set("Link", nextPageLink(r, next.String()))
+		}
+		encodeTaskList(w, r, list)
+	}
+}
+
+// listTasks lists the tasks matching the query string as described by parseTaskQuery,
+// along with the cursor of the next page, if any.
+func (s *server) listTasks(r *http.Request) ([]todo.Task, *todo.Cursor, error) {
+	taskQuery, err := parseTaskQuery(r.URL.Query())
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.service.ListPage(r.Context(), taskQuery)
+}
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// parseTaskQuery reads the params of a paginated task listing: "done" filters by
+// completion, "archived" leaves archived tasks out unless it is "include" or "only",
+// "status" filters by workflow status, "assignee_id" by assignee, "name" by a
+// case-insensitive substring of the name and the RFC 3339 timestamps "created_after",
+// "created_before", "updated_after", "updated_before", "completed_after" and
+// "completed_before" by when tasks were created, last updated or completed, just as "due_after"
+// and "due_before" do by due date. "due" may also be "overdue" or "today" (evaluated in the "tz"
+// location, UTC by default). One or more "tag" params keep the tasks carrying any of the tags,
+// or all of them with "tag_match=all". "blocked=true" keeps the pending tasks waiting on other
+// pending tasks and "blocked=false" the pending tasks that aren't. "sort" picks
+// one of position (the default), priority, due_date, name, id, created_at, updated_at or
+// completed_at and "order=desc" reverses it. All matching tasks are listed at once unless
+// "limit" or "cursor" is given, in which case pages hold "limit" tasks, 100 by default,
+// and "cursor" resumes after the end of a previous page.
+func parseTaskQuery(query url.Values) (todo.TaskQuery, error) {
+	taskQuery := todo.TaskQuery{Name: query.Get("name")}
+
+	if value := query.Get("done"); value != "" {
+		done, err := strconv.ParseBool(value)
+		if err != nil {
+			return todo.TaskQuery{}, ErrInvalidQueryParam{"done", err}
+		}
+		taskQuery.Done = &done
+	}
+
+	switch archived := query.Get("archived"); archived {
+	case "", "exclude":
+		taskQuery.Archived = new(bool)
+	case "include":
+	case "only":
+		only := true
+		taskQuery.Archived = &only
+	default:
+		return todo.TaskQuery{}, ErrInvalidQueryParam{"archived", fmt.Errorf("unknown value %q", archived)}
+	}
+
+	status, err := todo.ParseStatus(query.Get("status"))
+	if err != nil {
+		return todo.TaskQuery{}, ErrInvalidQueryParam{"status", err}
+	}
+	taskQuery.Status = status
+
+	if value := query.Get("assignee_id"); value != "" {
+		assigneeID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return todo.TaskQuery{}, ErrInvalidQueryParam{"assignee_id", err}
+		}
+		taskQuery.AssigneeID = assigneeID
+	}
+
+	switch due := query.Get("due"); due {
+	case "":
+	case "overdue":
+		taskQuery.Overdue = true
+	case "today":
+		loc, err := time.LoadLocation(query.Get("tz"))
+		if err != nil {
+			return todo.TaskQuery{}, ErrInvalidQueryParam{"tz", err}
+		}
+		taskQuery.DueToday = loc
+	default:
+		return todo.TaskQuery{}, ErrInvalidQueryParam{"due", fmt.Errorf("unknown value %q", due)}
+	}
+
+	if value := query.Get("blocked"); value != "" {
+		blocked, err := strconv.ParseBool(value)
+		if err != nil {
+			return todo.TaskQuery{}, ErrInvalidQueryParam{"blocked", err}
+		}
+		taskQuery.Blocked = &blocked
+	}
+
+	taskQuery.Tags = query["tag"]
+	switch match := query.Get("tag_match"); match {
+	case "", "any":
+	case "all":
+		taskQuery.AllTags = true
+	default:
+		return todo.TaskQuery{}, ErrInvalidQueryParam{"tag_match", fmt.Errorf("unknown value %q", match)}
+	}
+
+	ranges := []struct {
+		name  string
+		field *todo.TimeRange
+	}{
+		{"created", &taskQuery.Created},
+		{"updated", &taskQuery.Updated},
+		{"completed", &taskQuery.Completed},
+		{"due", &taskQuery.Due},
+	}
+	for _, r := range ranges {
+		after, err := parseTimeParam(query, r.name+"_after")
+		if err != nil {
+			return todo.TaskQuery{}, err
+		}
+		before, err := parseTimeParam(query, r.name+"_before")
+		if err != nil {
+			return todo.TaskQuery{}, err
+		}
+		*r.field = todo.TimeRange{After: after, Before: before}
+	}
+
+	sortBy, err := todo.ParseSortField(query.Get("sort"))
+	if err != nil {
+		return todo.TaskQuery{}, ErrInvalidQueryParam{"sort", err}
+	}
+	taskQuery.SortBy = sortBy
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		taskQuery.Descending = true
+	default:
+		return todo.TaskQuery{}, ErrInvalidQueryParam{"order", fmt.Errorf("unknown value %q", order)}
+	}
+
+	// only listings that ask for pages are paginated, so that plain ones return every task
+	if query.Get("limit") != "" || query.Get("cursor") != "" {
+		limit, err := parsePageSize(query)
+		if err != nil {
+			return todo.TaskQuery{}, err
+		}
+		taskQuery.Limit = limit
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := todo.ParseCursor(value)
+		if err != nil {
+			return todo.TaskQuery{}, ErrInvalidQueryParam{"cursor", err}
+		}
+		taskQuery.After = cursor
+	}
+	return taskQuery, nil
+}
+
+// nextPageLink formats a Link header pointing at the page that starts after the given cursor.
+func nextPageLink(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	link := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=\"next\"", link.String())
+}
+
+// parsePageSize reads the "limit" param of a paginated listing, defaulting to defaultPageSize.
+func parsePageSize(query url.Values) (int, error) {
+	value := query.Get("limit")
+	if value == "" {
+		return defaultPageSize, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		return 0, ErrInvalidQueryParam{"limit", fmt.Errorf("must be a number from 1 to %d", maxPageSize)}
+	}
+	return limit, nil
+}
+
+func parseBoolParam(query url.Values, name string) (bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, ErrInvalidQueryParam{name, err}
+	}
+	return b, nil
+}
+
+func parseTimeParam(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, ErrInvalidQueryParam{name, err}
+	}
+	return t, nil
+}
+
+func (s *server) handleListSubtasks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		list, err := s.service.ListSubtasks(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		encodeTaskList(w, r, list)
+	}
+}
+
+func (s *server) handleListTags() http.HandlerFunc {
+	type tag struct {
+		Name      string `json:"name"`
+		TaskCount int    `json:"task_count"`
+	}
+	type response []tag
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := s.service.ListTags(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(tags))
+		for _, v := range tags {
+			resp = append(resp, tag{Name: v.Name, TaskCount: v.TaskCount})
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleGetTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		task, err := s.service.Get(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("ETag", etag(*task))
+		if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && matchesETag(ifNoneMatch, task) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeTaskResponse(*task, renderHTML(r)))
+	}
+}
+
+// handleListTaskHistory pages through the changes made to a task, oldest first. Pages
+// hold "limit" entries, 100 by default, and "cursor" resumes after the end of a previous page.
+func (s *server) handleListTaskHistory() http.HandlerFunc {
+	type entry struct {
+		ID        int64         `json:"id"`
+		TaskID    int64         `json:"task_id"`
+		Actor     string        `json:"actor"`
+		Time      time.Time     `json:"time"`
+		Operation string        `json:"operation"`
+		Before    *taskResponse `json:"before,omitempty"`
+		After     *taskResponse `json:"after,omitempty"`
+	}
+	type response []entry
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		limit, err := parsePageSize(query)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		var afterID int64
+		if value := query.Get("cursor"); value != "" {
+			if afterID, err = strconv.ParseInt(value, 10, 64); err != nil {
+				writeError(w, ErrInvalidQueryParam{"cursor", err})
+				return
+			}
+		}
+
+		entries, hasMore, err := s.service.ListHistory(r.Context(), id, afterID, limit)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(entries))
+		for _, v := range entries {
+			e := entry{
+				ID:        v.ID,
+				TaskID:    v.TaskID,
+				Actor:     v.Actor,
+				Time:      v.Time,
+				Operation: string(v.Operation),
+			}
+			if v.Before != nil {
+				before := makeTaskResponse(*v.Before, renderHTML(r))
+				e.Before = &before
+			}
+			if v.After != nil {
+				after := makeTaskResponse(*v.After, renderHTML(r))
+				e.After = &after
+			}
+			resp = append(resp, e)
+		}
+		if hasMore {
+			w.Header().Set("Link", nextPageLink(r, strconv.FormatInt(entries[len(entries)-1].ID, 10)))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleTransitionTask() http.HandlerFunc {
+	type request struct {
+		Status string `json:"status"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req request
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		task, err := s.service.Transition(r.Context(), id, todo.Status(req.Status))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*task))
+		json.NewEncoder(w).Encode(makeTaskResponse(*task, renderHTML(r)))
+	}
+}
+
+func (s *server) handleMoveTask() http.HandlerFunc {
+	// request names the task to place this one before or after, but not both
+	type request struct {
+		Before int64 `json:"before"`
+		After  int64 `json:"after"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req request
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+		if (req.Before == 0) == (req.After == 0) {
+			writeError(w, ErrInvalidMoveTarget)
+			return
+		}
+
+		targetID, after := req.Before, false
+		if req.After != 0 {
+			targetID, after = req.After, true
+		}
+		moved, err := s.service.Move(r.Context(), id, targetID, after)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*moved))
+		json.NewEncoder(w).Encode(makeTaskResponse(*moved, renderHTML(r)))
+	}
+}
+
+func (s *server) handleListBlockers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		list, err := s.service.ListBlockers(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		encodeTaskList(w, r, list)
+	}
+}
+
+func (s *server) handleAddBlocker() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		blockerID, err := pathID(r, "blockerid", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.AddBlocker(r.Context(), id, blockerID); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) handleRemoveBlocker() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		blockerID, err := pathID(r, "blockerid", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.RemoveBlocker(r.Context(), id, blockerID); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) handleAddComment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req commentRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		comment, err := s.service.AddComment(r.Context(), taskID, todo.Comment{Body: req.Body})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(makeCommentResponse(*comment))
+	}
+}
+
+func (s *server) handleListComments() http.HandlerFunc {
+	type response []commentResponse
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		list, err := s.service.ListComments(r.Context(), taskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(list))
+		for _, v := range list {
+			resp = append(resp, makeCommentResponse(v))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleEditComment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "commentid", ErrNonNumericCommentID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req commentRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		comment, err := s.service.EditComment(r.Context(), taskID, todo.Comment{ID: id, Body: req.Body})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeCommentResponse(*comment))
+	}
+}
+
+func (s *server) handleRemoveComment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "commentid", ErrNonNumericCommentID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.RemoveComment(r.Context(), taskID, id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) handleListTrash() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := s.service.ListTrash(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		encodeTaskList(w, r, list)
+	}
+}
+
+func (s *server) handleRestoreTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		restored, err := s.service.Restore(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*restored))
+		json.NewEncoder(w).Encode(makeTaskResponse(*restored, renderHTML(r)))
+	}
+}
+
+func (s *server) handlePurgeTrash() http.HandlerFunc {
+	type response struct {
+		Purged int64 `json:"purged"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		purged, err := s.service.PurgeTrash(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(response{Purged: purged})
+	}
+}
+
+func (s *server) handleRemoveTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(way.Param(r.Context(), "id"), 10, 64)
+		if err != nil {
+			writeError(w, ErrNonNumericTaskID)
+			return
+		}
+
+		version, err := s.checkedVersion(r, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.Remove(r.Context(), id, version); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) handleToggleTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(way.Param(r.Context(), "id"), 10, 64)
+		if err != nil {
+			writeError(w, ErrNonNumericTaskID)
+			return
+		}
+
+		cascade, err := parseBoolParam(r.URL.Query(), "cascade")
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		version, err := s.checkedVersion(r, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		toggle := s.service.ToggleDone
+		if cascade {
+			toggle = s.service.ToggleDoneTree
+		}
+		if err := toggle(r.Context(), id, version); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) handleUpdateTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(way.Param(r.Context(), "id"), 10, 64)
+		if err != nil {
+			writeError(w, ErrNonNumericTaskID)
+			return
+		}
+
+		task, err := decodeTask(r, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		current, err := s.checkPreconditions(r, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if current != nil {
+			// makes the update fail if the task changes after the preconditions were checked
+			task.Version = current.Version
+		}
+
+		updated, isCreated, err := s.service.Update(r.Context(), task)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*updated))
+		if isCreated {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(makeTaskResponse(*updated, renderHTML(r)))
+	}
+}
+
+func (s *server) handleStartTimer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		entry, err := s.service.StartTimer(r.Context(), taskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(makeTimeEntryResponse(*entry))
+	}
+}
+
+func (s *server) handleStopTimer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		entry, err := s.service.StopTimer(r.Context(), taskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeTimeEntryResponse(*entry))
+	}
+}
+
+func (s *server) handleAddTimeEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req timeEntryRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		entry, err := s.service.AddTimeEntry(r.Context(), taskID, todo.TimeEntry{Start: req.Start, End: &req.End})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(makeTimeEntryResponse(*entry))
+	}
+}
+
+func (s *server) handleListTimeEntries() http.HandlerFunc {
+	type response []timeEntryResponse
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		list, err := s.service.ListTimeEntries(r.Context(), taskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(list))
+		for _, v := range list {
+			resp = append(resp, makeTimeEntryResponse(v))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleRemoveTimeEntry() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "entryid", ErrNonNumericTimeEntryID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.RemoveTimeEntry(r.Context(), taskID, id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) handleSummarizeTaskTime() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		summary, err := s.service.SummarizeTime(r.Context(), taskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeTimeSummaryResponse(summary))
+	}
+}
+
+func (s *server) handleAddReminder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req reminderRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+		reminder, err := decodeReminder(req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		added, err := s.service.AddReminder(r.Context(), taskID, reminder)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(makeReminderResponse(*added))
+	}
+}
+
+func (s *server) handleListReminders() http.HandlerFunc {
+	type response []reminderResponse
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		list, err := s.service.ListReminders(r.Context(), taskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(list))
+		for _, v := range list {
+			resp = append(resp, makeReminderResponse(v))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleRemoveReminder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "reminderid", ErrNonNumericReminderID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.RemoveReminder(r.Context(), taskID, id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleAddAttachment expects a multipart/form-data body with the file in its file field,
+// which is streamed to the service as it's received rather than buffered first.
+func (s *server) handleAddAttachment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		mr, err := r.MultipartReader()
+		if err != nil {
+			writeError(w, ErrInvalidRequestBody{err})
+			return
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				writeError(w, ErrMissingFile)
+				return
+			}
+			if err != nil {
+				writeError(w, bodyError(r, err))
+				return
+			}
+			if part.FormName() != "file" {
+				continue
+			}
+
+			attachment, err := s.service.AddAttachment(r.Context(), taskID, todo.Attachment{Name: part.FileName()}, part)
+			if requestBodyTooLarge(r) {
+				err = ErrRequestBodyTooLarge // the service only saw that the file couldn't be read
+			}
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			w.Header().Set(contentTypeKey, contentTypeValue)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(makeAttachmentResponse(*attachment))
+			return
+		}
+	}
+}
+
+func (s *server) handleListAttachments() http.HandlerFunc {
+	type response []attachmentResponse
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		list, err := s.service.ListAttachments(r.Context(), taskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(list))
+		for _, v := range list {
+			resp = append(resp, makeAttachmentResponse(v))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleDownloadAttachment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "attachmentid", ErrNonNumericAttachmentID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		attachment, content, err := s.service.OpenAttachment(r.Context(), taskID, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		defer content.Close()
+
+		w.Header().Set(contentTypeKey, attachment.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})
+		if disposition == "" {
+			disposition = "attachment" // the name couldn't be encoded, so it's left to the client
+		}
+		w.Header().Set("Content-Disposition", disposition)
+		// browsers mustn't second-guess the type, which was told from the content when it was uploaded
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		io.Copy(w, content)
+	}
+}
+
+func (s *server) handleRemoveAttachment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "attachmentid", ErrNonNumericAttachmentID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.RemoveAttachment(r.Context(), taskID, id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) handleAssignTask() http.HandlerFunc {
+	type request struct {
+		UserID int64 `json:"user_id"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req request
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		task, err := s.service.Assign(r.Context(), id, req.UserID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*task))
+		json.NewEncoder(w).Encode(makeTaskResponse(*task, renderHTML(r)))
+	}
+}
+
+func (s *server) handleUnassignTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		task, err := s.service.Unassign(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*task))
+		json.NewEncoder(w).Encode(makeTaskResponse(*task, renderHTML(r)))
+	}
+}
+
+// handleListMyTasks lists the tasks assigned to the user making the request
+// a page at a time, taking the same params as parseTaskQuery.
+func (s *server) handleListMyTasks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseTaskQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		list, next, err := s.service.ListMyTasks(r.Context(), query)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if next != nil {
+			w.Header().Set("Link", nextPageLink(r, next.String()))
+		}
+		encodeTaskList(w, r, list)
+	}
+}
+
+func (s *server) handleCreateUser() http.HandlerFunc {
+	type request struct {
+		Name string `json:"name"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		user, err := s.service.CreateUser(r.Context(), todo.User{Name: req.Name})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(makeUserResponse(*user))
+	}
+}
+
+func (s *server) handleListUsers() http.HandlerFunc {
+	type response []userResponse
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := s.service.ListUsers(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(list))
+		for _, v := range list {
+			resp = append(resp, makeUserResponse(v))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleCreateChecklist() http.HandlerFunc {
+	type request struct {
+		Name string `json:"name"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		checklist, err := s.service.CreateChecklist(r.Context(), todo.Checklist{Name: req.Name})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(makeChecklistResponse(*checklist))
+	}
+}
+
+func (s *server) handleListChecklists() http.HandlerFunc {
+	type response []checklistResponse
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := s.service.ListChecklists(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := make(response, 0, len(list))
+		for _, v := range list {
+			resp = append(resp, makeChecklistResponse(v))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *server) handleGetChecklist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		checklist, err := s.service.GetChecklist(r.Context(), id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeChecklistResponse(*checklist))
+	}
+}
+
+func (s *server) handleUpdateChecklist() http.HandlerFunc {
+	type request struct {
+		Name string `json:"name"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-	var handleSaveTask http.Handler
-	handleSaveTask = s.handleSaveTask()
-	handleSaveTask = httpLoggingMiddleware(logger, "handleSaveTask")(handleSaveTask)
-	handleSaveTask = otelhttp.NewHandler(handleSaveTask, "handleSaveTask")
-	// wire other middlewares here
+		var req request
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
 
-	var handleListTasks http.Handler
-	handleListTasks = s.handleListTasks()
-	handleListTasks = httpLoggingMiddleware(logger, "handleListTasks")(handleListTasks)
-	handleListTasks = otelhttp.NewHandler(handleListTasks, "handleListTasks")
+		checklist, err := s.service.UpdateChecklist(r.Context(), todo.Checklist{ID: id, Name: req.Name})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeChecklistResponse(*checklist))
+	}
+}
 
-	var handleRemoveTask http.Handler
-	handleRemoveTask = s.handleRemoveTask()
-	handleRemoveTask = httpLoggingMiddleware(logger, "handleRemoveTask")(handleRemoveTask)
-	handleRemoveTask = otelhttp.NewHandler(handleRemoveTask, "handleRemoveTask")
+func (s *server) handleRemoveChecklist() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-	var handleToggleTask http.Handler
-	handleToggleTask = s.handleToggleTask()
-	handleToggleTask = httpLoggingMiddleware(logger, "handleToggleTask")(handleToggleTask)
-	handleToggleTask = otelhttp.NewHandler(handleToggleTask, "handleToggleTask")
+		if err := s.service.RemoveChecklist(r.Context(), id); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
 
-	var handleUpdateTask http.Handler
-	handleUpdateTask = s.handleUpdateTask()
-	handleUpdateTask = httpLoggingMiddleware(logger, "handleUpdateTask")(handleUpdateTask)
-	handleUpdateTask = otelhttp.NewHandler(handleUpdateTask, "handleUpdateTask")
+func (s *server) handleSummarizeChecklistTime() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checklistID, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-	router := way.NewRouter()
+		summary, err := s.service.SummarizeChecklistTime(r.Context(), checklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeTimeSummaryResponse(summary))
+	}
+}
 
-	router.Handle("POST", "/checklist/v1/tasks", handleSaveTask)
-	router.Handle("GET", "/checklist/v1/tasks", handleListTasks)
-	router.Handle("DELETE", "/checklist/v1/task/:id", handleRemoveTask)
-	router.Handle("PATCH", "/checklist/v1/task/:id", handleToggleTask)
-	router.Handle("PUT", "/checklist/v1/task/:id", handleUpdateTask)
+func (s *server) handleSaveChecklistTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checklistID, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { writeError(w, ErrResourceNotFound) })
+		task, err := decodeTask(r, 0)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-	return router
+		saved, err := s.service.SaveToChecklist(r.Context(), checklistID, task)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.Header().Set("ETag", etag(*saved))
+		json.NewEncoder(w).Encode(makeTaskResponse(*saved, renderHTML(r)))
+	}
 }
 
-const (
-	contentTypeKey   = "Content-Type"
-	contentTypeValue = "application/json; charset=utf-8"
-)
+func (s *server) handleListChecklistTasks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checklistID, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-var (
-	ErrNonNumericTaskID = errors.New("task id in path must be numeric")
-	ErrResourceNotFound = errors.New("resource not found")
-	ErrMethodNotAllowed = errors.New("method not allowed")
-)
+		list, err := s.service.ListChecklistTasks(r.Context(), checklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		encodeTaskList(w, r, list)
+	}
+}
 
-type ErrInvalidRequestBody struct{ err error }
+func (s *server) handleRemoveChecklistTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checklistID, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-func (e ErrInvalidRequestBody) Error() string { return fmt.Sprintf("invalid request body: %v", e.err) }
+		version, err := s.checkedVersion(r, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-type server struct {
-	service Service
+		if err := s.service.RemoveFromChecklist(r.Context(), checklistID, id, version); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
 }
 
-func (s *server) handleSaveTask() http.HandlerFunc {
-	type request struct {
-		Name string `json:"name"`
-	}
-	type response struct {
-		ID   int64  `json:"id"`
-		Name string `json:"name"`
-		Done bool   `json:"done"`
+func (s *server) handleToggleChecklistTask() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		checklistID, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		version, err := s.checkedVersion(r, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if err := s.service.ToggleDoneInChecklist(r.Context(), checklistID, id, version); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
 	}
+}
+
+func (s *server) handleUpdateChecklistTask() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeError(w, ErrInvalidRequestBody{err})
+		checklistID, err := pathID(r, "listid", ErrNonNumericChecklistID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		id, err := pathID(r, "id", ErrNonNumericTaskID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		task, err := decodeTask(r, id)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
-		task, err := s.service.Save(r.Context(), todo.Task{Name: req.Name})
+		current, err := s.checkPreconditions(r, id)
 		if err != nil {
 			writeError(w, err)
 			return
 		}
+		if current != nil {
+			task.Version = current.Version
+		}
+
+		updated, isCreated, err := s.service.UpdateInChecklist(r.Context(), checklistID, task)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
 		w.Header().Set(contentTypeKey, contentTypeValue)
-		json.NewEncoder(w).Encode(response{ID: task.ID, Name: task.Name, Done: task.Done})
+		w.Header().Set("ETag", etag(*updated))
+		if isCreated {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(makeTaskResponse(*updated, renderHTML(r)))
 	}
 }
 
-func (s *server) handleListTasks() http.HandlerFunc {
-	type task struct {
-		ID   int64  `json:"id"`
-		Name string `json:"name"`
-		Done bool   `json:"done"`
+func (s *server) handleCreateTemplate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		template, err := decodeTemplate(r, 0)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		created, err := s.service.CreateTemplate(r.Context(), template)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(makeTemplateResponse(*created))
 	}
-	type response []task
+}
+
+func (s *server) handleListTemplates() http.HandlerFunc {
+	type response []templateResponse
 	return func(w http.ResponseWriter, r *http.Request) {
-		list, err := s.service.List(r.Context())
+		list, err := s.service.ListTemplates(r.Context())
 		if err != nil {
 			writeError(w, err)
 			return
@@ -118,104 +2371,259 @@ func (s *server) handleListTasks() http.HandlerFunc {
 
 		resp := make(response, 0, len(list))
 		for _, v := range list {
-			resp = append(resp, task{ID: v.ID, Name: v.Name, Done: v.Done})
+			resp = append(resp, makeTemplateResponse(v))
 		}
 		w.Header().Set(contentTypeKey, contentTypeValue)
 		json.NewEncoder(w).Encode(resp)
 	}
 }
 
-func (s *server) handleRemoveTask() http.HandlerFunc {
+func (s *server) handleGetTemplate() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.ParseInt(way.Param(r.Context(), "id"), 10, 64)
+		id, err := pathID(r, "templateid", ErrNonNumericTemplateID)
 		if err != nil {
-			writeError(w, ErrNonNumericTaskID)
+			writeError(w, err)
 			return
 		}
 
-		if err := s.service.Remove(r.Context(), id); err != nil {
+		template, err := s.service.GetTemplate(r.Context(), id)
+		if err != nil {
 			writeError(w, err)
 			return
 		}
-		w.WriteHeader(http.StatusNoContent)
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeTemplateResponse(*template))
 	}
 }
 
-func (s *server) handleToggleTask() http.HandlerFunc {
+func (s *server) handleUpdateTemplate() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.ParseInt(way.Param(r.Context(), "id"), 10, 64)
+		id, err := pathID(r, "templateid", ErrNonNumericTemplateID)
 		if err != nil {
-			writeError(w, ErrNonNumericTaskID)
+			writeError(w, err)
+			return
+		}
+
+		template, err := decodeTemplate(r, id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		updated, err := s.service.UpdateTemplate(r.Context(), template)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		json.NewEncoder(w).Encode(makeTemplateResponse(*updated))
+	}
+}
+
+func (s *server) handleRemoveTemplate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathID(r, "templateid", ErrNonNumericTemplateID)
+		if err != nil {
+			writeError(w, err)
 			return
 		}
 
-		if err := s.service.ToggleDone(r.Context(), id); err != nil {
+		if err := s.service.RemoveTemplate(r.Context(), id); err != nil {
 			writeError(w, err)
+			return
 		}
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
-func (s *server) handleUpdateTask() http.HandlerFunc {
+func (s *server) handleInstantiateTemplate() http.HandlerFunc {
 	type request struct {
-		Name string `json:"name"`
-		Done bool   `json:"done"`
-	}
-	type response struct {
-		ID   int64  `json:"id"`
-		Name string `json:"name"`
-		Done bool   `json:"done"`
+		ChecklistID int64     `json:"checklist_id"`
+		Start       time.Time `json:"start"` // defaults to now
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.ParseInt(way.Param(r.Context(), "id"), 10, 64)
+		id, err := pathID(r, "templateid", ErrNonNumericTemplateID)
 		if err != nil {
-			writeError(w, ErrNonNumericTaskID)
+			writeError(w, err)
 			return
 		}
 
 		var req request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeError(w, ErrInvalidRequestBody{err})
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
 			return
 		}
 
-		task, isCreated, err := s.service.Update(r.Context(), todo.Task{ID: id, Name: req.Name, Done: req.Done})
+		tasks, err := s.service.InstantiateTemplate(r.Context(), id, req.ChecklistID, req.Start)
 		if err != nil {
 			writeError(w, err)
 			return
 		}
 
-		if isCreated {
-			w.WriteHeader(http.StatusCreated)
+		resp := make([]taskResponse, 0, len(tasks))
+		for _, v := range tasks {
+			resp = append(resp, makeTaskResponse(v, renderHTML(r)))
+		}
+		w.Header().Set(contentTypeKey, contentTypeValue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// batchRequest is the JSON body of a batch. Its mode is "all_or_nothing" unless
+// it's "per_item", in which case each operation succeeds or fails on its own.
+type batchRequest struct {
+	Mode       string `json:"mode"`
+	Operations []struct {
+		Op   string      `json:"op"`
+		ID   int64       `json:"id"`   // the task to update, toggle or delete
+		Task taskRequest `json:"task"` // the task to create or to replace
+	} `json:"operations"`
+}
+
+// batchResultResponse is the outcome of one operation of a batch, with the status
+// the operation would have gotten had it been requested on its own.
+type batchResultResponse struct {
+	Status int           `json:"status"`
+	Task   *taskResponse `json:"task,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+func (s *server) handleBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req batchRequest
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var allOrNothing bool
+		switch req.Mode {
+		case "", "all_or_nothing":
+			allOrNothing = true
+		case "per_item":
+		default:
+			writeError(w, ErrInvalidBatchMode)
+			return
+		}
+
+		if len(req.Operations) == 0 || len(req.Operations) > todo.MaxBatchSize {
+			writeError(w, todo.ErrInvalidBatchSize)
+			return
+		}
+
+		// operations that can't be decoded fail without reaching the service, and
+		// unless each operation succeeds or fails on its own, none of the others is run
+		ops := make([]todo.BatchOperation, 0, len(req.Operations))
+		invalid := make(map[int]error)
+		for i, v := range req.Operations {
+			op := todo.BatchOperation{Op: todo.BatchOp(v.Op), ID: v.ID}
+			if op.Op == todo.BatchCreate || op.Op == todo.BatchUpdate {
+				task, err := makeTask(v.Task, v.ID)
+				if err != nil {
+					invalid[i] = err
+					continue
+				}
+				op.Task = task
+			}
+			ops = append(ops, op)
+		}
+		if allOrNothing && len(invalid) > 0 {
+			ops = nil
+		}
+
+		var results []todo.BatchResult
+		if len(ops) > 0 {
+			var err error
+			if results, err = s.service.Batch(r.Context(), ops, allOrNothing); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		resp := make([]batchResultResponse, 0, len(req.Operations))
+		for i := range req.Operations {
+			if err, ok := invalid[i]; ok {
+				resp = append(resp, batchResultResponse{Status: errorStatus(err), Error: err.Error()})
+				continue
+			}
+			if len(results) == 0 {
+				err := todo.ErrBatchSkipped
+				resp = append(resp, batchResultResponse{Status: errorStatus(err), Error: err.Error()})
+				continue
+			}
+			result := results[0]
+			results = results[1:]
+			if result.Err != nil {
+				resp = append(resp, batchResultResponse{Status: errorStatus(result.Err), Error: result.Err.Error()})
+				continue
+			}
+
+			status := http.StatusOK
+			switch {
+			case result.Created:
+				status = http.StatusCreated
+			case result.Task == nil:
+				status = http.StatusNoContent
+			}
+			var task *taskResponse
+			if result.Task != nil {
+				v := makeTaskResponse(*result.Task, renderHTML(r))
+				task = &v
+			}
+			resp = append(resp, batchResultResponse{Status: status, Task: task})
 		}
 
 		w.Header().Set(contentTypeKey, contentTypeValue)
-		json.NewEncoder(w).Encode(response{ID: task.ID, Name: task.Name, Done: task.Done})
+		json.NewEncoder(w).Encode(resp)
 	}
 }
 
 func writeError(w http.ResponseWriter, err error) {
 	w.Header().Set(contentTypeKey, contentTypeValue)
+	w.WriteHeader(errorStatus(err))
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+}
 
+// errorStatus returns the HTTP status code that the error is reported with.
+func errorStatus(err error) int {
 	switch err {
-	case ErrResourceNotFound, todo.ErrTaskNotFound:
-		w.WriteHeader(http.StatusNotFound)
-	case todo.ErrTaskAlreadyExists:
-		w.WriteHeader(http.StatusConflict)
-	case ErrNonNumericTaskID:
-		w.WriteHeader(http.StatusBadRequest)
+	case ErrResourceNotFound, todo.ErrTaskNotFound, todo.ErrChecklistNotFound, todo.ErrCommentNotFound,
+		todo.ErrDependencyNotFound, todo.ErrUserNotFound, todo.ErrTimeEntryNotFound, todo.ErrTemplateNotFound,
+		todo.ErrReminderNotFound, todo.ErrAttachmentNotFound:
+		return http.StatusNotFound
+	case todo.ErrTaskAlreadyExists, todo.ErrSubtaskCycle, todo.ErrSubtaskTooDeep, todo.ErrParentTaskTrashed,
+		todo.ErrDependencyCycle, todo.ErrTaskBlocked, todo.ErrIllegalTransition, todo.ErrUserAlreadyExists,
+		todo.ErrTimerRunning, todo.ErrNoTimerRunning:
+		return http.StatusConflict
+	case ErrNonNumericTaskID, ErrNonNumericChecklistID, ErrNonNumericCommentID, ErrInvalidMoveTarget, todo.ErrInvalidPriority,
+		todo.ErrInvalidTag, todo.ErrParentTaskNotFound, todo.ErrEmptyComment, todo.ErrInvalidStatus,
+		todo.ErrInvalidUserName, todo.ErrAssigneeNotFound, todo.ErrInvalidEstimate, todo.ErrInvalidTimeEntry,
+		ErrNonNumericTimeEntryID, ErrNonNumericTemplateID, todo.ErrEmptyTemplate, todo.ErrInvalidDueOffset,
+		ErrNonNumericReminderID, todo.ErrInvalidReminder, todo.ErrTaskNotDue, ErrNonNumericAttachmentID,
+		ErrMissingFile, todo.ErrInvalidAttachmentName, todo.ErrInvalidBatchSize, todo.ErrInvalidBatchOp,
+		ErrInvalidBatchMode, todo.ErrInvalidMoveTarget:
+		return http.StatusBadRequest
+	case ErrRequestBodyTooLarge, todo.ErrAttachmentTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case todo.ErrUnsupportedContentType:
+		return http.StatusUnsupportedMediaType
+	case todo.ErrBatchRolledBack, todo.ErrBatchSkipped:
+		return http.StatusFailedDependency
+	case todo.ErrBatchNotAtomic:
+		return http.StatusNotImplemented
 	case ErrMethodNotAllowed:
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		return http.StatusMethodNotAllowed
+	case ErrPreconditionFailed, todo.ErrVersionConflict:
+		return http.StatusPreconditionFailed
 	default:
 		switch err.(type) {
-		case ErrInvalidRequestBody:
-			w.WriteHeader(http.StatusBadRequest)
+		case ErrInvalidRequestBody, ErrInvalidQueryParam, todo.ErrInvalidRecurrence:
+			return http.StatusBadRequest
 		default:
-			w.WriteHeader(http.StatusInternalServerError)
+			return http.StatusInternalServerError
 		}
 	}
-
-	json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
 }
 
 type loggingResponseWriter struct {
@@ -228,6 +2636,17 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
+// actorMiddleware attributes the changes made by a request to the actor named
+// in its X-Actor header, if any.
+func actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get("X-Actor"); actor != "" {
+			r = r.WithContext(todo.ContextWithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func httpLoggingMiddleware(logger log.Logger, operation string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2,12 +2,16 @@ package checklist_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +22,10 @@ import (
 	"github.com/jarri-abidi/todo/pkg/todo"
 )
 
+// clock timestamps the tasks saved in these tests with a fixed time,
+// so that responses can be compared as a whole.
+func clock() time.Time { return time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC) }
+
 func TestToggleTask(t *testing.T) {
 	tt := []struct {
 		Name            string
@@ -54,7 +62,7 @@ func TestToggleTask(t *testing.T) {
 			var (
 				require = require.New(t)
 				assert  = assert.New(t)
-				svc     = checklist.NewService(inmem.NewTaskRepository())
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
 				handler = checklist.NewServer(svc, log.NewNopLogger())
 			)
 
@@ -100,9 +108,9 @@ func TestListTasks(t *testing.T) {
 				{ID: 3, Name: "Roti le ao", Done: false},
 			},
 			Expected: `[
-				{"id": 1, "name": "Kachra phenk k ao", "done": false},
-				{"id": 2, "name": "Gaari ki service karalo", "done": false},
-				{"id": 3, "name": "Roti le ao", "done": false}
+				{"id": 1, "name": "Kachra phenk k ao", "done": false, "status": "todo", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z"},
+				{"id": 2, "name": "Gaari ki service karalo", "done": false, "status": "todo", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z"},
+				{"id": 3, "name": "Roti le ao", "done": false, "status": "todo", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z"}
 			]`,
 		},
 		{
@@ -111,7 +119,7 @@ func TestListTasks(t *testing.T) {
 				{ID: 1, Name: "Kachra phenk k ao", Done: false},
 			},
 			Expected: `[
-				{"id": 1, "name": "Kachra phenk k ao", "done": false}
+				{"id": 1, "name": "Kachra phenk k ao", "done": false, "status": "todo", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z"}
 			]`,
 		},
 	}
@@ -121,7 +129,7 @@ func TestListTasks(t *testing.T) {
 			var (
 				require = require.New(t)
 				assert  = assert.New(t)
-				svc     = checklist.NewService(inmem.NewTaskRepository())
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
 				handler = checklist.NewServer(svc, log.NewNopLogger())
 			)
 
@@ -159,7 +167,7 @@ func TestReplaceTask(t *testing.T) {
 			ExpectedName:    "Pawdo ko paani daal do",
 			ExpectedDone:    true,
 			ExpectedCode:    http.StatusOK,
-			ExpectedRspBody: `{"id": 1,"name":"Pawdo ko paani daal do","done":true}`,
+			ExpectedRspBody: `{"id": 1,"name":"Pawdo ko paani daal do","done":true,"status":"done","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z"}`,
 		},
 		{
 			Name:            "Returns 201 and creates task for valid request if it doesn't exist",
@@ -168,7 +176,7 @@ func TestReplaceTask(t *testing.T) {
 			ExpectedName:    "Pawdo ko paani daal do",
 			ExpectedDone:    true,
 			ExpectedCode:    http.StatusCreated,
-			ExpectedRspBody: `{"id":1337,"name":"Pawdo ko paani daal do","done":true}`,
+			ExpectedRspBody: `{"id":1337,"name":"Pawdo ko paani daal do","done":true,"status":"done","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z"}`,
 		},
 		{
 			Name:            "Returns 400 and error msg for non-numeric id",
@@ -204,7 +212,7 @@ func TestReplaceTask(t *testing.T) {
 			var (
 				require = require.New(t)
 				assert  = assert.New(t)
-				svc     = checklist.NewService(inmem.NewTaskRepository())
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
 				handler = checklist.NewServer(svc, log.NewNopLogger())
 			)
 
@@ -240,3 +248,2112 @@ func TestReplaceTask(t *testing.T) {
 		})
 	}
 }
+
+func TestListTasksByDueDate(t *testing.T) {
+	due := time.Date(2021, time.May, 31, 9, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		Name            string
+		Query           string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and tasks in range for valid bounds",
+			Query:           "?due_after=2021-05-31T13:00:00%2B05:00&due_before=2021-06-01T00:00:00Z",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id": 1, "name": "Bill jama karao", "done": false, "status": "todo", "due_date": "2021-05-31T09:00:00Z", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 200 and overdue tasks",
+			Query:           "?due=overdue",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id": 1, "name": "Bill jama karao", "done": false, "status": "todo", "due_date": "2021-05-31T09:00:00Z", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 200 and empty list for tasks due today",
+			Query:           "?due=today&tz=Asia/Karachi",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[]`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown due filter",
+			Query:           "?due=someday",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid query param \"due\": unknown value \"someday\""}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown time zone",
+			Query:           "?due=today&tz=Mars/Olympus_Mons",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid query param \"tz\": unknown time zone Mars/Olympus_Mons"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for malformed timestamp",
+			Query:           "?due_before=kal",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid query param \"due_before\": parsing time \"kal\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"kal\" as \"2006\""}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				require = require.New(t)
+				assert  = assert.New(t)
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+				handler = checklist.NewServer(svc, log.NewNopLogger())
+			)
+
+			_, err := svc.Save(context.TODO(), todo.Task{Name: "Bill jama karao", DueDate: &due})
+			require.NoError(err, "could not save task")
+			_, err = svc.Save(context.TODO(), todo.Task{Name: "Kitaab wapas karo"})
+			require.NoError(err, "could not save task")
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/checklist/v1/tasks"+tc.Query, nil)
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestSaveTaskWithPriority(t *testing.T) {
+	tt := []struct {
+		Name            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and saves task with given priority",
+			ReqBody:         `{"name":"Cylinder bharwao","priority":"urgent"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Cylinder bharwao","done":false,"status":"todo","priority":"urgent","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 200 and defaults to normal priority",
+			ReqBody:         `{"name":"Cylinder bharwao"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Cylinder bharwao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown priority",
+			ReqBody:         `{"name":"Cylinder bharwao","priority":"whenever"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid task priority"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				require = require.New(t)
+				assert  = assert.New(t)
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+				handler = checklist.NewServer(svc, log.NewNopLogger())
+			)
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/checklist/v1/tasks", strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestListTasksByTags(t *testing.T) {
+	tt := []struct {
+		Name            string
+		Path            string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:         "Returns 200 and tasks carrying any of the tags",
+			Path:         "/checklist/v1/tasks?tag=auth&tag=frontend",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[
+				{"id": 1, "name": "Login page banao", "done": false, "status": "todo", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z", "tags": ["auth", "frontend"]},
+				{"id": 2, "name": "Token refresh theek karo", "done": false, "status": "todo", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z", "tags": ["auth", "backend"]}
+			]`,
+		},
+		{
+			Name:         "Returns 200 and tasks carrying all of the tags",
+			Path:         "/checklist/v1/tasks?tag=auth&tag=frontend&tag_match=all",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[
+				{"id": 1, "name": "Login page banao", "done": false, "status": "todo", "priority": "normal", "created_at": "2021-06-01T12:00:00Z", "updated_at": "2021-06-01T12:00:00Z", "tags": ["auth", "frontend"]}
+			]`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown tag match",
+			Path:            "/checklist/v1/tasks?tag=auth&tag_match=some",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid query param \"tag_match\": unknown value \"some\""}`,
+		},
+		{
+			Name:         "Returns 200 and tags with usage counts",
+			Path:         "/checklist/v1/tags",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[
+				{"name": "auth", "task_count": 2},
+				{"name": "backend", "task_count": 1},
+				{"name": "frontend", "task_count": 1}
+			]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				require = require.New(t)
+				assert  = assert.New(t)
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+				handler = checklist.NewServer(svc, log.NewNopLogger())
+			)
+
+			_, err := svc.Save(context.TODO(), todo.Task{Name: "Login page banao", Tags: []string{"frontend", "auth"}})
+			require.NoError(err, "could not save task")
+			_, err = svc.Save(context.TODO(), todo.Task{Name: "Token refresh theek karo", Tags: []string{"backend", "auth"}})
+			require.NoError(err, "could not save task")
+			_, err = svc.Save(context.TODO(), todo.Task{Name: "Chai peeyo"})
+			require.NoError(err, "could not save task")
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tc.Path, nil)
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestChecklistEndpoints(t *testing.T) {
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 201 and creates checklist",
+			Method:          "POST",
+			Path:            "/checklist/v1/lists",
+			ReqBody:         `{"name":"Daftar"}`,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":2,"name":"Daftar"}`,
+		},
+		{
+			Name:            "Returns 200 and lists checklists",
+			Method:          "GET",
+			Path:            "/checklist/v1/lists",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"name":"Ghar"}]`,
+		},
+		{
+			Name:            "Returns 200 and renames checklist",
+			Method:          "PUT",
+			Path:            "/checklist/v1/list/1",
+			ReqBody:         `{"name":"Makaan"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Makaan"}`,
+		},
+		{
+			Name:            "Returns 404 and error msg for checklist that doesn't exist",
+			Method:          "GET",
+			Path:            "/checklist/v1/list/1337",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"checklist not found"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for non-numeric checklist id",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/list/meow",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"checklist id in path must be numeric"}`,
+		},
+		{
+			Name:            "Returns 200 and saves task to checklist",
+			Method:          "POST",
+			Path:            "/checklist/v1/list/1/tasks",
+			ReqBody:         `{"name":"Sabzi le ao"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":2,"checklist_id":1,"name":"Sabzi le ao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 200 and lists tasks of checklist",
+			Method:          "GET",
+			Path:            "/checklist/v1/list/1/tasks",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"checklist_id":1,"name":"Geezer chala do","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 404 and error msg for task outside of checklist",
+			Method:          "PATCH",
+			Path:            "/checklist/v1/list/1/task/1337",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"task not found"}`,
+		},
+		{
+			Name:            "Returns 200 and replaces task in checklist",
+			Method:          "PUT",
+			Path:            "/checklist/v1/list/1/task/1",
+			ReqBody:         `{"name":"Geezer band karo","done":true}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"checklist_id":1,"name":"Geezer band karo","done":true,"status":"done","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				require = require.New(t)
+				assert  = assert.New(t)
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+				handler = checklist.NewServer(svc, log.NewNopLogger())
+			)
+
+			home, err := svc.CreateChecklist(context.TODO(), todo.Checklist{Name: "Ghar"})
+			require.NoError(err, "could not create checklist")
+			_, err = svc.SaveToChecklist(context.TODO(), home.ID, todo.Task{Name: "Geezer chala do"})
+			require.NoError(err, "could not save task to checklist")
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestSubtaskEndpoints(t *testing.T) {
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and saves subtask",
+			Method:          "POST",
+			Path:            "/checklist/v1/tasks",
+			ReqBody:         `{"name":"Biryani","parent_id":2}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":4,"parent_id":2,"name":"Biryani","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for parent that doesn't exist",
+			Method:          "POST",
+			Path:            "/checklist/v1/tasks",
+			ReqBody:         `{"name":"Biryani","parent_id":1337}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"parent task not found"}`,
+		},
+		{
+			Name:            "Returns 409 and error msg for cyclic subtasks",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/1",
+			ReqBody:         `{"name":"Dawat ki tayyari","parent_id":2}`,
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"task cannot be a subtask of itself or of its subtasks"}`,
+		},
+		{
+			Name:            "Returns 200 and lists subtasks",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/1/subtasks",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":2,"parent_id":1,"name":"Khana banao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:         "Returns 200 and nests subtasks under their parent",
+			Method:       "GET",
+			Path:         "/checklist/v1/tasks?tree=true",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[
+				{"id":1,"name":"Dawat ki tayyari","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","subtasks":[
+					{"id":2,"parent_id":1,"name":"Khana banao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}
+				]},
+				{"id":3,"name":"Bartan dho lo","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}
+			]`,
+		},
+		{
+			Name:            "Returns 400 and error msg for non-boolean tree param",
+			Method:          "GET",
+			Path:            "/checklist/v1/tasks?tree=haan",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid query param \"tree\": strconv.ParseBool: parsing \"haan\": invalid syntax"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				require = require.New(t)
+				assert  = assert.New(t)
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+				handler = checklist.NewServer(svc, log.NewNopLogger())
+			)
+
+			party, err := svc.Save(context.TODO(), todo.Task{Name: "Dawat ki tayyari"})
+			require.NoError(err, "could not save task")
+			_, err = svc.Save(context.TODO(), todo.Task{Name: "Khana banao", ParentID: party.ID})
+			require.NoError(err, "could not save subtask")
+			_, err = svc.Save(context.TODO(), todo.Task{Name: "Bartan dho lo"})
+			require.NoError(err, "could not save task")
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestToggleTaskCascade(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	party, err := svc.Save(context.TODO(), todo.Task{Name: "Dawat ki tayyari"})
+	require.NoError(err, "could not save task")
+	_, err = svc.Save(context.TODO(), todo.Task{Name: "Khana banao", ParentID: party.ID})
+	require.NoError(err, "could not save subtask")
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("PATCH", "/checklist/v1/task/1?cascade=true", nil)
+	require.NoError(err, "could not create http request")
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusNoContent, rec.Result().StatusCode, "unexpected http status code")
+	list, err := svc.List(context.TODO())
+	require.NoError(err, "could not list tasks")
+	for _, task := range list {
+		assert.True(task.Done, "expected %q to be done", task.Name)
+	}
+}
+
+func TestSaveRecurringTask(t *testing.T) {
+	tt := []struct {
+		Name            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and saves task with given recurrence rule",
+			ReqBody:         `{"name":"Weekly report","recurrence":"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Weekly report","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","recurrence":"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"}`,
+		},
+		{
+			Name:            "Returns 200 and expands shorthand recurrence",
+			ReqBody:         `{"name":"Stand-up prep","recurrence":"daily"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Stand-up prep","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","recurrence":"FREQ=DAILY"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unsupported frequency",
+			ReqBody:         `{"name":"Stand-up prep","recurrence":"FREQ=HOURLY"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid recurrence rule: unknown FREQ \"HOURLY\""}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for BYDAY on a daily rule",
+			ReqBody:         `{"name":"Stand-up prep","recurrence":"FREQ=DAILY;BYDAY=MO"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid recurrence rule: BYDAY is only supported with FREQ=WEEKLY"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				require = require.New(t)
+				assert  = assert.New(t)
+				svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+				handler = checklist.NewServer(svc, log.NewNopLogger())
+			)
+
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/checklist/v1/tasks", strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestListTasksPagination(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	for _, name := range []string{"Chai banao", "Anday lao", "Bartan dho"} {
+		_, err := svc.Save(context.TODO(), todo.Task{Name: name})
+		require.NoError(err, "could not save task")
+	}
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/checklist/v1/tasks?sort=name&limit=2", nil)
+	require.NoError(err, "could not create http request")
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	assert.JSONEq(`[
+		{"id":2,"name":"Anday lao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"},
+		{"id":3,"name":"Bartan dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}
+	]`, rec.Body.String(), "unexpected http response body")
+
+	link := rec.Header().Get("Link")
+	require.True(strings.HasPrefix(link, "</checklist/v1/tasks?") && strings.HasSuffix(link, `>; rel="next"`), "unexpected link header %q", link)
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`), nil)
+	require.NoError(err, "could not create http request")
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	assert.JSONEq(`[{"id":1,"name":"Chai banao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`, rec.Body.String(), "unexpected http response body")
+	assert.Empty(rec.Header().Get("Link"), "expected no link to a next page")
+
+	for query, expected := range map[string]string{
+		"limit=0":       `{"error":"invalid query param \"limit\": must be a number from 1 to 1000"}`,
+		"sort=color":    `{"error":"invalid query param \"sort\": invalid sort field"}`,
+		"order=up":      `{"error":"invalid query param \"order\": unknown value \"up\""}`,
+		"cursor=bogus!": `{"error":"invalid query param \"cursor\": invalid cursor"}`,
+	} {
+		rec = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", "/checklist/v1/tasks?"+query, nil)
+		require.NoError(err, "could not create http request")
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(http.StatusBadRequest, rec.Result().StatusCode, "unexpected http status code for %s", query)
+		assert.JSONEq(expected, rec.Body.String(), "unexpected http response body for %s", query)
+	}
+
+	// listings that don't ask for pages aren't cut off at the default page size
+	for i := 0; i < 100; i++ {
+		_, err := svc.Save(context.TODO(), todo.Task{Name: fmt.Sprintf("Kaam %d", i)})
+		require.NoError(err, "could not save task")
+	}
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/checklist/v1/tasks", nil)
+	require.NoError(err, "could not create http request")
+
+	handler.ServeHTTP(rec, req)
+
+	var list []json.RawMessage
+	require.NoError(json.NewDecoder(rec.Body).Decode(&list), "could not decode http response body")
+	assert.Len(list, 103, "expected every task to be listed")
+	assert.Empty(rec.Header().Get("Link"), "expected no link to a next page")
+}
+
+func TestConditionalRequests(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Bijli ka bill"})
+	require.NoError(err, "could not save task")
+
+	tt := []struct {
+		Name         string
+		Method       string
+		Path         string
+		Header       string
+		Value        string
+		ReqBody      string
+		ExpectedCode int
+		ExpectedETag string
+	}{
+		{"Returns task with its etag", "GET", "/checklist/v1/task/1", "", "", "", http.StatusOK, `"1"`},
+		{"Returns 304 if etag matches", "GET", "/checklist/v1/task/1", "If-None-Match", `"1"`, "", http.StatusNotModified, `"1"`},
+		{"Replaces task if etag matches", "PUT", "/checklist/v1/task/1", "If-Match", `"1"`, `{"name":"Bijli ka bill bharo"}`, http.StatusOK, `"2"`},
+		{"Returns 412 on replace with stale etag", "PUT", "/checklist/v1/task/1", "If-Match", `"1"`, `{"name":"Bijli"}`, http.StatusPreconditionFailed, ""},
+		{"Returns 412 on toggle with stale etag", "PATCH", "/checklist/v1/task/1", "If-Match", `"1"`, "", http.StatusPreconditionFailed, ""},
+		{"Returns 412 on create if task must exist", "PUT", "/checklist/v1/task/2", "If-Match", "*", `{"name":"Gas ka bill"}`, http.StatusPreconditionFailed, ""},
+		{"Creates task if it must not exist", "PUT", "/checklist/v1/task/2", "If-None-Match", "*", `{"name":"Gas ka bill"}`, http.StatusCreated, `"1"`},
+		{"Returns 412 on replace if task must not exist", "PUT", "/checklist/v1/task/2", "If-None-Match", "*", `{"name":"Gas ka bill"}`, http.StatusPreconditionFailed, ""},
+		{"Removes task if etag matches", "DELETE", "/checklist/v1/task/1", "If-Match", `W/"3", "2"`, "", http.StatusNoContent, ""},
+	}
+
+	for _, tc := range tt {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+		require.NoError(err, "could not create http request")
+		if tc.Header != "" {
+			req.Header.Set(tc.Header, tc.Value)
+		}
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code: %s", tc.Name)
+		assert.Equal(tc.ExpectedETag, rec.Header().Get("ETag"), "unexpected etag: %s", tc.Name)
+		if tc.ExpectedCode == http.StatusPreconditionFailed {
+			assert.JSONEq(`{"error":"precondition failed"}`, rec.Body.String(), "unexpected http response body: %s", tc.Name)
+		}
+	}
+}
+
+func TestTrashEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, nil)
+		require.NoError(err, "could not create http request")
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("DELETE", "/checklist/v1/task/1")
+	assert.Equal(http.StatusNoContent, rec.Result().StatusCode, "unexpected http status code")
+
+	rec = serve("GET", "/checklist/v1/trash")
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	var trash []struct {
+		ID        int64      `json:"id"`
+		DeletedAt *time.Time `json:"deleted_at"`
+	}
+	require.NoError(json.NewDecoder(rec.Body).Decode(&trash), "could not decode http response body")
+	require.Len(trash, 1)
+	assert.Equal(int64(1), trash[0].ID)
+	assert.NotNil(trash[0].DeletedAt, "expected trashed task to have a deletion time")
+
+	rec = serve("DELETE", "/checklist/v1/trash")
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	assert.JSONEq(`{"purged":0}`, rec.Body.String(), "expected task to be kept during the retention period")
+
+	rec = serve("POST", "/checklist/v1/trash/1/restore")
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	assert.JSONEq(`{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`, rec.Body.String(), "unexpected http response body")
+
+	rec = serve("POST", "/checklist/v1/trash/1/restore")
+	assert.Equal(http.StatusNotFound, rec.Result().StatusCode, "unexpected http status code")
+	assert.JSONEq(`{"error":"task not found"}`, rec.Body.String(), "unexpected http response body")
+}
+
+func TestListTaskHistory(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	serve := func(method, path string, body io.Reader) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, body)
+		require.NoError(err, "could not create http request")
+		req.Header.Set("X-Actor", "jarri")
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("POST", "/checklist/v1/tasks", strings.NewReader(`{"name":"Kapre dho"}`))
+	require.Equal(http.StatusOK, rec.Result().StatusCode, "could not save task")
+	rec = serve("PATCH", "/checklist/v1/task/1", nil)
+	require.Equal(http.StatusNoContent, rec.Result().StatusCode, "could not toggle task")
+
+	rec = serve("GET", "/checklist/v1/task/1/history?limit=1", nil)
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	assert.Equal(`</checklist/v1/task/1/history?cursor=1&limit=1>; rel="next"`, rec.Header().Get("Link"))
+	var history []struct {
+		ID        int64           `json:"id"`
+		TaskID    int64           `json:"task_id"`
+		Actor     string          `json:"actor"`
+		Operation string          `json:"operation"`
+		Before    json.RawMessage `json:"before"`
+		After     json.RawMessage `json:"after"`
+	}
+	require.NoError(json.NewDecoder(rec.Body).Decode(&history), "could not decode http response body")
+	require.Len(history, 1)
+	assert.Equal("save", history[0].Operation)
+	assert.Equal("jarri", history[0].Actor)
+	assert.Nil(history[0].Before)
+	assert.JSONEq(`{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`, string(history[0].After))
+
+	rec = serve("GET", "/checklist/v1/task/1/history?limit=1&cursor=1", nil)
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	assert.Empty(rec.Header().Get("Link"), "expected no more pages")
+	require.NoError(json.NewDecoder(rec.Body).Decode(&history), "could not decode http response body")
+	require.Len(history, 1)
+	assert.Equal("toggle_done", history[0].Operation)
+	assert.JSONEq(`{"id":1,"name":"Kapre dho","done":true,"status":"done","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z"}`, string(history[0].After))
+
+	rec = serve("GET", "/checklist/v1/task/2/history", nil)
+	assert.Equal(http.StatusNotFound, rec.Result().StatusCode, "unexpected http status code")
+}
+
+func TestListTasksByTimestamps(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(func() time.Time { return now }))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	for _, name := range []string{"Chai banao", "Anday lao", "Bartan dho"} {
+		_, err := svc.Save(context.TODO(), todo.Task{Name: name})
+		require.NoError(err, "could not save task")
+		now = now.Add(time.Hour)
+	}
+	require.NoError(svc.ToggleDone(context.TODO(), 3, 0), "could not toggle task")
+	now = now.Add(time.Hour)
+	require.NoError(svc.ToggleDone(context.TODO(), 1, 0), "could not toggle task")
+
+	list := func(query string) string {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/checklist/v1/tasks"+query, nil)
+		require.NoError(err, "could not create http request")
+		handler.ServeHTTP(rec, req)
+		require.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+
+		var tasks []struct {
+			Name string `json:"name"`
+		}
+		require.NoError(json.NewDecoder(rec.Body).Decode(&tasks), "could not decode http response body")
+		names := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			names = append(names, task.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	assert.Equal("Bartan dho,Anday lao,Chai banao", list("?sort=created_at&order=desc"))
+	assert.Equal("Bartan dho,Chai banao,Anday lao", list("?sort=completed_at"))
+	assert.Equal("Anday lao,Bartan dho", list("?created_after=2021-06-01T13:00:00Z&sort=updated_at"))
+	assert.Equal("Chai banao", list("?completed_after=2021-06-01T15:30:00Z"))
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/checklist/v1/tasks?completed_before=kal", nil)
+	require.NoError(err, "could not create http request")
+	handler.ServeHTTP(rec, req)
+	assert.Equal(http.StatusBadRequest, rec.Result().StatusCode, "unexpected http status code")
+}
+
+func TestTaskDescription(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		require.NoError(err, "could not create http request")
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("POST", "/checklist/v1/tasks", `{"name":"Sabzi le ao","description":"- **aloo**\n- pyaaz"}`)
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	assert.JSONEq(`{"id":1,"name":"Sabzi le ao","description":"- **aloo**\n- pyaaz","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`, rec.Body.String(), "unexpected http response body")
+
+	rec = serve("PUT", "/checklist/v1/task/1?render=html", `{"name":"Sabzi le ao","description":"[dukaan](javascript:alert(1)) <script>alert(2)</script>"}`)
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	var task struct {
+		Description     string `json:"description"`
+		DescriptionHTML string `json:"description_html"`
+	}
+	require.NoError(json.NewDecoder(rec.Body).Decode(&task), "could not decode http response body")
+	assert.Equal("[dukaan](javascript:alert(1)) <script>alert(2)</script>", task.Description, "expected raw Markdown to be returned as is")
+	assert.NotContains(task.DescriptionHTML, "script")
+	assert.NotContains(task.DescriptionHTML, "javascript:")
+
+	rec = serve("GET", "/checklist/v1/tasks?render=html", "")
+	assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+	var list []struct {
+		DescriptionHTML string `json:"description_html"`
+	}
+	require.NoError(json.NewDecoder(rec.Body).Decode(&list), "could not decode http response body")
+	require.Len(list, 1)
+	assert.Equal("<p>dukaan alert(2)</p>\n", list[0].DescriptionHTML)
+}
+
+func TestCommentEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 201 and adds comment to task",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/comments",
+			ReqBody:         `{"body":"Sirf safed wale"}`,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":1,"task_id":1,"author":"jarri","body":"Sirf safed wale","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for empty comment",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/comments",
+			ReqBody:         `{"body":""}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"comment must not be empty"}`,
+		},
+		{
+			Name:            "Returns 200 and edits comment",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/1/comments/1",
+			ReqBody:         `{"body":"Sirf rangeen wale"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"task_id":1,"author":"jarri","body":"Sirf rangeen wale","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 200 and lists comments of task",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/1/comments",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"task_id":1,"author":"jarri","body":"Sirf rangeen wale","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:         "Returns 204 and removes comment",
+			Method:       "DELETE",
+			Path:         "/checklist/v1/task/1/comments/1",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 404 and error msg for removed comment",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/1/comments/1",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"comment not found"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for non-numeric comment id",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/1/comments/pehla",
+			ReqBody:         `{"body":"Kuch bhi"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"comment id in path must be numeric"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+			req.Header.Set("X-Actor", "jarri")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestDependencyEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	for _, name := range []string{"Kapre dho", "Kapre sukhao"} {
+		_, err := svc.Save(context.TODO(), todo.Task{Name: name})
+		require.NoError(err, "could not save task")
+	}
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:         "Returns 204 and adds blocker to task",
+			Method:       "PUT",
+			Path:         "/checklist/v1/task/2/blockers/1",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 409 and error msg for cyclic dependency",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/1/blockers/2",
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"dependency would create a cycle"}`,
+		},
+		{
+			Name:            "Returns 200 and lists blockers of task",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/2/blockers",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 200 and lists blocked tasks",
+			Method:          "GET",
+			Path:            "/checklist/v1/tasks?blocked=true",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":2,"name":"Kapre sukhao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 200 and lists unblocked tasks",
+			Method:          "GET",
+			Path:            "/checklist/v1/tasks?blocked=false",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 409 and error msg for toggling blocked task",
+			Method:          "PATCH",
+			Path:            "/checklist/v1/task/2",
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"task is blocked by tasks that are not done"}`,
+		},
+		{
+			Name:            "Returns 409 and error msg for replacing blocked task with done one",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/2",
+			ReqBody:         `{"name":"Kapre sukhao","done":true}`,
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"task is blocked by tasks that are not done"}`,
+		},
+		{
+			Name:            "Returns 409 and error msg for replacing blocked task with one in done status",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/2",
+			ReqBody:         `{"name":"Kapre sukhao","status":"done"}`,
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"task is blocked by tasks that are not done"}`,
+		},
+		{
+			Name:         "Returns 204 and removes blocker from task",
+			Method:       "DELETE",
+			Path:         "/checklist/v1/task/2/blockers/1",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 404 and error msg for removed blocker",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/2/blockers/1",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"dependency not found"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	for _, name := range []string{"Kapre dho", "Kapre sukhao", "Kapre istri karo"} {
+		_, err := svc.Save(context.TODO(), todo.Task{Name: name})
+		require.NoError(err, "could not save task")
+	}
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and moves task before another",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/3/move",
+			ReqBody:         `{"before":1}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":3,"name":"Kapre istri karo","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 200 and moves task after another",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/move",
+			ReqBody:         `{"after":2}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:         "Returns 200 and lists tasks in the order they were moved to",
+			Method:       "GET",
+			Path:         "/checklist/v1/tasks?sort=position",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[{"id":3,"name":"Kapre istri karo","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"},` +
+				`{"id":2,"name":"Kapre sukhao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"},` +
+				`{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:         "Returns 200 and lists tasks in the order they were moved to by default",
+			Method:       "GET",
+			Path:         "/checklist/v1/tasks",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[{"id":3,"name":"Kapre istri karo","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"},` +
+				`{"id":2,"name":"Kapre sukhao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"},` +
+				`{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 400 and error msg for both before and after",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/move",
+			ReqBody:         `{"before":2,"after":3}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"exactly one of before and after must be given"}`,
+		},
+		{
+			Name:            "Returns 404 and error msg for non-existent target",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/move",
+			ReqBody:         `{"before":42}`,
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"task not found"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestTransitionTask(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and moves task to new status",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/transition",
+			ReqBody:         `{"status":"in_progress"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Kapre dho","done":false,"status":"in_progress","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 200 and lists tasks with status",
+			Method:          "GET",
+			Path:            "/checklist/v1/tasks?status=in_progress",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"name":"Kapre dho","done":false,"status":"in_progress","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown status",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/transition",
+			ReqBody:         `{"status":"archived"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid task status"}`,
+		},
+		{
+			Name:            "Returns 200 and marks task as done",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/transition",
+			ReqBody:         `{"status":"done"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Kapre dho","done":true,"status":"done","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 409 and error msg for illegal transition",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/transition",
+			ReqBody:         `{"status":"review"}`,
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"task cannot move from its current status to the requested one"}`,
+		},
+		{
+			Name:         "Returns 204 and toggles done task back to todo",
+			Method:       "PATCH",
+			Path:         "/checklist/v1/task/1",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 200 and shows task as todo after toggle",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/1",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestAssigneeEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	for _, name := range []string{"Kapre dho", "Kapre sukhao"} {
+		_, err := svc.Save(context.TODO(), todo.Task{Name: name})
+		require.NoError(err, "could not save task")
+	}
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 201 and creates user",
+			Method:          "POST",
+			Path:            "/checklist/v1/users",
+			ReqBody:         `{"name":"jarri"}`,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":1,"name":"jarri"}`,
+		},
+		{
+			Name:            "Returns 409 and error msg for existing user",
+			Method:          "POST",
+			Path:            "/checklist/v1/users",
+			ReqBody:         `{"name":"jarri"}`,
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"user already exists"}`,
+		},
+		{
+			Name:            "Returns 200 and lists users",
+			Method:          "GET",
+			Path:            "/checklist/v1/users",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"name":"jarri"}]`,
+		},
+		{
+			Name:            "Returns 200 and assigns task",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/2/assignee",
+			ReqBody:         `{"user_id":1}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":2,"assignee_id":1,"name":"Kapre sukhao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown assignee",
+			Method:          "PUT",
+			Path:            "/checklist/v1/task/1/assignee",
+			ReqBody:         `{"user_id":42}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"assignee not found"}`,
+		},
+		{
+			Name:            "Returns 200 and lists tasks assigned to actor",
+			Method:          "GET",
+			Path:            "/checklist/v1/my/tasks",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":2,"assignee_id":1,"name":"Kapre sukhao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 200 and lists tasks by assignee",
+			Method:          "GET",
+			Path:            "/checklist/v1/tasks?assignee_id=1",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":2,"assignee_id":1,"name":"Kapre sukhao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 200 and unassigns task",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/2/assignee",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":2,"name":"Kapre sukhao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 200 and no tasks once unassigned",
+			Method:          "GET",
+			Path:            "/checklist/v1/my/tasks",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+			req.Header.Set("X-Actor", "jarri")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestTimeTrackingEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.CreateChecklist(context.TODO(), todo.Checklist{Name: "Ghar"})
+	require.NoError(err, "could not create checklist")
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and saves task with estimate",
+			Method:          "POST",
+			Path:            "/checklist/v1/list/1/tasks",
+			ReqBody:         `{"name":"Deewar rang karo","estimate":"2h30m"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"checklist_id":1,"name":"Deewar rang karo","done":false,"status":"todo","priority":"normal","estimate":"2h30m0s","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for invalid estimate",
+			Method:          "POST",
+			Path:            "/checklist/v1/list/1/tasks",
+			ReqBody:         `{"name":"Deewar rang karo","estimate":"thori dair"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"estimate must be a non-negative duration such as 1h30m"}`,
+		},
+		{
+			Name:            "Returns 201 and starts timer",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/timer",
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":1,"task_id":1,"user":"jarri","start":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 409 and error msg for second timer",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/timer",
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"a timer is already running"}`,
+		},
+		{
+			Name:            "Returns 200 and stops timer",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/1/timer",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"task_id":1,"user":"jarri","start":"2021-06-01T12:00:00Z","end":"2021-06-01T12:00:00Z","duration":"0s"}`,
+		},
+		{
+			Name:            "Returns 409 and error msg for stopping stopped timer",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/1/timer",
+			ExpectedCode:    http.StatusConflict,
+			ExpectedRspBody: `{"error":"no timer is running on the task"}`,
+		},
+		{
+			Name:            "Returns 201 and adds time entry",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/time-entries",
+			ReqBody:         `{"start":"2021-05-31T10:00:00Z","end":"2021-05-31T11:15:00Z"}`,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":2,"task_id":1,"user":"jarri","start":"2021-05-31T10:00:00Z","end":"2021-05-31T11:15:00Z","duration":"1h15m0s"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for time entry ending before it starts",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/time-entries",
+			ReqBody:         `{"start":"2021-05-31T10:00:00Z","end":"2021-05-31T09:00:00Z"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"time entry must end after it starts"}`,
+		},
+		{
+			Name:         "Returns 200 and lists time entries",
+			Method:       "GET",
+			Path:         "/checklist/v1/task/1/time-entries",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[{"id":2,"task_id":1,"user":"jarri","start":"2021-05-31T10:00:00Z","end":"2021-05-31T11:15:00Z","duration":"1h15m0s"},` +
+				`{"id":1,"task_id":1,"user":"jarri","start":"2021-06-01T12:00:00Z","end":"2021-06-01T12:00:00Z","duration":"0s"}]`,
+		},
+		{
+			Name:            "Returns 200 and summarizes task time",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/1/time",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"estimate":"2h30m0s","spent":"1h15m0s"}`,
+		},
+		{
+			Name:            "Returns 200 and summarizes checklist time",
+			Method:          "GET",
+			Path:            "/checklist/v1/list/1/time",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"estimate":"2h30m0s","spent":"1h15m0s"}`,
+		},
+		{
+			Name:         "Returns 204 and removes time entry",
+			Method:       "DELETE",
+			Path:         "/checklist/v1/task/1/time-entries/2",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 404 and error msg for removed time entry",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/1/time-entries/2",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"time entry not found"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+			req.Header.Set("X-Actor", "jarri")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestTemplateEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.CreateChecklist(context.TODO(), todo.Checklist{Name: "Naukri"})
+	require.NoError(err, "could not create checklist")
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 400 and error msg for template without tasks",
+			Method:          "POST",
+			Path:            "/checklist/v1/templates",
+			ReqBody:         `{"name":"Onboarding","tasks":[]}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"template must have at least one task"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for invalid due offset",
+			Method:          "POST",
+			Path:            "/checklist/v1/templates",
+			ReqBody:         `{"name":"Onboarding","tasks":[{"name":"Laptop do","due_offset":"kal"}]}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"due offset must be a duration such as 48h"}`,
+		},
+		{
+			Name:            "Returns 201 and creates template",
+			Method:          "POST",
+			Path:            "/checklist/v1/templates",
+			ReqBody:         `{"name":"Onboarding","tasks":[{"name":"Laptop do","priority":"high","due_offset":"24h"},{"name":"Team se milao","estimate":"30m"}]}`,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":1,"name":"Onboarding","tasks":[{"name":"Laptop do","priority":"high","due_offset":"24h0m0s"},{"name":"Team se milao","priority":"normal","estimate":"30m0s"}]}`,
+		},
+		{
+			Name:            "Returns 200 and lists templates",
+			Method:          "GET",
+			Path:            "/checklist/v1/templates",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"name":"Onboarding","tasks":[{"name":"Laptop do","priority":"high","due_offset":"24h0m0s"},{"name":"Team se milao","priority":"normal","estimate":"30m0s"}]}]`,
+		},
+		{
+			Name:            "Returns 404 and error msg for unknown template",
+			Method:          "GET",
+			Path:            "/checklist/v1/template/2",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"template not found"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for non-numeric template id",
+			Method:          "GET",
+			Path:            "/checklist/v1/template/abc",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"template id in path must be numeric"}`,
+		},
+		{
+			Name:         "Returns 201 and instantiates template into checklist",
+			Method:       "POST",
+			Path:         "/checklist/v1/template/1/instantiate",
+			ReqBody:      `{"checklist_id":1}`,
+			ExpectedCode: http.StatusCreated,
+			ExpectedRspBody: `[{"id":1,"checklist_id":1,"name":"Laptop do","done":false,"status":"todo","priority":"high","due_date":"2021-06-02T12:00:00Z","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"},` +
+				`{"id":2,"checklist_id":1,"name":"Team se milao","done":false,"status":"todo","priority":"normal","estimate":"30m0s","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:            "Returns 404 and error msg for instantiating into unknown checklist",
+			Method:          "POST",
+			Path:            "/checklist/v1/template/1/instantiate",
+			ReqBody:         `{"checklist_id":2}`,
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"checklist not found"}`,
+		},
+		{
+			Name:            "Returns 200 and updates template",
+			Method:          "PUT",
+			Path:            "/checklist/v1/template/1",
+			ReqBody:         `{"name":"Onboarding v2","tasks":[{"name":"Laptop do"}]}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Onboarding v2","tasks":[{"name":"Laptop do","priority":"normal"}]}`,
+		},
+		{
+			Name:         "Returns 204 and removes template",
+			Method:       "DELETE",
+			Path:         "/checklist/v1/template/1",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 404 and error msg for instantiating removed template",
+			Method:          "POST",
+			Path:            "/checklist/v1/template/1/instantiate",
+			ReqBody:         `{}`,
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"template not found"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestListTasksByArchived(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = clock()
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(),
+			checklist.WithClock(func() time.Time { return now }))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	done, err := svc.Save(context.TODO(), todo.Task{Name: "Bijli ka bill bharo"})
+	require.NoError(err, "could not save task")
+	_, err = svc.Save(context.TODO(), todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+	require.NoError(svc.ToggleDone(context.TODO(), done.ID, 0), "could not toggle task")
+	now = now.Add(8 * 24 * time.Hour)
+	_, err = svc.ArchiveCompleted(context.TODO())
+	require.NoError(err, "could not archive tasks")
+
+	var (
+		archived = `{"id":1,"name":"Bijli ka bill bharo","done":true,"status":"done","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z","archived_at":"2021-06-09T12:00:00Z"}`
+		pending  = `{"id":2,"name":"Kapre dho","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`
+	)
+	tt := []struct {
+		Name            string
+		Path            string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and leaves out archived tasks by default",
+			Path:            "/checklist/v1/tasks",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[` + pending + `]`,
+		},
+		{
+			Name:            "Returns 200 and includes archived tasks",
+			Path:            "/checklist/v1/tasks?archived=include",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[` + archived + `,` + pending + `]`,
+		},
+		{
+			Name:            "Returns 200 and lists only archived tasks",
+			Path:            "/checklist/v1/tasks?archived=only",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[` + archived + `]`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown archived value",
+			Path:            "/checklist/v1/tasks?archived=maybe",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid query param \"archived\": unknown value \"maybe\""}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", tc.Path, nil)
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestReminderEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+		due     = time.Date(2021, time.June, 2, 9, 0, 0, 0, time.UTC)
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Bijli ka bill bharo", DueDate: &due})
+	require.NoError(err, "could not save task")
+	_, err = svc.Save(context.TODO(), todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 201 and adds reminder at a fixed time",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/reminders",
+			ReqBody:         `{"at":"2021-06-01T18:00:00Z"}`,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":1,"task_id":1,"at":"2021-06-01T18:00:00Z","attempts":0}`,
+		},
+		{
+			Name:            "Returns 201 and adds reminder before the due date",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/reminders",
+			ReqBody:         `{"before":"1h30m"}`,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":2,"task_id":1,"before":"1h30m0s","attempts":0}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for reminder with both a time and a duration",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/reminders",
+			ReqBody:         `{"at":"2021-06-01T18:00:00Z","before":"1h"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"reminder must have either a time or a non-negative duration before the due date"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for invalid duration",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/reminders",
+			ReqBody:         `{"before":"ek din"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"reminder must have either a time or a non-negative duration before the due date"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for reminder before the due date of task that isn't due",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/2/reminders",
+			ReqBody:         `{"before":"1h"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"task has no due date to be reminded before"}`,
+		},
+		{
+			Name:            "Returns 404 and error msg for reminder on non-existent task",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/9/reminders",
+			ReqBody:         `{"at":"2021-06-01T18:00:00Z"}`,
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"task not found"}`,
+		},
+		{
+			Name:         "Returns 200 and lists reminders",
+			Method:       "GET",
+			Path:         "/checklist/v1/task/1/reminders",
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"task_id":1,"at":"2021-06-01T18:00:00Z","attempts":0},` +
+				`{"id":2,"task_id":1,"before":"1h30m0s","attempts":0}]`,
+		},
+		{
+			Name:            "Returns 400 and error msg for non-numeric reminder id",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/1/reminders/abc",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"reminder id in path must be numeric"}`,
+		},
+		{
+			Name:         "Returns 204 and removes reminder",
+			Method:       "DELETE",
+			Path:         "/checklist/v1/task/1/reminders/1",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 404 and error msg for removed reminder",
+			Method:          "DELETE",
+			Path:            "/checklist/v1/task/1/reminders/1",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"reminder not found"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+// multipartBody returns a multipart/form-data body with the content as a file in the field, and its content type.
+func multipartBody(t *testing.T, field, filename, content string) (string, string) {
+	var b strings.Builder
+	w := multipart.NewWriter(&b)
+	part, err := w.CreateFormFile(field, filename)
+	require.NoError(t, err, "could not create form file")
+	_, err = io.WriteString(part, content)
+	require.NoError(t, err, "could not write form file")
+	require.NoError(t, w.Close(), "could not close multipart writer")
+	return b.String(), w.FormDataContentType()
+}
+
+func TestAttachmentEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock), checklist.WithMaxAttachmentSize(32))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Login ka bug theek karo"})
+	require.NoError(err, "could not save task")
+
+	logs, logsType := multipartBody(t, "file", "server.log", "panic: nil map")
+	large, largeType := multipartBody(t, "file", "server.log", strings.Repeat("panic: nil map\n", 3))
+	html, htmlType := multipartBody(t, "file", "page.html", "<html></html>")
+	missing, missingType := multipartBody(t, "screenshot", "server.log", "panic: nil map")
+
+	tt := []struct {
+		Name            string
+		Method          string
+		Path            string
+		ContentType     string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedHeaders map[string]string
+		ExpectedContent string // compared as is rather than as JSON
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 201 and adds attachment",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/attachments",
+			ContentType:     logsType,
+			ReqBody:         logs,
+			ExpectedCode:    http.StatusCreated,
+			ExpectedRspBody: `{"id":1,"task_id":1,"name":"server.log","content_type":"text/plain; charset=utf-8","size":14,"uploaded_by":"jarri","created_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 413 and error msg for attachment larger than allowed",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/attachments",
+			ContentType:     largeType,
+			ReqBody:         large,
+			ExpectedCode:    http.StatusRequestEntityTooLarge,
+			ExpectedRspBody: `{"error":"attachment is larger than allowed"}`,
+		},
+		{
+			Name:            "Returns 415 and error msg for attachment of a type that isn't allowed",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/attachments",
+			ContentType:     htmlType,
+			ReqBody:         html,
+			ExpectedCode:    http.StatusUnsupportedMediaType,
+			ExpectedRspBody: `{"error":"attachment type is not allowed"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for body without a file field",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/attachments",
+			ContentType:     missingType,
+			ReqBody:         missing,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"request must have a file in its file field"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for body that isn't multipart",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/1/attachments",
+			ContentType:     "application/json",
+			ReqBody:         `{"name":"server.log"}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"invalid request body: request Content-Type isn't multipart/form-data"}`,
+		},
+		{
+			Name:            "Returns 404 and error msg for attachment on non-existent task",
+			Method:          "POST",
+			Path:            "/checklist/v1/task/9/attachments",
+			ContentType:     logsType,
+			ReqBody:         logs,
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"task not found"}`,
+		},
+		{
+			Name:            "Returns 200 and lists attachments",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/1/attachments",
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `[{"id":1,"task_id":1,"name":"server.log","content_type":"text/plain; charset=utf-8","size":14,"uploaded_by":"jarri","created_at":"2021-06-01T12:00:00Z"}]`,
+		},
+		{
+			Name:         "Returns 200 and downloads attachment",
+			Method:       "GET",
+			Path:         "/checklist/v1/task/1/attachments/1",
+			ExpectedCode: http.StatusOK,
+			ExpectedHeaders: map[string]string{
+				"Content-Type":        "text/plain; charset=utf-8",
+				"Content-Length":      "14",
+				"Content-Disposition": `attachment; filename=server.log`,
+			},
+			ExpectedContent: "panic: nil map",
+		},
+		{
+			Name:            "Returns 400 and error msg for non-numeric attachment id",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/1/attachments/abc",
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"attachment id in path must be numeric"}`,
+		},
+		{
+			Name:         "Returns 204 and removes attachment",
+			Method:       "DELETE",
+			Path:         "/checklist/v1/task/1/attachments/1",
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:            "Returns 404 and error msg for removed attachment",
+			Method:          "GET",
+			Path:            "/checklist/v1/task/1/attachments/1",
+			ExpectedCode:    http.StatusNotFound,
+			ExpectedRspBody: `{"error":"attachment not found"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.Method, tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+			req.Header.Set("X-Actor", "jarri")
+			if tc.ContentType != "" {
+				req.Header.Set("Content-Type", tc.ContentType)
+			}
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			for key, value := range tc.ExpectedHeaders {
+				assert.Equal(value, rec.Result().Header.Get(key), "unexpected %s header", key)
+			}
+			if tc.ExpectedContent != "" {
+				assert.Equal(tc.ExpectedContent, rec.Body.String(), "unexpected http response body")
+			}
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestRequestBodyLimits(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		handler = checklist.NewServer(svc, log.NewNopLogger(),
+			checklist.WithMaxBodySize(64), checklist.WithRouteMaxBodySize("POST", "/checklist/v1/task/:id/attachments", 512))
+	)
+
+	logs, logsType := multipartBody(t, "file", "server.log", strings.Repeat("panic: nil map\n", 8))
+	large, largeType := multipartBody(t, "file", "server.log", strings.Repeat("panic: nil map\n", 64))
+
+	tt := []struct {
+		Name            string
+		Path            string
+		ContentType     string
+		ReqBody         string
+		UnknownLength   bool // makes the body be streamed without a Content-Length
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 200 and saves task with body within the limit",
+			Path:            "/checklist/v1/tasks",
+			ReqBody:         `{"name":"Cylinder bharwao"}`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedRspBody: `{"id":1,"name":"Cylinder bharwao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name:            "Returns 413 and error msg for body said to be larger than the limit",
+			Path:            "/checklist/v1/tasks",
+			ReqBody:         `{"name":"Cylinder bharwao","description":"Shell wala cylinder, naya wala nahi"}`,
+			ExpectedCode:    http.StatusRequestEntityTooLarge,
+			ExpectedRspBody: `{"error":"request body is larger than allowed"}`,
+		},
+		{
+			Name:            "Returns 413 and error msg for streamed body larger than the limit",
+			Path:            "/checklist/v1/tasks",
+			ReqBody:         `{"name":"Cylinder bharwao","description":"Shell wala cylinder, naya wala nahi"}`,
+			UnknownLength:   true,
+			ExpectedCode:    http.StatusRequestEntityTooLarge,
+			ExpectedRspBody: `{"error":"request body is larger than allowed"}`,
+		},
+		{
+			Name:         "Returns 201 and adds attachment within the route's own limit",
+			Path:         "/checklist/v1/task/1/attachments",
+			ContentType:  logsType,
+			ReqBody:      logs,
+			ExpectedCode: http.StatusCreated,
+		},
+		{
+			Name:            "Returns 413 and error msg for streamed upload larger than the route's own limit",
+			Path:            "/checklist/v1/task/1/attachments",
+			ContentType:     largeType,
+			ReqBody:         large,
+			UnknownLength:   true,
+			ExpectedCode:    http.StatusRequestEntityTooLarge,
+			ExpectedRspBody: `{"error":"request body is larger than allowed"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", tc.Path, strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+			if tc.ContentType != "" {
+				req.Header.Set("Content-Type", tc.ContentType)
+			}
+			if tc.UnknownLength {
+				req.ContentLength = -1
+			}
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			if tc.ExpectedRspBody != "" {
+				assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			}
+		})
+	}
+}
+
+func TestBatchEndpoints(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		r       = inmem.NewRepositories()
+		svc     = checklist.NewService(r, inmem.NewBlobStore(),
+			checklist.WithClock(clock), checklist.WithUnitOfWork(inmem.NewUnitOfWork(r)))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Doodh le aao"})
+	require.NoError(err, "could not save task")
+
+	tt := []struct {
+		Name            string
+		ReqBody         string
+		ExpectedCode    int
+		ExpectedRspBody string
+	}{
+		{
+			Name:            "Returns 400 and error msg for batch without operations",
+			ReqBody:         `{"operations":[]}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"batch must have at least one and at most 100 operations"}`,
+		},
+		{
+			Name:            "Returns 400 and error msg for unknown mode",
+			ReqBody:         `{"mode":"best_effort","operations":[{"op":"toggle","id":1}]}`,
+			ExpectedCode:    http.StatusBadRequest,
+			ExpectedRspBody: `{"error":"batch mode must be one of all_or_nothing or per_item"}`,
+		},
+		{
+			Name:         "Returns 200 and rolls back all operations if one fails",
+			ReqBody:      `{"operations":[{"op":"create","task":{"name":"Bijli ka bill bharo"}},{"op":"delete","id":9},{"op":"toggle","id":1}]}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[{"status":424,"error":"operation was rolled back because another one in the batch failed"},` +
+				`{"status":404,"error":"task not found"},` +
+				`{"status":424,"error":"operation was skipped because another one in the batch failed"}]`,
+		},
+		{
+			Name:         "Returns 200 and runs none of the operations if one is invalid",
+			ReqBody:      `{"operations":[{"op":"toggle","id":1},{"op":"create","task":{"name":"Bijli ka bill bharo","priority":"kabhi"}}]}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[{"status":424,"error":"operation was skipped because another one in the batch failed"},` +
+				`{"status":400,"error":"invalid task priority"}]`,
+		},
+		{
+			Name:         "Returns 200 and the status of each operation in per item mode",
+			ReqBody:      `{"mode":"per_item","operations":[{"op":"create","task":{"name":"Bijli ka bill bharo"}},{"op":"toggle","id":1},{"op":"update","id":5,"task":{"name":"Gaari dhulwao"}},{"op":"delete","id":9},{"op":"archive","id":1},{"op":"delete","id":2}]}`,
+			ExpectedCode: http.StatusOK,
+			ExpectedRspBody: `[{"status":200,"task":{"id":2,"name":"Bijli ka bill bharo","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}},` +
+				`{"status":200,"task":{"id":1,"name":"Doodh le aao","done":true,"status":"done","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z"}},` +
+				`{"status":201,"task":{"id":5,"name":"Gaari dhulwao","done":false,"status":"todo","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z"}},` +
+				`{"status":404,"error":"task not found"},` +
+				`{"status":400,"error":"batch operation must be one of create, update, toggle or delete"},` +
+				`{"status":204}]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("POST", "/checklist/v1/tasks:batch", strings.NewReader(tc.ReqBody))
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(tc.ExpectedCode, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+		})
+	}
+}
+
+func TestListTasksCombinesFilters(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = clock()
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(),
+			checklist.WithClock(func() time.Time { return now }))
+		handler = checklist.NewServer(svc, log.NewNopLogger())
+	)
+
+	dueOn := func(day int) *time.Time {
+		due := time.Date(2021, time.June, day, 9, 0, 0, 0, time.UTC)
+		return &due
+	}
+	bill, err := svc.Save(context.TODO(), todo.Task{Name: "Bijli ka bill bharo", DueDate: dueOn(1), Tags: []string{"ghar"}})
+	require.NoError(err, "could not save task")
+	wash, err := svc.Save(context.TODO(), todo.Task{Name: "Kapre dho", DueDate: dueOn(2), Tags: []string{"ghar"}})
+	require.NoError(err, "could not save task")
+	dry, err := svc.Save(context.TODO(), todo.Task{Name: "Kapre sukhao", DueDate: dueOn(3), Tags: []string{"ghar"}})
+	require.NoError(err, "could not save task")
+	_, err = svc.Save(context.TODO(), todo.Task{Name: "Chai peeyo"})
+	require.NoError(err, "could not save task")
+	require.NoError(svc.AddBlocker(context.TODO(), dry.ID, wash.ID), "could not add blocker")
+	require.NoError(svc.ToggleDone(context.TODO(), bill.ID, 0), "could not toggle task")
+	now = now.Add(8 * 24 * time.Hour)
+	_, err = svc.ArchiveCompleted(context.TODO())
+	require.NoError(err, "could not archive tasks")
+
+	var (
+		archived = `{"id":1,"name":"Bijli ka bill bharo","done":true,"status":"done","due_date":"2021-06-01T09:00:00Z","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","completed_at":"2021-06-01T12:00:00Z","archived_at":"2021-06-09T12:00:00Z","tags":["ghar"]}`
+		blocker  = `{"id":2,"name":"Kapre dho","done":false,"status":"todo","due_date":"2021-06-02T09:00:00Z","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","tags":["ghar"]}`
+		blocked  = `{"id":3,"name":"Kapre sukhao","done":false,"status":"todo","due_date":"2021-06-03T09:00:00Z","priority":"normal","created_at":"2021-06-01T12:00:00Z","updated_at":"2021-06-01T12:00:00Z","tags":["ghar"]}`
+	)
+	tt := []struct {
+		Name             string
+		Query            string
+		ExpectedRspBody  string
+		ExpectedNextPage bool
+	}{
+		{
+			Name:            "Leaves out archived tasks due in range by default",
+			Query:           "?due_before=2021-06-09T00:00:00Z",
+			ExpectedRspBody: `[` + blocker + `,` + blocked + `]`,
+		},
+		{
+			Name:            "Lists done tasks due in range when asked to include archived ones",
+			Query:           "?due_before=2021-06-09T00:00:00Z&done=true&archived=include",
+			ExpectedRspBody: `[` + archived + `]`,
+		},
+		{
+			Name:             "Lists overdue tasks a page at a time",
+			Query:            "?due=overdue&limit=1",
+			ExpectedRspBody:  `[` + blocker + `]`,
+			ExpectedNextPage: true,
+		},
+		{
+			Name:            "Lists no overdue tasks that are done",
+			Query:           "?due=overdue&done=true&archived=include",
+			ExpectedRspBody: `[]`,
+		},
+		{
+			Name:            "Lists only archived tasks carrying a tag",
+			Query:           "?tag=ghar&archived=only",
+			ExpectedRspBody: `[` + archived + `]`,
+		},
+		{
+			Name:            "Sorts tasks carrying a tag",
+			Query:           "?tag=ghar&status=todo&sort=name&order=desc",
+			ExpectedRspBody: `[` + blocked + `,` + blocker + `]`,
+		},
+		{
+			Name:            "Lists unblocked tasks carrying a tag",
+			Query:           "?blocked=false&tag=ghar",
+			ExpectedRspBody: `[` + blocker + `]`,
+		},
+		{
+			Name:            "Lists no blocked tasks that are done",
+			Query:           "?blocked=true&done=true",
+			ExpectedRspBody: `[]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/checklist/v1/tasks"+tc.Query, nil)
+			require.NoError(err, "could not create http request")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(http.StatusOK, rec.Result().StatusCode, "unexpected http status code")
+			assert.JSONEq(tc.ExpectedRspBody, rec.Body.String(), "unexpected http response body")
+			assert.Equal(tc.ExpectedNextPage, rec.Header().Get("Link") != "", "unexpected link header")
+		})
+	}
+}
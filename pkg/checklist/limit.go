@@ -0,0 +1,108 @@
+package checklist
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxBodySize is how many bytes the body of a request may have unless configured otherwise.
+const DefaultMaxBodySize = 1 << 20
+
+// DefaultMaxUploadSize is how many bytes the body of an attachment upload may have unless configured
+// otherwise, which leaves room for the multipart headers around an attachment of the default size.
+const DefaultMaxUploadSize = DefaultMaxAttachmentSize + 1<<20
+
+// ServerOption configures optional behaviour of the server returned by NewServer.
+type ServerOption func(*server)
+
+// WithMaxBodySize limits how many bytes the body of a request may have on routes
+// that aren't given a limit of their own with WithRouteMaxBodySize.
+func WithMaxBodySize(size int64) ServerOption {
+	return func(s *server) { s.maxBodySize = size }
+}
+
+// WithRouteMaxBodySize limits how many bytes the body of a request to the route may have,
+// e.g. WithRouteMaxBodySize("POST", "/checklist/v1/tasks", 64<<10). A size of zero lifts the limit.
+func WithRouteMaxBodySize(method, pattern string, size int64) ServerOption {
+	return func(s *server) { s.routeMaxBodySizes[method+" "+pattern] = size }
+}
+
+// maxBodySizeOf returns how many bytes the body of a request to the route may have.
+func (s *server) maxBodySizeOf(method, pattern string) int64 {
+	if size, ok := s.routeMaxBodySizes[method+" "+pattern]; ok {
+		return size
+	}
+	return s.maxBodySize
+}
+
+type bodyLimitContextKey struct{}
+
+// bodyLimitMiddleware fails requests with ErrRequestBodyTooLarge if their body has more than size bytes,
+// right away if their Content-Length says so or otherwise as soon as the handler reads past the limit,
+// so that the body can be streamed without a misbehaving client being able to send more than that.
+func bodyLimitMiddleware(size int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if size <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > size {
+				writeError(w, ErrRequestBodyTooLarge)
+				return
+			}
+			body := &limitedBody{ReadCloser: r.Body, n: size}
+			r.Body = body
+			// the body is kept in the context too, as other middleware may wrap it before it reaches the handler
+			r = r.WithContext(context.WithValue(r.Context(), bodyLimitContextKey{}, body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitedBody fails reads with ErrRequestBodyTooLarge once more than n bytes have been read.
+type limitedBody struct {
+	io.ReadCloser
+	n        int64 // how many more bytes may be read
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrRequestBodyTooLarge
+	}
+	if int64(len(p)) > b.n+1 {
+		p = p[:b.n+1] // reading one byte more than allowed tells whether there's more
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.n {
+		allowed := b.n
+		b.n, b.exceeded = 0, true
+		return int(allowed), ErrRequestBodyTooLarge
+	}
+	b.n -= int64(n)
+	return n, err
+}
+
+// requestBodyTooLarge tells whether reading the body of the request failed because it was larger than allowed.
+func requestBodyTooLarge(r *http.Request) bool {
+	body, ok := r.Context().Value(bodyLimitContextKey{}).(*limitedBody)
+	return ok && body.exceeded
+}
+
+// bodyError returns the error to respond with when the body of the request couldn't be read because of err.
+func bodyError(r *http.Request, err error) error {
+	if requestBodyTooLarge(r) {
+		return ErrRequestBodyTooLarge
+	}
+	return ErrInvalidRequestBody{err}
+}
+
+// decodeJSON decodes the JSON body of the request into v as it's read.
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return bodyError(r, err)
+	}
+	return nil
+}
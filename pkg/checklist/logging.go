@@ -2,6 +2,8 @@ package checklist
 
 import (
 	"context"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log"
@@ -24,6 +26,7 @@ func (s *loggingMiddleware) Save(ctx context.Context, task todo.Task) (_ *todo.T
 		s.logger.Log(
 			"method", "save",
 			"name", task.Name,
+			"assignee_id", task.AssigneeID,
 			"took", time.Since(begin),
 			"err", err,
 		)
@@ -31,6 +34,18 @@ func (s *loggingMiddleware) Save(ctx context.Context, task todo.Task) (_ *todo.T
 	return s.Service.Save(ctx, task)
 }
 
+func (s *loggingMiddleware) Get(ctx context.Context, id int64) (_ *todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "get",
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Get(ctx, id)
+}
+
 func (s *loggingMiddleware) List(ctx context.Context) (tasks []todo.Task, err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
@@ -42,28 +57,72 @@ func (s *loggingMiddleware) List(ctx context.Context) (tasks []todo.Task, err er
 	return s.Service.List(ctx)
 }
 
-func (s *loggingMiddleware) Remove(ctx context.Context, id int64) (err error) {
+func (s *loggingMiddleware) ListPage(ctx context.Context, query todo.TaskQuery) (tasks []todo.Task, next *todo.Cursor, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_page",
+			"sort", query.SortBy,
+			"desc", query.Descending,
+			"limit", query.Limit,
+			"tasks", len(tasks),
+			"has_next", next != nil,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListPage(ctx, query)
+}
+
+func (s *loggingMiddleware) Remove(ctx context.Context, id, version int64) (err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "remove",
 			"id", id,
+			"version", version,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.Remove(ctx, id)
+	return s.Service.Remove(ctx, id, version)
 }
 
-func (s *loggingMiddleware) ToggleDone(ctx context.Context, id int64) (err error) {
+func (s *loggingMiddleware) ToggleDone(ctx context.Context, id, version int64) (err error) {
 	defer func(begin time.Time) {
 		s.logger.Log(
 			"method", "toggle_done",
 			"id", id,
+			"version", version,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ToggleDone(ctx, id, version)
+}
+
+func (s *loggingMiddleware) Transition(ctx context.Context, id int64, status todo.Status) (_ *todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "transition",
+			"id", id,
+			"status", status,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Transition(ctx, id, status)
+}
+
+func (s *loggingMiddleware) ToggleDoneTree(ctx context.Context, id, version int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "toggle_done_tree",
+			"id", id,
+			"version", version,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
-	return s.Service.ToggleDone(ctx, id)
+	return s.Service.ToggleDoneTree(ctx, id, version)
 }
 
 func (s *loggingMiddleware) Update(ctx context.Context, task todo.Task) (_ *todo.Task, _ bool, err error) {
@@ -71,9 +130,755 @@ func (s *loggingMiddleware) Update(ctx context.Context, task todo.Task) (_ *todo
 		s.logger.Log(
 			"method", "update",
 			"name", task.Name,
+			"version", task.Version,
+			"assignee_id", task.AssigneeID,
 			"took", time.Since(begin),
 			"err", err,
 		)
 	}(time.Now())
 	return s.Service.Update(ctx, task)
 }
+
+func (s *loggingMiddleware) ListOverdue(ctx context.Context) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_overdue",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListOverdue(ctx)
+}
+
+func (s *loggingMiddleware) ListDueToday(ctx context.Context, loc *time.Location) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_due_today",
+			"location", loc,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListDueToday(ctx, loc)
+}
+
+func (s *loggingMiddleware) ListDueBetween(ctx context.Context, after, before time.Time) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_due_between",
+			"after", after,
+			"before", before,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListDueBetween(ctx, after, before)
+}
+
+func (s *loggingMiddleware) ListByTags(ctx context.Context, tags []string, matchAll bool) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_by_tags",
+			"tags", strings.Join(tags, ","),
+			"match_all", matchAll,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListByTags(ctx, tags, matchAll)
+}
+
+func (s *loggingMiddleware) ListSubtasks(ctx context.Context, id int64) (_ []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_subtasks",
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListSubtasks(ctx, id)
+}
+
+func (s *loggingMiddleware) Move(ctx context.Context, id, targetID int64, after bool) (_ *todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "move",
+			"id", id,
+			"target_id", targetID,
+			"after", after,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Move(ctx, id, targetID, after)
+}
+
+func (s *loggingMiddleware) ListTags(ctx context.Context) (tags []todo.Tag, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_tags",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListTags(ctx)
+}
+
+func (s *loggingMiddleware) CreateChecklist(ctx context.Context, checklist todo.Checklist) (_ *todo.Checklist, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "create_checklist",
+			"name", checklist.Name,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.CreateChecklist(ctx, checklist)
+}
+
+func (s *loggingMiddleware) ListChecklists(ctx context.Context) (_ []todo.Checklist, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_checklists",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListChecklists(ctx)
+}
+
+func (s *loggingMiddleware) GetChecklist(ctx context.Context, id int64) (_ *todo.Checklist, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "get_checklist",
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.GetChecklist(ctx, id)
+}
+
+func (s *loggingMiddleware) UpdateChecklist(ctx context.Context, checklist todo.Checklist) (_ *todo.Checklist, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "update_checklist",
+			"id", checklist.ID,
+			"name", checklist.Name,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.UpdateChecklist(ctx, checklist)
+}
+
+func (s *loggingMiddleware) RemoveChecklist(ctx context.Context, id int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_checklist",
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveChecklist(ctx, id)
+}
+
+func (s *loggingMiddleware) SaveToChecklist(ctx context.Context, checklistID int64, task todo.Task) (_ *todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "save_to_checklist",
+			"checklist_id", checklistID,
+			"name", task.Name,
+			"assignee_id", task.AssigneeID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.SaveToChecklist(ctx, checklistID, task)
+}
+
+func (s *loggingMiddleware) ListChecklistTasks(ctx context.Context, checklistID int64) (_ []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_checklist_tasks",
+			"checklist_id", checklistID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListChecklistTasks(ctx, checklistID)
+}
+
+func (s *loggingMiddleware) ToggleDoneInChecklist(ctx context.Context, checklistID, id, version int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "toggle_done_in_checklist",
+			"checklist_id", checklistID,
+			"id", id,
+			"version", version,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ToggleDoneInChecklist(ctx, checklistID, id, version)
+}
+
+func (s *loggingMiddleware) RemoveFromChecklist(ctx context.Context, checklistID, id, version int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_from_checklist",
+			"checklist_id", checklistID,
+			"id", id,
+			"version", version,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveFromChecklist(ctx, checklistID, id, version)
+}
+
+func (s *loggingMiddleware) UpdateInChecklist(ctx context.Context, checklistID int64, task todo.Task) (_ *todo.Task, _ bool, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "update_in_checklist",
+			"checklist_id", checklistID,
+			"name", task.Name,
+			"assignee_id", task.AssigneeID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.UpdateInChecklist(ctx, checklistID, task)
+}
+
+func (s *loggingMiddleware) ListHistory(ctx context.Context, id, afterID int64, limit int) (entries []todo.HistoryEntry, hasMore bool, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_history",
+			"id", id,
+			"after_id", afterID,
+			"limit", limit,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListHistory(ctx, id, afterID, limit)
+}
+
+func (s *loggingMiddleware) AddBlocker(ctx context.Context, taskID, blockerID int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "add_blocker",
+			"task_id", taskID,
+			"blocker_id", blockerID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.AddBlocker(ctx, taskID, blockerID)
+}
+
+func (s *loggingMiddleware) RemoveBlocker(ctx context.Context, taskID, blockerID int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_blocker",
+			"task_id", taskID,
+			"blocker_id", blockerID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveBlocker(ctx, taskID, blockerID)
+}
+
+func (s *loggingMiddleware) ListBlockers(ctx context.Context, taskID int64) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_blockers",
+			"task_id", taskID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListBlockers(ctx, taskID)
+}
+
+func (s *loggingMiddleware) ListBlocked(ctx context.Context) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_blocked",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListBlocked(ctx)
+}
+
+func (s *loggingMiddleware) ListUnblocked(ctx context.Context) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_unblocked",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListUnblocked(ctx)
+}
+
+func (s *loggingMiddleware) AddComment(ctx context.Context, taskID int64, comment todo.Comment) (_ *todo.Comment, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "add_comment",
+			"task_id", taskID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.AddComment(ctx, taskID, comment)
+}
+
+func (s *loggingMiddleware) ListComments(ctx context.Context, taskID int64) (comments []todo.Comment, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_comments",
+			"task_id", taskID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListComments(ctx, taskID)
+}
+
+func (s *loggingMiddleware) EditComment(ctx context.Context, taskID int64, comment todo.Comment) (_ *todo.Comment, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "edit_comment",
+			"task_id", taskID,
+			"id", comment.ID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.EditComment(ctx, taskID, comment)
+}
+
+func (s *loggingMiddleware) RemoveComment(ctx context.Context, taskID, id int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_comment",
+			"task_id", taskID,
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveComment(ctx, taskID, id)
+}
+
+func (s *loggingMiddleware) CreateUser(ctx context.Context, user todo.User) (_ *todo.User, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "create_user",
+			"name", user.Name,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.CreateUser(ctx, user)
+}
+
+func (s *loggingMiddleware) ListUsers(ctx context.Context) (users []todo.User, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_users",
+			"users", len(users),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListUsers(ctx)
+}
+
+func (s *loggingMiddleware) Assign(ctx context.Context, taskID, userID int64) (_ *todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "assign",
+			"task_id", taskID,
+			"assignee_id", userID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Assign(ctx, taskID, userID)
+}
+
+func (s *loggingMiddleware) Unassign(ctx context.Context, taskID int64) (_ *todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "unassign",
+			"task_id", taskID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Unassign(ctx, taskID)
+}
+
+func (s *loggingMiddleware) ListMyTasks(ctx context.Context, query todo.TaskQuery) (tasks []todo.Task, next *todo.Cursor, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_my_tasks",
+			"actor", todo.ActorFromContext(ctx),
+			"sort", query.SortBy,
+			"limit", query.Limit,
+			"tasks", len(tasks),
+			"has_next", next != nil,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListMyTasks(ctx, query)
+}
+
+func (s *loggingMiddleware) StartTimer(ctx context.Context, taskID int64) (_ *todo.TimeEntry, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "start_timer",
+			"task_id", taskID,
+			"actor", todo.ActorFromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.StartTimer(ctx, taskID)
+}
+
+func (s *loggingMiddleware) StopTimer(ctx context.Context, taskID int64) (_ *todo.TimeEntry, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "stop_timer",
+			"task_id", taskID,
+			"actor", todo.ActorFromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.StopTimer(ctx, taskID)
+}
+
+func (s *loggingMiddleware) AddTimeEntry(ctx context.Context, taskID int64, entry todo.TimeEntry) (_ *todo.TimeEntry, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "add_time_entry",
+			"task_id", taskID,
+			"actor", todo.ActorFromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.AddTimeEntry(ctx, taskID, entry)
+}
+
+func (s *loggingMiddleware) ListTimeEntries(ctx context.Context, taskID int64) (entries []todo.TimeEntry, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_time_entries",
+			"task_id", taskID,
+			"entries", len(entries),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListTimeEntries(ctx, taskID)
+}
+
+func (s *loggingMiddleware) RemoveTimeEntry(ctx context.Context, taskID, id int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_time_entry",
+			"task_id", taskID,
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveTimeEntry(ctx, taskID, id)
+}
+
+func (s *loggingMiddleware) SummarizeTime(ctx context.Context, taskID int64) (_ todo.TimeSummary, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "summarize_time",
+			"task_id", taskID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.SummarizeTime(ctx, taskID)
+}
+
+func (s *loggingMiddleware) SummarizeChecklistTime(ctx context.Context, checklistID int64) (_ todo.TimeSummary, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "summarize_checklist_time",
+			"checklist_id", checklistID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.SummarizeChecklistTime(ctx, checklistID)
+}
+
+func (s *loggingMiddleware) AddReminder(ctx context.Context, taskID int64, reminder todo.Reminder) (_ *todo.Reminder, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "add_reminder",
+			"task_id", taskID,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.AddReminder(ctx, taskID, reminder)
+}
+
+func (s *loggingMiddleware) ListReminders(ctx context.Context, taskID int64) (reminders []todo.Reminder, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_reminders",
+			"task_id", taskID,
+			"reminders", len(reminders),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListReminders(ctx, taskID)
+}
+
+func (s *loggingMiddleware) RemoveReminder(ctx context.Context, taskID, id int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_reminder",
+			"task_id", taskID,
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveReminder(ctx, taskID, id)
+}
+
+func (s *loggingMiddleware) SendDueReminders(ctx context.Context, notifier todo.Notifier) (sent int, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "send_due_reminders",
+			"sent", sent,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.SendDueReminders(ctx, notifier)
+}
+
+func (s *loggingMiddleware) AddAttachment(ctx context.Context, taskID int64, attachment todo.Attachment, content io.Reader) (added *todo.Attachment, err error) {
+	defer func(begin time.Time) {
+		var size int64
+		if added != nil {
+			size = added.Size
+		}
+		s.logger.Log(
+			"method", "add_attachment",
+			"task_id", taskID,
+			"name", attachment.Name,
+			"size", size,
+			"actor", todo.ActorFromContext(ctx),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.AddAttachment(ctx, taskID, attachment, content)
+}
+
+func (s *loggingMiddleware) ListAttachments(ctx context.Context, taskID int64) (attachments []todo.Attachment, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_attachments",
+			"task_id", taskID,
+			"attachments", len(attachments),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListAttachments(ctx, taskID)
+}
+
+func (s *loggingMiddleware) OpenAttachment(ctx context.Context, taskID, id int64) (_ *todo.Attachment, _ io.ReadCloser, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "open_attachment",
+			"task_id", taskID,
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.OpenAttachment(ctx, taskID, id)
+}
+
+func (s *loggingMiddleware) RemoveAttachment(ctx context.Context, taskID, id int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_attachment",
+			"task_id", taskID,
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveAttachment(ctx, taskID, id)
+}
+
+func (s *loggingMiddleware) ListTrash(ctx context.Context) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_trash",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListTrash(ctx)
+}
+
+func (s *loggingMiddleware) Restore(ctx context.Context, id int64) (_ *todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "restore",
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Restore(ctx, id)
+}
+
+func (s *loggingMiddleware) PurgeTrash(ctx context.Context) (purged int64, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "purge_trash",
+			"purged", purged,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.PurgeTrash(ctx)
+}
+
+func (s *loggingMiddleware) ArchiveCompleted(ctx context.Context) (archived int64, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "archive_completed",
+			"archived", archived,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ArchiveCompleted(ctx)
+}
+
+func (s *loggingMiddleware) CreateTemplate(ctx context.Context, template todo.Template) (_ *todo.Template, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "create_template",
+			"name", template.Name,
+			"tasks", len(template.Tasks),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.CreateTemplate(ctx, template)
+}
+
+func (s *loggingMiddleware) ListTemplates(ctx context.Context) (_ []todo.Template, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "list_templates",
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.ListTemplates(ctx)
+}
+
+func (s *loggingMiddleware) GetTemplate(ctx context.Context, id int64) (_ *todo.Template, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "get_template",
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.GetTemplate(ctx, id)
+}
+
+func (s *loggingMiddleware) UpdateTemplate(ctx context.Context, template todo.Template) (_ *todo.Template, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "update_template",
+			"id", template.ID,
+			"name", template.Name,
+			"tasks", len(template.Tasks),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.UpdateTemplate(ctx, template)
+}
+
+func (s *loggingMiddleware) RemoveTemplate(ctx context.Context, id int64) (err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "remove_template",
+			"id", id,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.RemoveTemplate(ctx, id)
+}
+
+func (s *loggingMiddleware) InstantiateTemplate(ctx context.Context, id, checklistID int64, start time.Time) (tasks []todo.Task, err error) {
+	defer func(begin time.Time) {
+		s.logger.Log(
+			"method", "instantiate_template",
+			"id", id,
+			"checklist_id", checklistID,
+			"start", start,
+			"created", len(tasks),
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.InstantiateTemplate(ctx, id, checklistID, start)
+}
+
+func (s *loggingMiddleware) Batch(ctx context.Context, ops []todo.BatchOperation, allOrNothing bool) (results []todo.BatchResult, err error) {
+	defer func(begin time.Time) {
+		var failed int
+		for _, result := range results {
+			if result.Err != nil {
+				failed++
+			}
+		}
+		s.logger.Log(
+			"method", "batch",
+			"ops", len(ops),
+			"all_or_nothing", allOrNothing,
+			"failed", failed,
+			"took", time.Since(begin),
+			"err", err,
+		)
+	}(time.Now())
+	return s.Service.Batch(ctx, ops, allOrNothing)
+}
@@ -0,0 +1,26 @@
+package checklist
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	// sanitizer strips whatever could run scripts or break out of the page the HTML
+	// is embedded in, while keeping the formatting Markdown can produce.
+	sanitizer = bluemonday.UGCPolicy()
+)
+
+// renderMarkdown converts Markdown to sanitized HTML that is safe to embed in a page.
+func renderMarkdown(src string) string {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(src), &buf); err != nil {
+		// goldmark only fails when writing the output, which a bytes.Buffer never does
+		return ""
+	}
+	return sanitizer.Sanitize(buf.String())
+}
@@ -0,0 +1,60 @@
+package checklist
+
+import (
+	"context"
+	"time"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+// ReminderScheduler periodically sends the reminders that have fallen due
+// through its notifier, see Service.SendDueReminders. Since delivery state is
+// kept by the service's repository, reminders that fell due while the
+// scheduler wasn't running are sent as soon as it starts again.
+type ReminderScheduler struct {
+	service  Service
+	notifier todo.Notifier
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewReminderScheduler returns a ReminderScheduler that runs every interval, which must be positive.
+// Errors aren't returned but left to be logged by the service's middleware.
+func NewReminderScheduler(service Service, notifier todo.Notifier, interval time.Duration) *ReminderScheduler {
+	return &ReminderScheduler{
+		service:  service,
+		notifier: notifier,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start sends due reminders right away and then once every interval until Stop is called.
+func (rs *ReminderScheduler) Start() {
+	go rs.run()
+}
+
+// Stop stops the scheduler, waiting for a run that is in progress to finish.
+// It must be called at most once, and only after Start.
+func (rs *ReminderScheduler) Stop() {
+	close(rs.stop)
+	<-rs.done
+}
+
+func (rs *ReminderScheduler) run() {
+	defer close(rs.done)
+
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+	for {
+		rs.service.SendDueReminders(context.Background(), rs.notifier)
+
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
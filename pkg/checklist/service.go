@@ -1,8 +1,17 @@
 package checklist
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/jarri-abidi/todo/pkg/todo"
 )
@@ -10,39 +19,427 @@ import (
 // Service is an application service that lets us interact with a list of tasks.
 type Service interface {
 	Save(context.Context, todo.Task) (*todo.Task, error)
+	Get(ctx context.Context, id int64) (*todo.Task, error)
 	List(context.Context) ([]todo.Task, error)
-	ToggleDone(ctx context.Context, id int64) error
-	Remove(ctx context.Context, id int64) error
+	// ListPage returns the tasks matching the query along with the cursor
+	// of the next page, which is nil if this is the last one.
+	ListPage(context.Context, todo.TaskQuery) (_ []todo.Task, next *todo.Cursor, err error)
+	// ToggleDone marks a task that isn't done as done, or a done task as todo again, regardless
+	// of the workflow. A task can't be done while it depends on pending tasks, in which case
+	// ErrTaskBlocked is returned. Unless version is zero, it must match the stored one or
+	// ErrVersionConflict is returned.
+	ToggleDone(ctx context.Context, id, version int64) error
+	// Transition moves the task to the status if the workflow allows it to, returning
+	// ErrIllegalTransition otherwise. Like ToggleDone, it fails with ErrTaskBlocked
+	// if the task would be done while it depends on pending tasks.
+	Transition(ctx context.Context, id int64, status todo.Status) (*todo.Task, error)
+	// ToggleDoneTree toggles the task and, if it became done, also completes all of its subtasks.
+	// Like ToggleDone, it checks the version of the task unless it's zero. Subtasks are completed
+	// as Transition would complete them: it fails with ErrTaskBlocked if any of them is blocked,
+	// and leaves those the workflow doesn't let be done, such as won't do ones, as they are.
+	ToggleDoneTree(ctx context.Context, id, version int64) error
+	// Remove moves the task along with all of its subtasks to the trash.
+	// Their comments are archived with them until they are restored or purged.
+	// Unless version is zero, it must match the stored one or ErrVersionConflict is returned.
+	Remove(ctx context.Context, id, version int64) error
+	// Update replaces the task, or creates it if it doesn't exist. If the task has
+	// a Version, it must match the stored one or ErrVersionConflict is returned.
 	Update(context.Context, todo.Task) (task *todo.Task, isCreated bool, err error)
+	ListOverdue(context.Context) ([]todo.Task, error)
+	ListDueToday(ctx context.Context, loc *time.Location) ([]todo.Task, error)
+	ListDueBetween(ctx context.Context, after, before time.Time) ([]todo.Task, error)
+	ListByTags(ctx context.Context, tags []string, matchAll bool) ([]todo.Task, error)
+	ListTags(context.Context) ([]todo.Tag, error)
+	ListSubtasks(ctx context.Context, id int64) ([]todo.Task, error)
+	// Move places the task right before the target task, or right after it if after is set,
+	// in the order the tasks are listed by position. New tasks are placed after all others.
+	// The target must belong to the same checklist and have the same parent as the task,
+	// or ErrInvalidMoveTarget is returned.
+	Move(ctx context.Context, id, targetID int64, after bool) (*todo.Task, error)
+
+	// ListHistory returns the changes made to the task after the entry with the given ID,
+	// oldest first, and reports whether there are more than limit of them.
+	ListHistory(ctx context.Context, id, afterID int64, limit int) (_ []todo.HistoryEntry, hasMore bool, err error)
+
+	// AddBlocker makes the task depend on the blocker, so that it can't be done before
+	// the blocker is. ErrDependencyCycle is returned if the blocker depends on the task.
+	AddBlocker(ctx context.Context, taskID, blockerID int64) error
+	RemoveBlocker(ctx context.Context, taskID, blockerID int64) error
+	// ListBlockers returns the tasks that the task depends on.
+	ListBlockers(ctx context.Context, taskID int64) ([]todo.Task, error)
+	// ListBlocked returns the pending tasks that depend on at least one pending task.
+	ListBlocked(context.Context) ([]todo.Task, error)
+	// ListUnblocked returns the pending tasks that don't depend on any pending task.
+	ListUnblocked(context.Context) ([]todo.Task, error)
+
+	// AddComment adds a comment to the task on behalf of the actor of ctx.
+	AddComment(ctx context.Context, taskID int64, comment todo.Comment) (*todo.Comment, error)
+	// ListComments returns the comments on the task, oldest first.
+	ListComments(ctx context.Context, taskID int64) ([]todo.Comment, error)
+	// EditComment replaces the body of one of the task's comments.
+	EditComment(ctx context.Context, taskID int64, comment todo.Comment) (*todo.Comment, error)
+	RemoveComment(ctx context.Context, taskID, id int64) error
+
+	// StartTimer starts tracking the time the actor of ctx spends on the task.
+	// It fails with ErrTimerRunning if the actor already has a timer running.
+	StartTimer(ctx context.Context, taskID int64) (*todo.TimeEntry, error)
+	// StopTimer stops the timer the actor of ctx has running on the task.
+	StopTimer(ctx context.Context, taskID int64) (*todo.TimeEntry, error)
+	// AddTimeEntry records time the actor of ctx spent on the task without running a timer.
+	AddTimeEntry(ctx context.Context, taskID int64, entry todo.TimeEntry) (*todo.TimeEntry, error)
+	// ListTimeEntries returns the time entries of the task, earliest first.
+	ListTimeEntries(ctx context.Context, taskID int64) ([]todo.TimeEntry, error)
+	RemoveTimeEntry(ctx context.Context, taskID, id int64) error
+	// SummarizeTime compares the estimate of the task with the time spent on it so far.
+	SummarizeTime(ctx context.Context, taskID int64) (todo.TimeSummary, error)
+	// SummarizeChecklistTime compares the estimates of the checklist's tasks with the time spent on them.
+	SummarizeChecklistTime(ctx context.Context, checklistID int64) (todo.TimeSummary, error)
+
+	// AddReminder sets a reminder on the task, either at a fixed time or for some time
+	// before the task is due, in which case the task must have a due date.
+	AddReminder(ctx context.Context, taskID int64, reminder todo.Reminder) (*todo.Reminder, error)
+	// ListReminders returns the reminders of the task, sent or not, in the order they were added.
+	ListReminders(ctx context.Context, taskID int64) ([]todo.Reminder, error)
+	RemoveReminder(ctx context.Context, taskID, id int64) error
+	// SendDueReminders notifies about the pending reminders that are due and returns how many
+	// were sent. Reminders of tasks that are done or trashed are held back. A reminder that
+	// can't be delivered stays pending and is tried again on the next call.
+	SendDueReminders(ctx context.Context, notifier todo.Notifier) (sent int, err error)
+
+	// AddAttachment stores the content as a file attached to the task. Its type is told from
+	// the content, which must be of one of the allowed types and no larger than allowed.
+	AddAttachment(ctx context.Context, taskID int64, attachment todo.Attachment, content io.Reader) (*todo.Attachment, error)
+	// ListAttachments returns the attachments of the task in the order they were added.
+	ListAttachments(ctx context.Context, taskID int64) ([]todo.Attachment, error)
+	// OpenAttachment returns one of the task's attachments along with its content, which the caller must close.
+	OpenAttachment(ctx context.Context, taskID, id int64) (*todo.Attachment, io.ReadCloser, error)
+	RemoveAttachment(ctx context.Context, taskID, id int64) error
+
+	CreateUser(context.Context, todo.User) (*todo.User, error)
+	ListUsers(context.Context) ([]todo.User, error)
+	// Assign makes the user the assignee of the task, replacing any previous one.
+	Assign(ctx context.Context, taskID, userID int64) (*todo.Task, error)
+	Unassign(ctx context.Context, taskID int64) (*todo.Task, error)
+	// ListMyTasks is ListPage restricted to the tasks assigned to the actor of ctx,
+	// who must be a known user.
+	ListMyTasks(context.Context, todo.TaskQuery) (_ []todo.Task, next *todo.Cursor, err error)
+
+	ListTrash(context.Context) ([]todo.Task, error)
+	// Restore takes the task out of the trash along with the subtasks that were trashed with it.
+	Restore(ctx context.Context, id int64) (*todo.Task, error)
+	// PurgeTrash permanently deletes the tasks that have been in the trash
+	// for longer than the retention period and returns how many there were.
+	PurgeTrash(context.Context) (purged int64, err error)
+	// ArchiveCompleted archives the tasks that have been done for longer than the
+	// archive period and returns how many there were. Archived tasks are left out
+	// of listings that ask for unarchived tasks only, until they are reopened.
+	ArchiveCompleted(context.Context) (archived int64, err error)
+
+	CreateChecklist(context.Context, todo.Checklist) (*todo.Checklist, error)
+	ListChecklists(context.Context) ([]todo.Checklist, error)
+	GetChecklist(ctx context.Context, id int64) (*todo.Checklist, error)
+	UpdateChecklist(context.Context, todo.Checklist) (*todo.Checklist, error)
+	RemoveChecklist(ctx context.Context, id int64) error
+
+	SaveToChecklist(ctx context.Context, checklistID int64, task todo.Task) (*todo.Task, error)
+	ListChecklistTasks(ctx context.Context, checklistID int64) ([]todo.Task, error)
+	ToggleDoneInChecklist(ctx context.Context, checklistID, id, version int64) error
+	RemoveFromChecklist(ctx context.Context, checklistID, id, version int64) error
+	UpdateInChecklist(ctx context.Context, checklistID int64, task todo.Task) (_ *todo.Task, isCreated bool, err error)
+
+	CreateTemplate(context.Context, todo.Template) (*todo.Template, error)
+	ListTemplates(context.Context) ([]todo.Template, error)
+	GetTemplate(ctx context.Context, id int64) (*todo.Template, error)
+	UpdateTemplate(context.Context, todo.Template) (*todo.Template, error)
+	RemoveTemplate(ctx context.Context, id int64) error
+	// InstantiateTemplate creates the tasks of the template in the checklist, or outside of any
+	// checklist if checklistID is zero, due relative to start or to now if start is zero.
+	// Either all of the tasks are created or, if any fails, none is.
+	InstantiateTemplate(ctx context.Context, id, checklistID int64, start time.Time) ([]todo.Task, error)
+
+	// Batch makes the changes of the operations in order and returns the result of each.
+	// If allOrNothing is set, they're made in one unit of work which stops at the first
+	// operation that fails, so that either all of them succeed or none is kept. Otherwise
+	// each operation succeeds or fails on its own. Failed operations are reported in their
+	// result rather than as the error, which is only returned if the batch couldn't be run,
+	// such as when it's all or nothing but the service has no unit of work.
+	Batch(ctx context.Context, ops []todo.BatchOperation, allOrNothing bool) ([]todo.BatchResult, error)
 }
 
 // Middleware describes a Service middleware.
 type Middleware func(Service) Service
 
+// DefaultMaxSubtaskDepth is how deeply subtasks may be nested unless configured otherwise.
+const DefaultMaxSubtaskDepth = 5
+
+// DefaultTrashRetention is how long tasks stay in the trash before they may be purged.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// DefaultArchiveAfter is how long tasks stay done before they may be archived.
+const DefaultArchiveAfter = 7 * 24 * time.Hour
+
+// DefaultMaxAttachmentSize is how many bytes an attachment may have unless configured otherwise.
+const DefaultMaxAttachmentSize = 10 << 20
+
+// DefaultAttachmentTypes are the media types attachments may have unless configured otherwise,
+// which are those of common screenshots, logs and documents.
+var DefaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"text/plain", "application/pdf", "application/zip", "application/x-gzip",
+}
+
+// Option configures optional behaviour of the Service.
+type Option func(*service)
+
+// WithMaxSubtaskDepth limits how many levels of subtasks a task may have above it.
+func WithMaxSubtaskDepth(depth int) Option {
+	return func(s *service) { s.maxSubtaskDepth = depth }
+}
+
+// WithTrashRetention sets how long tasks stay in the trash before PurgeTrash deletes them.
+func WithTrashRetention(retention time.Duration) Option {
+	return func(s *service) { s.trashRetention = retention }
+}
+
+// WithArchiveAfter sets how long tasks stay done before ArchiveCompleted archives them.
+// A period of zero disables archiving.
+func WithArchiveAfter(period time.Duration) Option {
+	return func(s *service) { s.archiveAfter = period }
+}
+
+// WithMaxAttachmentSize limits how many bytes an attachment may have.
+func WithMaxAttachmentSize(size int64) Option {
+	return func(s *service) { s.maxAttachmentSize = size }
+}
+
+// WithAttachmentTypes sets the media types attachments may have, such as image/png.
+func WithAttachmentTypes(types ...string) Option {
+	return func(s *service) { s.attachmentTypes = types }
+}
+
+// WithUnitOfWork sets the unit of work the service makes changes that must be atomic in.
+// It must be over the same storage as the repositories the service is created with.
+// Unless it's configured, such changes are made directly and aren't atomic, and
+// batches can't be run all or nothing.
+func WithUnitOfWork(unitOfWork todo.UnitOfWork) Option {
+	return func(s *service) { s.unitOfWork = unitOfWork }
+}
+
+// WithClock sets where the service reads the current time from when it timestamps
+// tasks and their history, which is time.Now unless configured otherwise.
+func WithClock(now func() time.Time) Option {
+	return func(s *service) { s.now = now }
+}
+
+// WithWorkflow sets which status changes Transition allows, which are those
+// of todo.DefaultWorkflow unless configured otherwise.
+func WithWorkflow(workflow todo.Workflow) Option {
+	return func(s *service) { s.workflow = workflow }
+}
+
 type service struct {
-	repository todo.TaskRepository
+	repository        todo.TaskRepository
+	checklists        todo.ChecklistRepository
+	history           todo.HistoryRepository
+	comments          todo.CommentRepository
+	dependencies      todo.DependencyRepository
+	users             todo.UserRepository
+	timeEntries       todo.TimeEntryRepository
+	templates         todo.TemplateRepository
+	reminders         todo.ReminderRepository
+	attachments       todo.AttachmentRepository
+	blobs             todo.BlobStore
+	maxSubtaskDepth   int
+	trashRetention    time.Duration
+	archiveAfter      time.Duration
+	maxAttachmentSize int64
+	attachmentTypes   []string
+	workflow          todo.Workflow
+	now               func() time.Time
+	unitOfWork        todo.UnitOfWork
+	inUnitOfWork      bool // whether the repositories are those of a unit of work in progress
+}
+
+func NewService(repositories todo.Repositories, blobs todo.BlobStore, opts ...Option) Service {
+	s := &service{
+		repository:        repositories.Tasks,
+		checklists:        repositories.Checklists,
+		history:           repositories.History,
+		comments:          repositories.Comments,
+		dependencies:      repositories.Dependencies,
+		users:             repositories.Users,
+		timeEntries:       repositories.TimeEntries,
+		templates:         repositories.Templates,
+		reminders:         repositories.Reminders,
+		attachments:       repositories.Attachments,
+		blobs:             blobs,
+		maxSubtaskDepth:   DefaultMaxSubtaskDepth,
+		trashRetention:    DefaultTrashRetention,
+		archiveAfter:      DefaultArchiveAfter,
+		maxAttachmentSize: DefaultMaxAttachmentSize,
+		attachmentTypes:   DefaultAttachmentTypes,
+		workflow:          todo.DefaultWorkflow,
+		now:               time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// atomically calls fn with a copy of the service whose repositories are those of a unit of work,
+// so that either all of the changes fn makes are kept or, if it returns an error, none is.
+// If the service is part of a unit of work already, or has none configured, fn is simply called
+// with the service.
+func (s *service) atomically(ctx context.Context, fn func(*service) error) error {
+	if s.inUnitOfWork || s.unitOfWork == nil {
+		return fn(s)
+	}
+	return s.unitOfWork.Do(ctx, func(r todo.Repositories) error {
+		tx := *s
+		tx.repository, tx.checklists, tx.history, tx.comments, tx.dependencies = r.Tasks, r.Checklists, r.History, r.Comments, r.Dependencies
+		tx.users, tx.timeEntries, tx.templates, tx.reminders, tx.attachments = r.Users, r.TimeEntries, r.Templates, r.Reminders, r.Attachments
+		tx.inUnitOfWork = true
+		return fn(&tx)
+	})
 }
 
-func NewService(repository todo.TaskRepository) Service {
-	return &service{repository: repository}
+func (s *service) Save(ctx context.Context, task todo.Task) (saved *todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		saved, err = s.save(ctx, task)
+		return err
+	})
+	return saved, err
 }
 
-func (s *service) Save(ctx context.Context, task todo.Task) (*todo.Task, error) {
+func (s *service) save(ctx context.Context, task todo.Task) (*todo.Task, error) {
+	if err := validate(&task); err != nil {
+		return nil, err
+	}
+	if err := s.checkChecklistExists(ctx, task.ChecklistID); err != nil {
+		return nil, err
+	}
+	if err := s.checkParent(ctx, task); err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignee(ctx, task.AssigneeID); err != nil {
+		return nil, err
+	}
+	if err := s.settleStatus(&task, nil); err != nil {
+		return nil, err
+	}
+	stamp(&task, nil, s.now().UTC())
+	if err := s.rank(ctx, &task, nil); err != nil {
+		return nil, err
+	}
 	if err := s.repository.Insert(ctx, &task); err != nil {
 		return nil, fmt.Errorf("could not save task: %v", err)
 	}
+	if err := s.record(ctx, todo.OperationSave, nil, &task); err != nil {
+		return nil, err
+	}
 	return &task, nil
 }
 
+func (s *service) Get(ctx context.Context, id int64) (*todo.Task, error) {
+	task, err := s.repository.FindByID(ctx, id)
+	if err == todo.ErrTaskNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find task: %v", err)
+	}
+	return task, nil
+}
+
 func (s *service) List(ctx context.Context) ([]todo.Task, error) {
-	list, err := s.repository.FindAll(ctx)
+	list, err := s.repository.FindAll(ctx, todo.TaskQuery{})
 	if err != nil {
 		return nil, fmt.Errorf("could not list task: %v", err)
 	}
 	return list, nil
 }
 
-func (s *service) ToggleDone(ctx context.Context, id int64) error {
+func (s *service) ListPage(ctx context.Context, query todo.TaskQuery) ([]todo.Task, *todo.Cursor, error) {
+	if _, err := todo.ParseSortField(string(query.SortBy)); err != nil {
+		return nil, nil, err
+	}
+	query, ok, err := s.resolve(ctx, query)
+	if err != nil || !ok {
+		return nil, nil, err
+	}
+
+	// fetch one task more than asked for to find out whether there is a next page
+	limit := query.Limit
+	if limit > 0 {
+		query.Limit++
+	}
+	list, err := s.repository.FindAll(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not list tasks: %v", err)
+	}
+	if limit <= 0 || len(list) <= limit {
+		return list, nil, nil
+	}
+
+	list = list[:limit]
+	next := todo.CursorAfter(list[limit-1])
+	return list, &next, nil
+}
+
+// resolve turns the filters of the query that a repository disregards into ones it
+// understands, and normalizes its tags. It reports false if no task can match the query.
+func (s *service) resolve(ctx context.Context, query todo.TaskQuery) (todo.TaskQuery, bool, error) {
+	if len(query.Tags) > 0 {
+		tags, err := todo.NormalizeTags(query.Tags)
+		if err != nil {
+			return todo.TaskQuery{}, false, err
+		}
+		query.Tags = tags
+	}
+
+	// overdue and blocked tasks are pending by definition
+	if query.Overdue || query.Blocked != nil {
+		if query.Done != nil && *query.Done {
+			return todo.TaskQuery{}, false, nil
+		}
+		pending := false
+		query.Done = &pending
+	}
+
+	now := s.now()
+	if query.Overdue {
+		query.Due = query.Due.Intersect(todo.TimeRange{Before: now})
+	}
+	if query.DueToday != nil {
+		now := now.In(query.DueToday)
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, query.DueToday)
+		query.Due = query.Due.Intersect(todo.TimeRange{After: startOfDay, Before: startOfDay.AddDate(0, 0, 1)})
+	}
+
+	if query.Blocked != nil {
+		blocked, err := s.blockedIDs(ctx)
+		if err != nil {
+			return todo.TaskQuery{}, false, err
+		}
+		if *query.Blocked {
+			query.IDs = blocked
+		} else {
+			query.ExcludeIDs = append(query.ExcludeIDs, blocked...)
+		}
+	}
+
+	query.Overdue, query.DueToday, query.Blocked = false, nil, nil
+	return query, true, nil
+}
+
+func (s *service) ToggleDone(ctx context.Context, id, version int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.toggleDone(ctx, id, version)
+	})
+}
+
+func (s *service) toggleDone(ctx context.Context, id, version int64) error {
 	task, err := s.repository.FindByID(ctx, id)
 	if err == todo.ErrTaskNotFound {
 		return err
@@ -50,36 +447,1881 @@ func (s *service) ToggleDone(ctx context.Context, id int64) error {
 	if err != nil {
 		return fmt.Errorf("could not find task: %v", err)
 	}
+	if version != 0 {
+		// the repository fails the update unless the task is still at this version
+		task.Version = version
+	}
+	return s.changeStatus(ctx, task, todo.StatusOf(!task.Done), todo.OperationToggleDone)
+}
+
+func (s *service) Transition(ctx context.Context, id int64, status todo.Status) (task *todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		task, err = s.transition(ctx, id, status)
+		return err
+	})
+	return task, err
+}
+
+func (s *service) transition(ctx context.Context, id int64, status todo.Status) (*todo.Task, error) {
+	if !status.IsValid() {
+		return nil, todo.ErrInvalidStatus
+	}
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.workflow.Allows(task.Status, status) {
+		return nil, todo.ErrIllegalTransition
+	}
+	if task.Status == status {
+		return task, nil
+	}
+
+	if err := s.changeStatus(ctx, task, status, todo.OperationTransition); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// changeStatus moves the task to the status, scheduling its next occurrence if it
+// recurs and has just been done. It doesn't consult the workflow.
+func (s *service) changeStatus(ctx context.Context, task *todo.Task, status todo.Status, op todo.Operation) error {
+	if status == todo.StatusDone && !task.Done {
+		if err := s.checkBlockers(ctx, task.ID); err != nil {
+			return err
+		}
+	}
+
+	now := s.now().UTC()
+	before := *task
+	task.Status, task.Done = status, status == todo.StatusDone
+	stamp(task, &before, now)
+	next, recurs := task.NextOccurrence(s.now())
+	if task.Done && recurs {
+		// the recurrence carries on with the next occurrence, so toggling
+		// this task again must not spawn another one
+		task.Recurrence = nil
+	}
+	err := s.repository.Update(ctx, task)
+	if err == todo.ErrVersionConflict {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not change task status: %v", err)
+	}
+	if err := s.record(ctx, op, &before, task); err != nil {
+		return err
+	}
 
-	task.Done = !task.Done
-	if err = s.repository.Update(ctx, task); err != nil {
-		return fmt.Errorf("could not toggle task: %v", err)
+	if task.Done && recurs {
+		stamp(&next, nil, now)
+		if err := s.rank(ctx, &next, nil); err != nil {
+			return err
+		}
+		if err := s.repository.Insert(ctx, &next); err != nil {
+			return fmt.Errorf("could not schedule next occurrence: %v", err)
+		}
+		return s.record(ctx, todo.OperationSave, nil, &next)
 	}
 	return nil
 }
 
-func (s *service) Remove(ctx context.Context, id int64) error {
-	err := s.repository.DeleteByID(ctx, id)
-	if err == todo.ErrTaskNotFound {
+func (s *service) ToggleDoneTree(ctx context.Context, id, version int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.toggleDoneTree(ctx, id, version)
+	})
+}
+
+func (s *service) toggleDoneTree(ctx context.Context, id, version int64) error {
+	if err := s.ToggleDone(ctx, id, version); err != nil {
 		return err
 	}
+
+	task, err := s.repository.FindByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("could not delete task: %v", err)
+		return fmt.Errorf("could not find task: %v", err)
+	}
+	if !task.Done {
+		return nil
+	}
+	return s.completeSubtasks(ctx, id)
+}
+
+// completeSubtasks marks the pending subtasks of the task as done, deepest ones first, the way
+// Transition would, so that blockers, the workflow and recurrences are all respected.
+func (s *service) completeSubtasks(ctx context.Context, parentID int64) error {
+	subtasks, err := s.repository.FindAllByParentID(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("could not find subtasks: %v", err)
+	}
+
+	for i := range subtasks {
+		subtask := &subtasks[i]
+		if err := s.completeSubtasks(ctx, subtask.ID); err != nil {
+			return err
+		}
+		if subtask.Done || !s.workflow.Allows(subtask.Status, todo.StatusDone) {
+			continue
+		}
+		if err := s.changeStatus(ctx, subtask, todo.StatusDone, todo.OperationToggleDone); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *service) Update(ctx context.Context, task todo.Task) (*todo.Task, bool, error) {
-	err := s.repository.Update(ctx, &task)
+func (s *service) Remove(ctx context.Context, id, version int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.remove(ctx, id, version)
+	})
+}
+
+func (s *service) remove(ctx context.Context, id, version int64) error {
+	before, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.DeleteByID(ctx, id, version, s.now().UTC())
+	if err == todo.ErrTaskNotFound || err == todo.ErrVersionConflict {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete task: %v", err)
+	}
+	return s.record(ctx, todo.OperationRemove, before, nil)
+}
+
+func (s *service) Update(ctx context.Context, task todo.Task) (updated *todo.Task, created bool, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		updated, created, err = s.update(ctx, task)
+		return err
+	})
+	return updated, created, err
+}
+
+func (s *service) update(ctx context.Context, task todo.Task) (*todo.Task, bool, error) {
+	if err := validate(&task); err != nil {
+		return nil, false, err
+	}
+	if err := s.checkChecklistExists(ctx, task.ChecklistID); err != nil {
+		return nil, false, err
+	}
+	if err := s.checkParent(ctx, task); err != nil {
+		return nil, false, err
+	}
+	if err := s.checkAssignee(ctx, task.AssigneeID); err != nil {
+		return nil, false, err
+	}
+
+	before, err := s.repository.FindByID(ctx, task.ID)
+	if err != nil && err != todo.ErrTaskNotFound {
+		return nil, false, fmt.Errorf("could not find task: %v", err)
+	}
+	if err := s.settleStatus(&task, before); err != nil {
+		return nil, false, err
+	}
+	if task.Done && before != nil && !before.Done {
+		if err := s.checkBlockers(ctx, task.ID); err != nil {
+			return nil, false, err
+		}
+	}
+	stamp(&task, before, s.now().UTC())
+	if err := s.rank(ctx, &task, before); err != nil {
+		return nil, false, err
+	}
+
+	err = s.repository.Update(ctx, &task)
+	if err == todo.ErrVersionConflict || err == todo.ErrTaskNotFound && task.Version != 0 {
+		return nil, false, err
+	}
 	if err == todo.ErrTaskNotFound {
 		err = s.repository.Insert(ctx, &task)
+		if err == todo.ErrTaskAlreadyExists {
+			// the id belongs to a task in the trash
+			return nil, false, err
+		}
 		if err != nil {
 			return nil, false, fmt.Errorf("could not create task: %v", err)
 		}
+		if err := s.record(ctx, todo.OperationSave, nil, &task); err != nil {
+			return nil, false, err
+		}
 		return &task, true, nil
 	}
 	if err != nil {
 		return nil, false, fmt.Errorf("could not update task: %v", err)
 	}
+	if err := s.record(ctx, todo.OperationUpdate, before, &task); err != nil {
+		return nil, false, err
+	}
 	return &task, false, nil
 }
+
+func (s *service) Move(ctx context.Context, id, targetID int64, after bool) (task *todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		task, err = s.move(ctx, id, targetID, after)
+		return err
+	})
+	return task, err
+}
+
+func (s *service) move(ctx context.Context, id, targetID int64, after bool) (*todo.Task, error) {
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.Get(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if task.ID == target.ID {
+		return task, nil
+	}
+	if task.ChecklistID != target.ChecklistID || task.ParentID != target.ParentID {
+		return nil, todo.ErrInvalidMoveTarget
+	}
+
+	// find the task on the other side of the target among its siblings, skipping any
+	// that share its rank so that there is always room for a rank between the two
+	edge := todo.Cursor{Rank: target.Rank}
+	if after {
+		edge.ID = math.MaxInt64
+	}
+	neighbours, err := s.repository.FindAll(ctx, todo.TaskQuery{
+		ChecklistID: &target.ChecklistID,
+		ParentID:    &target.ParentID,
+		SortBy:      todo.SortByPosition,
+		Descending:  !after,
+		Limit:       2, // one of them may be the task itself
+		After:       &edge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not find neighbouring task: %v", err)
+	}
+	var neighbour string
+	for _, t := range neighbours {
+		if t.ID != task.ID {
+			neighbour = t.Rank
+			break
+		}
+	}
+
+	before := *task
+	if after && neighbour == "" {
+		task.Rank = todo.RankAfter(target.Rank)
+	} else if after {
+		task.Rank = todo.RankBetween(target.Rank, neighbour)
+	} else {
+		task.Rank = todo.RankBetween(neighbour, target.Rank)
+	}
+	stamp(task, &before, s.now().UTC())
+	err = s.repository.Update(ctx, task)
+	if err == todo.ErrVersionConflict || err == todo.ErrTaskNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not move task: %v", err)
+	}
+	if err := s.record(ctx, todo.OperationMove, &before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *service) ListOverdue(ctx context.Context) ([]todo.Task, error) {
+	now := s.now()
+	list, err := s.repository.FindAllDueBetween(ctx, time.Time{}, now)
+	if err != nil {
+		return nil, fmt.Errorf("could not list overdue tasks: %v", err)
+	}
+
+	overdue := make([]todo.Task, 0, len(list))
+	for _, task := range list {
+		if task.IsOverdue(now) {
+			overdue = append(overdue, task)
+		}
+	}
+	return overdue, nil
+}
+
+func (s *service) ListDueToday(ctx context.Context, loc *time.Location) ([]todo.Task, error) {
+	now := s.now().In(loc)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return s.ListDueBetween(ctx, startOfDay, startOfDay.AddDate(0, 0, 1))
+}
+
+func (s *service) ListDueBetween(ctx context.Context, after, before time.Time) ([]todo.Task, error) {
+	list, err := s.repository.FindAllDueBetween(ctx, after, before)
+	if err != nil {
+		return nil, fmt.Errorf("could not list tasks by due date: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) ListByTags(ctx context.Context, tags []string, matchAll bool) ([]todo.Task, error) {
+	tags, err := todo.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.repository.FindAllByTags(ctx, tags, matchAll)
+	if err != nil {
+		return nil, fmt.Errorf("could not list tasks by tags: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) ListSubtasks(ctx context.Context, id int64) ([]todo.Task, error) {
+	if _, err := s.repository.FindByID(ctx, id); err != nil {
+		if err == todo.ErrTaskNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not find task: %v", err)
+	}
+
+	list, err := s.repository.FindAllByParentID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not list subtasks: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) ListHistory(ctx context.Context, id, afterID int64, limit int) ([]todo.HistoryEntry, bool, error) {
+	// fetch one entry more than asked for to find out whether there are more
+	fetch := limit
+	if limit > 0 {
+		fetch++
+	}
+	entries, err := s.history.FindAllByTaskID(ctx, id, afterID, fetch)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not list history: %v", err)
+	}
+
+	if len(entries) == 0 && afterID == 0 {
+		// the history of removed tasks remains available, so only
+		// tasks without any history need to be checked for existence
+		if _, err := s.Get(ctx, id); err != nil {
+			return nil, false, err
+		}
+	}
+	if limit <= 0 || len(entries) <= limit {
+		return entries, false, nil
+	}
+	return entries[:limit], true, nil
+}
+
+func (s *service) AddBlocker(ctx context.Context, taskID, blockerID int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.addBlocker(ctx, taskID, blockerID)
+	})
+}
+
+func (s *service) addBlocker(ctx context.Context, taskID, blockerID int64) error {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return err
+	}
+	if _, err := s.Get(ctx, blockerID); err != nil {
+		return err
+	}
+
+	dep := todo.Dependency{TaskID: taskID, BlockerID: blockerID}
+	deps, err := s.dependencies.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list dependencies: %v", err)
+	}
+	if todo.CreatesCycle(deps, dep) {
+		return todo.ErrDependencyCycle
+	}
+
+	if err := s.dependencies.Insert(ctx, dep); err != nil {
+		return fmt.Errorf("could not add dependency: %v", err)
+	}
+	return nil
+}
+
+func (s *service) RemoveBlocker(ctx context.Context, taskID, blockerID int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.removeBlocker(ctx, taskID, blockerID)
+	})
+}
+
+func (s *service) removeBlocker(ctx context.Context, taskID, blockerID int64) error {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return err
+	}
+
+	err := s.dependencies.Delete(ctx, todo.Dependency{TaskID: taskID, BlockerID: blockerID})
+	if err == todo.ErrDependencyNotFound {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not remove dependency: %v", err)
+	}
+	return nil
+}
+
+func (s *service) ListBlockers(ctx context.Context, taskID int64) ([]todo.Task, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.blockers(ctx, taskID)
+}
+
+func (s *service) ListBlocked(ctx context.Context) ([]todo.Task, error) {
+	return s.listByBlocked(ctx, true)
+}
+
+func (s *service) ListUnblocked(ctx context.Context) ([]todo.Task, error) {
+	return s.listByBlocked(ctx, false)
+}
+
+// listByBlocked returns the pending tasks that are blocked by other pending tasks, or those that aren't.
+func (s *service) listByBlocked(ctx context.Context, blocked bool) ([]todo.Task, error) {
+	list, _, err := s.ListPage(ctx, todo.TaskQuery{Blocked: &blocked})
+	return list, err
+}
+
+// blockedIDs returns the IDs of the pending tasks that depend on other pending tasks.
+func (s *service) blockedIDs(ctx context.Context) ([]int64, error) {
+	pending := false
+	tasks, err := s.repository.FindAll(ctx, todo.TaskQuery{Done: &pending})
+	if err != nil {
+		return nil, fmt.Errorf("could not list tasks: %v", err)
+	}
+	deps, err := s.dependencies.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list dependencies: %v", err)
+	}
+
+	open := make(map[int64]bool, len(tasks))
+	for _, task := range tasks {
+		open[task.ID] = true
+	}
+	blocked := make(map[int64]bool)
+	ids := []int64{}
+	for _, dep := range deps {
+		if open[dep.TaskID] && open[dep.BlockerID] && !blocked[dep.TaskID] {
+			blocked[dep.TaskID] = true
+			ids = append(ids, dep.TaskID)
+		}
+	}
+	return ids, nil
+}
+
+// blockers returns the tasks that the task depends on, leaving out those in the trash.
+func (s *service) blockers(ctx context.Context, taskID int64) ([]todo.Task, error) {
+	deps, err := s.dependencies.FindAllByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list dependencies: %v", err)
+	}
+
+	list := make([]todo.Task, 0, len(deps))
+	for _, dep := range deps {
+		blocker, err := s.repository.FindByID(ctx, dep.BlockerID)
+		if err == todo.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not find blocker: %v", err)
+		}
+		list = append(list, *blocker)
+	}
+	return list, nil
+}
+
+// checkBlockers returns ErrTaskBlocked if the task depends on any pending task.
+func (s *service) checkBlockers(ctx context.Context, taskID int64) error {
+	blockers, err := s.blockers(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	for _, blocker := range blockers {
+		if !blocker.Done {
+			return todo.ErrTaskBlocked
+		}
+	}
+	return nil
+}
+
+func (s *service) AddComment(ctx context.Context, taskID int64, comment todo.Comment) (added *todo.Comment, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		added, err = s.addComment(ctx, taskID, comment)
+		return err
+	})
+	return added, err
+}
+
+func (s *service) addComment(ctx context.Context, taskID int64, comment todo.Comment) (*todo.Comment, error) {
+	if comment.Body == "" {
+		return nil, todo.ErrEmptyComment
+	}
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	comment.TaskID = taskID
+	comment.Author = todo.ActorFromContext(ctx)
+	comment.CreatedAt, comment.UpdatedAt = now, now
+	if err := s.comments.Insert(ctx, &comment); err != nil {
+		return nil, fmt.Errorf("could not add comment: %v", err)
+	}
+	return &comment, nil
+}
+
+func (s *service) ListComments(ctx context.Context, taskID int64) ([]todo.Comment, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.comments.FindAllByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list comments: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) EditComment(ctx context.Context, taskID int64, comment todo.Comment) (edited *todo.Comment, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		edited, err = s.editComment(ctx, taskID, comment)
+		return err
+	})
+	return edited, err
+}
+
+func (s *service) editComment(ctx context.Context, taskID int64, comment todo.Comment) (*todo.Comment, error) {
+	if comment.Body == "" {
+		return nil, todo.ErrEmptyComment
+	}
+	if _, err := s.findComment(ctx, taskID, comment.ID); err != nil {
+		return nil, err
+	}
+
+	comment.UpdatedAt = s.now().UTC()
+	err := s.comments.Update(ctx, &comment)
+	if err == todo.ErrCommentNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not edit comment: %v", err)
+	}
+	return &comment, nil
+}
+
+func (s *service) RemoveComment(ctx context.Context, taskID, id int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.removeComment(ctx, taskID, id)
+	})
+}
+
+func (s *service) removeComment(ctx context.Context, taskID, id int64) error {
+	if _, err := s.findComment(ctx, taskID, id); err != nil {
+		return err
+	}
+
+	err := s.comments.DeleteByID(ctx, id)
+	if err == todo.ErrCommentNotFound {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not remove comment: %v", err)
+	}
+	return nil
+}
+
+// findComment returns the comment with the given id, or ErrCommentNotFound if it isn't
+// on the task. Comments on tasks in the trash are archived and can't be found either.
+func (s *service) findComment(ctx context.Context, taskID, id int64) (*todo.Comment, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.comments.FindByID(ctx, id)
+	if err == todo.ErrCommentNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find comment: %v", err)
+	}
+	if comment.TaskID != taskID {
+		return nil, todo.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+func (s *service) CreateUser(ctx context.Context, user todo.User) (*todo.User, error) {
+	name, err := todo.NormalizeUserName(user.Name)
+	if err != nil {
+		return nil, err
+	}
+	user.Name = name
+
+	err = s.users.Insert(ctx, &user)
+	if err == todo.ErrUserAlreadyExists {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create user: %v", err)
+	}
+	return &user, nil
+}
+
+func (s *service) ListUsers(ctx context.Context) ([]todo.User, error) {
+	list, err := s.users.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list users: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) Assign(ctx context.Context, taskID, userID int64) (task *todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		task, err = s.assign(ctx, taskID, userID)
+		return err
+	})
+	return task, err
+}
+
+func (s *service) assign(ctx context.Context, taskID, userID int64) (*todo.Task, error) {
+	if userID == 0 {
+		return nil, todo.ErrAssigneeNotFound
+	}
+	if err := s.checkAssignee(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.setAssignee(ctx, taskID, userID, todo.OperationAssign)
+}
+
+func (s *service) Unassign(ctx context.Context, taskID int64) (task *todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		task, err = s.setAssignee(ctx, taskID, 0, todo.OperationUnassign)
+		return err
+	})
+	return task, err
+}
+
+func (s *service) setAssignee(ctx context.Context, taskID, userID int64, op todo.Operation) (*todo.Task, error) {
+	task, err := s.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.AssigneeID == userID {
+		return task, nil
+	}
+
+	before := *task
+	task.AssigneeID = userID
+	stamp(task, &before, s.now().UTC())
+	err = s.repository.Update(ctx, task)
+	if err == todo.ErrVersionConflict || err == todo.ErrTaskNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not assign task: %v", err)
+	}
+	if err := s.record(ctx, op, &before, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *service) ListMyTasks(ctx context.Context, query todo.TaskQuery) ([]todo.Task, *todo.Cursor, error) {
+	user, err := s.users.FindByName(ctx, todo.ActorFromContext(ctx))
+	if err == todo.ErrUserNotFound {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find user: %v", err)
+	}
+
+	query.AssigneeID = user.ID
+	return s.ListPage(ctx, query)
+}
+
+func (s *service) StartTimer(ctx context.Context, taskID int64) (entry *todo.TimeEntry, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		entry, err = s.startTimer(ctx, taskID)
+		return err
+	})
+	return entry, err
+}
+
+func (s *service) startTimer(ctx context.Context, taskID int64) (*todo.TimeEntry, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	entry := todo.TimeEntry{TaskID: taskID, User: todo.ActorFromContext(ctx), Start: s.now().UTC()}
+	err := s.timeEntries.Insert(ctx, &entry)
+	if err == todo.ErrTimerRunning {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not start timer: %v", err)
+	}
+	return &entry, nil
+}
+
+func (s *service) StopTimer(ctx context.Context, taskID int64) (entry *todo.TimeEntry, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		entry, err = s.stopTimer(ctx, taskID)
+		return err
+	})
+	return entry, err
+}
+
+func (s *service) stopTimer(ctx context.Context, taskID int64) (*todo.TimeEntry, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.timeEntries.FindRunning(ctx, todo.ActorFromContext(ctx))
+	if err == todo.ErrTimeEntryNotFound || err == nil && entry.TaskID != taskID {
+		return nil, todo.ErrNoTimerRunning
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find timer: %v", err)
+	}
+
+	end := s.now().UTC()
+	entry.End = &end
+	if err := s.timeEntries.Update(ctx, entry); err != nil {
+		return nil, fmt.Errorf("could not stop timer: %v", err)
+	}
+	return entry, nil
+}
+
+func (s *service) AddTimeEntry(ctx context.Context, taskID int64, entry todo.TimeEntry) (added *todo.TimeEntry, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		added, err = s.addTimeEntry(ctx, taskID, entry)
+		return err
+	})
+	return added, err
+}
+
+func (s *service) addTimeEntry(ctx context.Context, taskID int64, entry todo.TimeEntry) (*todo.TimeEntry, error) {
+	if entry.End == nil || !entry.End.After(entry.Start) {
+		return nil, todo.ErrInvalidTimeEntry
+	}
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	end := entry.End.UTC()
+	entry.TaskID, entry.User, entry.Start, entry.End = taskID, todo.ActorFromContext(ctx), entry.Start.UTC(), &end
+	if err := s.timeEntries.Insert(ctx, &entry); err != nil {
+		return nil, fmt.Errorf("could not add time entry: %v", err)
+	}
+	return &entry, nil
+}
+
+func (s *service) ListTimeEntries(ctx context.Context, taskID int64) ([]todo.TimeEntry, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.timeEntries.FindAllByTaskIDs(ctx, []int64{taskID})
+	if err != nil {
+		return nil, fmt.Errorf("could not list time entries: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) RemoveTimeEntry(ctx context.Context, taskID, id int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.removeTimeEntry(ctx, taskID, id)
+	})
+}
+
+func (s *service) removeTimeEntry(ctx context.Context, taskID, id int64) error {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return err
+	}
+
+	entry, err := s.timeEntries.FindByID(ctx, id)
+	if err == todo.ErrTimeEntryNotFound || err == nil && entry.TaskID != taskID {
+		return todo.ErrTimeEntryNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not find time entry: %v", err)
+	}
+
+	err = s.timeEntries.DeleteByID(ctx, id)
+	if err == todo.ErrTimeEntryNotFound {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete time entry: %v", err)
+	}
+	return nil
+}
+
+func (s *service) SummarizeTime(ctx context.Context, taskID int64) (todo.TimeSummary, error) {
+	task, err := s.Get(ctx, taskID)
+	if err != nil {
+		return todo.TimeSummary{}, err
+	}
+	return s.summarize(ctx, []todo.Task{*task})
+}
+
+func (s *service) SummarizeChecklistTime(ctx context.Context, checklistID int64) (todo.TimeSummary, error) {
+	tasks, err := s.ListChecklistTasks(ctx, checklistID)
+	if err != nil {
+		return todo.TimeSummary{}, err
+	}
+	return s.summarize(ctx, tasks)
+}
+
+func (s *service) summarize(ctx context.Context, tasks []todo.Task) (todo.TimeSummary, error) {
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	entries, err := s.timeEntries.FindAllByTaskIDs(ctx, ids)
+	if err != nil {
+		return todo.TimeSummary{}, fmt.Errorf("could not find time entries: %v", err)
+	}
+	return todo.Summarize(tasks, entries, s.now().UTC()), nil
+}
+
+func (s *service) AddReminder(ctx context.Context, taskID int64, reminder todo.Reminder) (added *todo.Reminder, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		added, err = s.addReminder(ctx, taskID, reminder)
+		return err
+	})
+	return added, err
+}
+
+func (s *service) addReminder(ctx context.Context, taskID int64, reminder todo.Reminder) (*todo.Reminder, error) {
+	if (reminder.At == nil) == (reminder.Before == nil) || reminder.Before != nil && *reminder.Before < 0 {
+		return nil, todo.ErrInvalidReminder
+	}
+	task, err := s.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if reminder.Before != nil && task.DueDate == nil {
+		return nil, todo.ErrTaskNotDue
+	}
+
+	if reminder.At != nil {
+		at := reminder.At.UTC()
+		reminder.At = &at
+	}
+	reminder.TaskID, reminder.SentAt, reminder.Attempts, reminder.LastError = taskID, nil, 0, ""
+	if err := s.reminders.Insert(ctx, &reminder); err != nil {
+		return nil, fmt.Errorf("could not add reminder: %v", err)
+	}
+	return &reminder, nil
+}
+
+func (s *service) ListReminders(ctx context.Context, taskID int64) ([]todo.Reminder, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.reminders.FindAllByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list reminders: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) RemoveReminder(ctx context.Context, taskID, id int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.removeReminder(ctx, taskID, id)
+	})
+}
+
+func (s *service) removeReminder(ctx context.Context, taskID, id int64) error {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return err
+	}
+
+	reminder, err := s.reminders.FindByID(ctx, id)
+	if err == todo.ErrReminderNotFound || err == nil && reminder.TaskID != taskID {
+		return todo.ErrReminderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not find reminder: %v", err)
+	}
+
+	err = s.reminders.DeleteByID(ctx, id)
+	if err == todo.ErrReminderNotFound {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete reminder: %v", err)
+	}
+	return nil
+}
+
+func (s *service) SendDueReminders(ctx context.Context, notifier todo.Notifier) (int, error) {
+	pending, err := s.reminders.FindAllPending(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not list pending reminders: %v", err)
+	}
+
+	now := s.now().UTC()
+	tasks := make(map[int64]*todo.Task)
+	var sent, failed int
+	var lastErr error
+	for _, reminder := range pending {
+		task, ok := tasks[reminder.TaskID]
+		if !ok {
+			task, err = s.repository.FindByID(ctx, reminder.TaskID)
+			if err != nil && err != todo.ErrTaskNotFound {
+				return sent, fmt.Errorf("could not find task: %v", err)
+			}
+			tasks[reminder.TaskID] = task // nil if the task is trashed
+		}
+		if task == nil || task.Done {
+			continue
+		}
+		if at, ok := reminder.FireAt(*task); !ok || at.After(now) {
+			continue
+		}
+
+		// the reminder is only marked as sent once it has been delivered, so
+		// it's delivered again if that can't be recorded, but never lost. Deliveries
+		// aren't made in a unit of work, which would be held open while waiting on them.
+		reminder.Attempts++
+		if err := notifier.Notify(ctx, todo.Notification{Reminder: reminder, Task: *task}); err != nil {
+			failed, lastErr = failed+1, err
+			reminder.LastError = err.Error()
+		} else {
+			sent++
+			reminder.SentAt, reminder.LastError = &now, ""
+		}
+		if err := s.reminders.Update(ctx, &reminder); err != nil {
+			return sent, fmt.Errorf("could not update reminder: %v", err)
+		}
+	}
+	if failed > 0 {
+		return sent, fmt.Errorf("could not send %d of %d due reminders: %v", failed, sent+failed, lastErr)
+	}
+	return sent, nil
+}
+
+func (s *service) AddAttachment(ctx context.Context, taskID int64, attachment todo.Attachment, content io.Reader) (*todo.Attachment, error) {
+	// only the base of the name is kept, whichever the separators of the uploader's system
+	name := strings.TrimSpace(path.Base(strings.Replace(attachment.Name, `\`, "/", -1)))
+	if name == "" || name == "." || name == "/" {
+		return nil, todo.ErrInvalidAttachmentName
+	}
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	// the type is told from the content rather than taken on trust from the uploader
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("could not read attachment: %v", err)
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if !s.allowsAttachmentType(contentType) {
+		return nil, todo.ErrUnsupportedContentType
+	}
+
+	key, err := newBlobKey(taskID)
+	if err != nil {
+		return nil, err
+	}
+	limited := &limitedReader{r: io.MultiReader(bytes.NewReader(head), content), n: s.maxAttachmentSize}
+	size, err := s.blobs.Put(ctx, key, limited, contentType)
+	if limited.exceeded {
+		s.blobs.Delete(ctx, key) // in case the store kept what it read before the limit was hit
+		return nil, todo.ErrAttachmentTooLarge
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not store attachment: %v", err)
+	}
+
+	attachment = todo.Attachment{
+		TaskID:      taskID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Key:         key,
+		UploadedBy:  todo.ActorFromContext(ctx),
+		CreatedAt:   s.now().UTC(),
+	}
+	err = s.atomically(ctx, func(s *service) error {
+		// the task may have been removed while the content was being stored
+		if _, err := s.Get(ctx, taskID); err != nil {
+			return err
+		}
+		if err := s.attachments.Insert(ctx, &attachment); err != nil {
+			return fmt.Errorf("could not add attachment: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.blobs.Delete(ctx, key)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (s *service) ListAttachments(ctx context.Context, taskID int64) ([]todo.Attachment, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.attachments.FindAllByTaskIDs(ctx, []int64{taskID})
+	if err != nil {
+		return nil, fmt.Errorf("could not list attachments: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) OpenAttachment(ctx context.Context, taskID, id int64) (*todo.Attachment, io.ReadCloser, error) {
+	attachment, err := s.findAttachment(ctx, taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.blobs.Get(ctx, attachment.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open attachment: %v", err)
+	}
+	return attachment, content, nil
+}
+
+func (s *service) RemoveAttachment(ctx context.Context, taskID, id int64) error {
+	var key string
+	err := s.atomically(ctx, func(s *service) (err error) {
+		key, err = s.removeAttachment(ctx, taskID, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return s.deleteBlobs(ctx, []string{key})
+}
+
+// removeAttachment deletes the attachment and returns the key of its content, which is
+// left to be deleted once the attachment's deletion is committed.
+func (s *service) removeAttachment(ctx context.Context, taskID, id int64) (string, error) {
+	attachment, err := s.findAttachment(ctx, taskID, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.attachments.DeleteByID(ctx, id)
+	if err == todo.ErrAttachmentNotFound {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not remove attachment: %v", err)
+	}
+	return attachment.Key, nil
+}
+
+func (s *service) findAttachment(ctx context.Context, taskID, id int64) (*todo.Attachment, error) {
+	if _, err := s.Get(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachments.FindByID(ctx, id)
+	if err == todo.ErrAttachmentNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find attachment: %v", err)
+	}
+	if attachment.TaskID != taskID {
+		return nil, todo.ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+// allowsAttachmentType tells whether attachments may have the content type, regardless of its parameters.
+func (s *service) allowsAttachmentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range s.attachmentTypes {
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// newBlobKey returns a key to store an attachment of the task under that no other attachment has.
+func newBlobKey(taskID int64) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate blob key: %v", err)
+	}
+	return fmt.Sprintf("tasks/%d/%x", taskID, b), nil
+}
+
+// limitedReader reads from r until more than n bytes have been read,
+// from when on it fails with ErrAttachmentTooLarge.
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, todo.ErrAttachmentTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1] // reading one byte more than allowed tells whether there's more
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		l.exceeded = true
+		return n, todo.ErrAttachmentTooLarge
+	}
+	return n, err
+}
+
+func (s *service) ListTrash(ctx context.Context) ([]todo.Task, error) {
+	list, err := s.repository.FindAllDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list trash: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) Restore(ctx context.Context, id int64) (restored *todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		restored, err = s.restore(ctx, id)
+		return err
+	})
+	return restored, err
+}
+
+func (s *service) restore(ctx context.Context, id int64) (*todo.Task, error) {
+	err := s.repository.RestoreByID(ctx, id)
+	if err == todo.ErrTaskNotFound || err == todo.ErrParentTaskTrashed {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not restore task: %v", err)
+	}
+
+	restored, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(ctx, todo.OperationRestore, nil, restored); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func (s *service) PurgeTrash(ctx context.Context) (int64, error) {
+	var (
+		purged int64
+		keys   []string
+	)
+	err := s.atomically(ctx, func(s *service) (err error) {
+		purged, keys, err = s.purgeTrash(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, s.deleteBlobs(ctx, keys)
+}
+
+// purgeTrash deletes the tasks that have been in the trash for longer than the retention,
+// and returns the keys of the content of their attachments, which is left to be deleted
+// once the deletion of the tasks is committed.
+func (s *service) purgeTrash(ctx context.Context) (int64, []string, error) {
+	before := s.now().Add(-s.trashRetention)
+
+	trash, err := s.repository.FindAllDeleted(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not list trash: %v", err)
+	}
+	var expired []int64
+	for _, task := range trash {
+		if task.DeletedAt.Before(before) {
+			expired = append(expired, task.ID)
+		}
+	}
+	keys, err := s.deleteRelations(ctx, expired)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	purged, err := s.repository.PurgeDeletedBefore(ctx, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not purge trash: %v", err)
+	}
+	return purged, keys, nil
+}
+
+func (s *service) ArchiveCompleted(ctx context.Context) (int64, error) {
+	if s.archiveAfter <= 0 {
+		return 0, nil
+	}
+
+	now := s.now().UTC()
+	archived, err := s.repository.ArchiveCompletedBefore(ctx, now.Add(-s.archiveAfter), now)
+	if err != nil {
+		return 0, fmt.Errorf("could not archive completed tasks: %v", err)
+	}
+	return archived, nil
+}
+
+func (s *service) ListTags(ctx context.Context) ([]todo.Tag, error) {
+	tags, err := s.repository.FindAllTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list tags: %v", err)
+	}
+	return tags, nil
+}
+
+func (s *service) CreateChecklist(ctx context.Context, checklist todo.Checklist) (*todo.Checklist, error) {
+	if err := s.checklists.Insert(ctx, &checklist); err != nil {
+		return nil, fmt.Errorf("could not create checklist: %v", err)
+	}
+	return &checklist, nil
+}
+
+func (s *service) ListChecklists(ctx context.Context) ([]todo.Checklist, error) {
+	list, err := s.checklists.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list checklists: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) GetChecklist(ctx context.Context, id int64) (*todo.Checklist, error) {
+	checklist, err := s.checklists.FindByID(ctx, id)
+	if err == todo.ErrChecklistNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find checklist: %v", err)
+	}
+	return checklist, nil
+}
+
+func (s *service) UpdateChecklist(ctx context.Context, checklist todo.Checklist) (*todo.Checklist, error) {
+	err := s.checklists.Update(ctx, &checklist)
+	if err == todo.ErrChecklistNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not update checklist: %v", err)
+	}
+	return &checklist, nil
+}
+
+func (s *service) RemoveChecklist(ctx context.Context, id int64) error {
+	var keys []string
+	err := s.atomically(ctx, func(s *service) (err error) {
+		keys, err = s.removeChecklist(ctx, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return s.deleteBlobs(ctx, keys)
+}
+
+// removeChecklist deletes the checklist with its tasks, and returns the keys of the content of
+// their attachments, which is left to be deleted once the deletion of the tasks is committed.
+func (s *service) removeChecklist(ctx context.Context, id int64) ([]string, error) {
+	if _, err := s.GetChecklist(ctx, id); err != nil {
+		return nil, err
+	}
+	ids, err := s.checklistTaskIDs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.deleteRelations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repository.DeleteAllByChecklistID(ctx, id); err != nil {
+		return nil, fmt.Errorf("could not delete checklist tasks: %v", err)
+	}
+	if err := s.checklists.DeleteByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("could not delete checklist: %v", err)
+	}
+	return keys, nil
+}
+
+// checklistTaskIDs returns the IDs of the tasks of the checklist, including those in the trash.
+func (s *service) checklistTaskIDs(ctx context.Context, checklistID int64) ([]int64, error) {
+	tasks, err := s.repository.FindAllByChecklistID(ctx, checklistID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list checklist tasks: %v", err)
+	}
+	trash, err := s.repository.FindAllDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list trash: %v", err)
+	}
+
+	var ids []int64
+	for _, task := range append(tasks, trash...) {
+		if task.ChecklistID == checklistID {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids, nil
+}
+
+// deleteRelations deletes the comments, dependencies, time entries, reminders
+// and attachments of tasks that are about to be deleted for good. It returns the
+// keys of the content of the attachments, as blobs can't be part of a unit of work.
+func (s *service) deleteRelations(ctx context.Context, taskIDs []int64) ([]string, error) {
+	if err := s.comments.DeleteAllByTaskIDs(ctx, taskIDs); err != nil {
+		return nil, fmt.Errorf("could not delete comments: %v", err)
+	}
+	if err := s.dependencies.DeleteAllByTaskIDs(ctx, taskIDs); err != nil {
+		return nil, fmt.Errorf("could not delete dependencies: %v", err)
+	}
+	if err := s.timeEntries.DeleteAllByTaskIDs(ctx, taskIDs); err != nil {
+		return nil, fmt.Errorf("could not delete time entries: %v", err)
+	}
+	if err := s.reminders.DeleteAllByTaskIDs(ctx, taskIDs); err != nil {
+		return nil, fmt.Errorf("could not delete reminders: %v", err)
+	}
+
+	attachments, err := s.attachments.FindAllByTaskIDs(ctx, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not find attachments: %v", err)
+	}
+	if err := s.attachments.DeleteAllByTaskIDs(ctx, taskIDs); err != nil {
+		return nil, fmt.Errorf("could not delete attachments: %v", err)
+	}
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.Key)
+	}
+	return keys, nil
+}
+
+// deleteBlobs deletes the content of attachments whose deletion has been committed.
+func (s *service) deleteBlobs(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			return fmt.Errorf("could not delete attachment content: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *service) SaveToChecklist(ctx context.Context, checklistID int64, task todo.Task) (saved *todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		saved, err = s.saveToChecklist(ctx, checklistID, task)
+		return err
+	})
+	return saved, err
+}
+
+func (s *service) saveToChecklist(ctx context.Context, checklistID int64, task todo.Task) (*todo.Task, error) {
+	if _, err := s.GetChecklist(ctx, checklistID); err != nil {
+		return nil, err
+	}
+	task.ChecklistID = checklistID
+	return s.Save(ctx, task)
+}
+
+func (s *service) ListChecklistTasks(ctx context.Context, checklistID int64) ([]todo.Task, error) {
+	if _, err := s.GetChecklist(ctx, checklistID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.repository.FindAllByChecklistID(ctx, checklistID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list checklist tasks: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) ToggleDoneInChecklist(ctx context.Context, checklistID, id, version int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.toggleDoneInChecklist(ctx, checklistID, id, version)
+	})
+}
+
+func (s *service) toggleDoneInChecklist(ctx context.Context, checklistID, id, version int64) error {
+	if _, err := s.findInChecklist(ctx, checklistID, id); err != nil {
+		return err
+	}
+	return s.ToggleDone(ctx, id, version)
+}
+
+func (s *service) RemoveFromChecklist(ctx context.Context, checklistID, id, version int64) error {
+	return s.atomically(ctx, func(s *service) error {
+		return s.removeFromChecklist(ctx, checklistID, id, version)
+	})
+}
+
+func (s *service) removeFromChecklist(ctx context.Context, checklistID, id, version int64) error {
+	if _, err := s.findInChecklist(ctx, checklistID, id); err != nil {
+		return err
+	}
+	return s.Remove(ctx, id, version)
+}
+
+func (s *service) UpdateInChecklist(ctx context.Context, checklistID int64, task todo.Task) (updated *todo.Task, created bool, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		updated, created, err = s.updateInChecklist(ctx, checklistID, task)
+		return err
+	})
+	return updated, created, err
+}
+
+func (s *service) updateInChecklist(ctx context.Context, checklistID int64, task todo.Task) (*todo.Task, bool, error) {
+	_, err := s.findInChecklist(ctx, checklistID, task.ID)
+	if err != nil && err != todo.ErrTaskNotFound {
+		return nil, false, err
+	}
+	if err == todo.ErrTaskNotFound {
+		// the task may still exist in another checklist, which this one must not overwrite
+		if _, err := s.repository.FindByID(ctx, task.ID); err == nil {
+			return nil, false, todo.ErrTaskAlreadyExists
+		}
+	}
+
+	task.ChecklistID = checklistID
+	return s.Update(ctx, task)
+}
+
+// findInChecklist returns the task with the given id if it belongs to the checklist.
+func (s *service) findInChecklist(ctx context.Context, checklistID, id int64) (*todo.Task, error) {
+	if _, err := s.GetChecklist(ctx, checklistID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.repository.FindByID(ctx, id)
+	if err == todo.ErrTaskNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find task: %v", err)
+	}
+	if task.ChecklistID != checklistID {
+		return nil, todo.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func (s *service) CreateTemplate(ctx context.Context, template todo.Template) (*todo.Template, error) {
+	if err := validateTemplate(&template); err != nil {
+		return nil, err
+	}
+	if err := s.templates.Insert(ctx, &template); err != nil {
+		return nil, fmt.Errorf("could not create template: %v", err)
+	}
+	return &template, nil
+}
+
+func (s *service) ListTemplates(ctx context.Context) ([]todo.Template, error) {
+	list, err := s.templates.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list templates: %v", err)
+	}
+	return list, nil
+}
+
+func (s *service) GetTemplate(ctx context.Context, id int64) (*todo.Template, error) {
+	template, err := s.templates.FindByID(ctx, id)
+	if err == todo.ErrTemplateNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not find template: %v", err)
+	}
+	return template, nil
+}
+
+func (s *service) UpdateTemplate(ctx context.Context, template todo.Template) (*todo.Template, error) {
+	if err := validateTemplate(&template); err != nil {
+		return nil, err
+	}
+	err := s.templates.Update(ctx, &template)
+	if err == todo.ErrTemplateNotFound {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not update template: %v", err)
+	}
+	return &template, nil
+}
+
+func (s *service) RemoveTemplate(ctx context.Context, id int64) error {
+	err := s.templates.DeleteByID(ctx, id)
+	if err == todo.ErrTemplateNotFound {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("could not delete template: %v", err)
+	}
+	return nil
+}
+
+func (s *service) InstantiateTemplate(ctx context.Context, id, checklistID int64, start time.Time) (tasks []todo.Task, err error) {
+	err = s.atomically(ctx, func(s *service) error {
+		tasks, err = s.instantiateTemplate(ctx, id, checklistID, start)
+		return err
+	})
+	return tasks, err
+}
+
+func (s *service) instantiateTemplate(ctx context.Context, id, checklistID int64, start time.Time) ([]todo.Task, error) {
+	template, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkChecklistExists(ctx, checklistID); err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	if start.IsZero() {
+		start = now
+	}
+	tasks := template.Instantiate(start)
+	for i := range tasks {
+		task := &tasks[i]
+		task.ChecklistID = checklistID
+		if err := validate(task); err != nil {
+			return nil, err
+		}
+		if err := s.settleStatus(task, nil); err != nil {
+			return nil, err
+		}
+		stamp(task, nil, now)
+
+		// the tasks are placed after all others in the order the template lists them
+		if i > 0 {
+			task.Rank = todo.RankAfter(tasks[i-1].Rank)
+		} else if err := s.rank(ctx, task, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repository.InsertAll(ctx, tasks); err != nil {
+		return nil, fmt.Errorf("could not save template tasks: %v", err)
+	}
+	for i := range tasks {
+		if err := s.record(ctx, todo.OperationSave, nil, &tasks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+func (s *service) Batch(ctx context.Context, ops []todo.BatchOperation, allOrNothing bool) ([]todo.BatchResult, error) {
+	if len(ops) == 0 || len(ops) > todo.MaxBatchSize {
+		return nil, todo.ErrInvalidBatchSize
+	}
+
+	results := make([]todo.BatchResult, len(ops))
+	if !allOrNothing {
+		for i, op := range ops {
+			err := s.atomically(ctx, func(s *service) error {
+				results[i] = s.apply(ctx, op)
+				return results[i].Err
+			})
+			if err != nil {
+				// also covers the unit of work failing after the operation succeeded
+				results[i] = todo.BatchResult{Err: err}
+			}
+		}
+		return results, nil
+	}
+	if s.unitOfWork == nil {
+		return nil, todo.ErrBatchNotAtomic
+	}
+
+	failed := -1
+	err := s.atomically(ctx, func(s *service) error {
+		for i, op := range ops {
+			results[i] = s.apply(ctx, op)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if err != nil && failed < 0 {
+		return nil, fmt.Errorf("could not run batch: %v", err)
+	}
+	if err != nil {
+		for i := range results {
+			if i < failed {
+				results[i] = todo.BatchResult{Err: todo.ErrBatchRolledBack}
+			} else if i > failed {
+				results[i] = todo.BatchResult{Err: todo.ErrBatchSkipped}
+			}
+		}
+	}
+	return results, nil
+}
+
+// apply makes the change of one operation of a batch.
+func (s *service) apply(ctx context.Context, op todo.BatchOperation) todo.BatchResult {
+	switch op.Op {
+	case todo.BatchCreate:
+		task, err := s.Save(ctx, op.Task)
+		return todo.BatchResult{Task: task, Err: err}
+	case todo.BatchUpdate:
+		task, created, err := s.Update(ctx, op.Task)
+		return todo.BatchResult{Task: task, Created: created, Err: err}
+	case todo.BatchToggle:
+		if err := s.ToggleDone(ctx, op.ID, 0); err != nil {
+			return todo.BatchResult{Err: err}
+		}
+		task, err := s.Get(ctx, op.ID)
+		return todo.BatchResult{Task: task, Err: err}
+	case todo.BatchDelete:
+		return todo.BatchResult{Err: s.Remove(ctx, op.ID, 0)}
+	default:
+		return todo.BatchResult{Err: todo.ErrInvalidBatchOp}
+	}
+}
+
+// stamp maintains the timestamps of a task that is about to be stored in place of
+// before, or inserted if before is nil. A task that stays done keeps its completion time
+// and stays archived if it was, while reopening a task takes it out of the archive.
+func stamp(task, before *todo.Task, now time.Time) {
+	task.CreatedAt, task.UpdatedAt, task.CompletedAt, task.ArchivedAt = now, now, nil, nil
+	if before != nil {
+		task.CreatedAt = before.CreatedAt
+		if before.Done && task.Done {
+			task.CompletedAt, task.ArchivedAt = before.CompletedAt, before.ArchivedAt
+		}
+	}
+	if task.Done && task.CompletedAt == nil {
+		task.CompletedAt = &now
+	}
+}
+
+// settleStatus keeps the status and Done of the task in step. A task without a status keeps
+// the one it had, unless it was marked as done or not done, which works as ToggleDone does.
+// Otherwise the workflow must allow the task to move from its previous status to its new one.
+func (s *service) settleStatus(task, before *todo.Task) error {
+	if task.Status == "" {
+		task.Status = todo.StatusOf(task.Done)
+		if before != nil && before.Done == task.Done {
+			task.Status = before.Status
+		}
+	} else if !task.Status.IsValid() {
+		return todo.ErrInvalidStatus
+	} else if before != nil && !s.workflow.Allows(before.Status, task.Status) {
+		return todo.ErrIllegalTransition
+	}
+	task.Done = task.Status == todo.StatusDone
+	return nil
+}
+
+// rank keeps the task where it was placed before it changed, or places a new task after
+// its siblings, the other tasks of its checklist with the same parent.
+func (s *service) rank(ctx context.Context, task, before *todo.Task) error {
+	if before != nil {
+		task.Rank = before.Rank
+		return nil
+	}
+
+	last, err := s.repository.FindAll(ctx, todo.TaskQuery{
+		ChecklistID: &task.ChecklistID,
+		ParentID:    &task.ParentID,
+		SortBy:      todo.SortByPosition,
+		Descending:  true,
+		Limit:       1,
+	})
+	if err != nil {
+		return fmt.Errorf("could not find last task: %v", err)
+	}
+	var lastRank string
+	if len(last) > 0 {
+		lastRank = last[0].Rank
+	}
+	task.Rank = todo.RankAfter(lastRank)
+	return nil
+}
+
+// record adds an entry to the history of the task that before and after are versions of,
+// attributing the change to the actor of ctx.
+func (s *service) record(ctx context.Context, op todo.Operation, before, after *todo.Task) error {
+	entry := todo.HistoryEntry{
+		Actor:     todo.ActorFromContext(ctx),
+		Time:      s.now().UTC(),
+		Operation: op,
+		Before:    before,
+		After:     after,
+	}
+	if after != nil {
+		entry.TaskID = after.ID
+	} else {
+		entry.TaskID = before.ID
+	}
+
+	if err := s.history.Insert(ctx, &entry); err != nil {
+		return fmt.Errorf("could not record history: %v", err)
+	}
+	return nil
+}
+
+// checkChecklistExists makes sure a task isn't assigned to a checklist that doesn't exist.
+func (s *service) checkChecklistExists(ctx context.Context, checklistID int64) error {
+	if checklistID == 0 {
+		return nil
+	}
+	_, err := s.GetChecklist(ctx, checklistID)
+	return err
+}
+
+// checkAssignee makes sure that the user a task is assigned to, if any, exists.
+func (s *service) checkAssignee(ctx context.Context, userID int64) error {
+	if userID == 0 {
+		return nil
+	}
+	_, err := s.users.FindByID(ctx, userID)
+	if err == todo.ErrUserNotFound {
+		return todo.ErrAssigneeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("could not find assignee: %v", err)
+	}
+	return nil
+}
+
+// checkParent makes sure that attaching the task to its parent neither
+// creates a cycle nor nests the task's subtree deeper than allowed.
+func (s *service) checkParent(ctx context.Context, task todo.Task) error {
+	if task.ParentID == 0 {
+		return nil
+	}
+	if task.ID != 0 && task.ParentID == task.ID {
+		return todo.ErrSubtaskCycle
+	}
+
+	depth := 0
+	for ancestorID := task.ParentID; ancestorID != 0; depth++ {
+		if task.ID != 0 && ancestorID == task.ID {
+			return todo.ErrSubtaskCycle
+		}
+		if depth >= s.maxSubtaskDepth {
+			return todo.ErrSubtaskTooDeep
+		}
+
+		ancestor, err := s.repository.FindByID(ctx, ancestorID)
+		if err == todo.ErrTaskNotFound && depth == 0 {
+			return todo.ErrParentTaskNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not find parent task: %v", err)
+		}
+		ancestorID = ancestor.ParentID
+	}
+
+	if task.ID == 0 {
+		return nil
+	}
+	height, err := s.subtaskHeight(ctx, task.ID, s.maxSubtaskDepth-depth)
+	if err != nil {
+		return err
+	}
+	if depth+height > s.maxSubtaskDepth {
+		return todo.ErrSubtaskTooDeep
+	}
+	return nil
+}
+
+// subtaskHeight returns how many levels of subtasks are nested below the task,
+// giving up once more than limit levels have been found.
+func (s *service) subtaskHeight(ctx context.Context, id int64, limit int) (int, error) {
+	subtasks, err := s.repository.FindAllByParentID(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("could not find subtasks: %v", err)
+	}
+	if len(subtasks) == 0 {
+		return 0, nil
+	}
+	if limit <= 0 {
+		return 1, nil
+	}
+
+	height := 0
+	for _, subtask := range subtasks {
+		h, err := s.subtaskHeight(ctx, subtask.ID, limit-1)
+		if err != nil {
+			return 0, err
+		}
+		if h > height {
+			height = h
+		}
+	}
+	return height + 1, nil
+}
+
+// validateTemplate checks a template before it is persisted and fills in the defaults
+// of its tasks the same way validate does for tasks. Due offsets are kept to the second
+// and may be negative for tasks that are due before the template's start.
+func validateTemplate(template *todo.Template) error {
+	if len(template.Tasks) == 0 {
+		return todo.ErrEmptyTemplate
+	}
+
+	tasks := make([]todo.TemplateTask, 0, len(template.Tasks))
+	for _, tt := range template.Tasks {
+		task := todo.Task{Priority: tt.Priority, Tags: tt.Tags, Estimate: tt.Estimate}
+		if err := validate(&task); err != nil {
+			return err
+		}
+		tt.Priority, tt.Tags, tt.Estimate = task.Priority, task.Tags, task.Estimate
+		if tt.DueOffset != nil {
+			offset := tt.DueOffset.Truncate(time.Second)
+			tt.DueOffset = &offset
+		}
+		tasks = append(tasks, tt)
+	}
+	template.Tasks = tasks
+	return nil
+}
+
+// validate checks a task before it is persisted and fills in defaults.
+// Due dates are stored in UTC so that every repository compares and
+// returns them the same way regardless of the caller's zone.
+func validate(task *todo.Task) error {
+	if task.Priority == 0 {
+		task.Priority = todo.PriorityNormal
+	}
+	if !task.Priority.IsValid() {
+		return todo.ErrInvalidPriority
+	}
+
+	if task.DueDate != nil {
+		due := task.DueDate.UTC()
+		task.DueDate = &due
+	}
+
+	if task.Estimate < 0 {
+		return todo.ErrInvalidEstimate
+	}
+	// estimates are kept to the second
+	task.Estimate = task.Estimate.Truncate(time.Second)
+
+	tags, err := todo.NormalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	task.Tags = tags
+
+	if task.Recurrence != nil {
+		return task.Recurrence.Validate()
+	}
+	return nil
+}
@@ -2,10 +2,18 @@ package checklist_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jarri-abidi/todo/pkg/checklist"
 	"github.com/jarri-abidi/todo/pkg/inmem"
+	"github.com/jarri-abidi/todo/pkg/postgres"
 	"github.com/jarri-abidi/todo/pkg/todo"
 
 	"github.com/stretchr/testify/assert"
@@ -15,7 +23,7 @@ import (
 func TestSave(t *testing.T) {
 	var (
 		assert = require.New(t)
-		svc    = checklist.NewService(inmem.NewTaskRepository())
+		svc    = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
 	)
 
 	task := todo.Task{Name: "Kachra phenk k ao", Done: false}
@@ -32,7 +40,7 @@ func TestList(t *testing.T) {
 	var (
 		require = require.New(t)
 		assert  = assert.New(t)
-		svc     = checklist.NewService(inmem.NewTaskRepository())
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
 	)
 
 	expected := []todo.Task{
@@ -60,13 +68,13 @@ func TestToggleDone(t *testing.T) {
 	var (
 		require = require.New(t)
 		assert  = assert.New(t)
-		svc     = checklist.NewService(inmem.NewTaskRepository())
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
 	)
 
 	task, err := svc.Save(context.TODO(), todo.Task{Name: "Kachra phenk k ao", Done: false})
 	require.NoError(err, "could not save task")
 
-	require.NoError(svc.ToggleDone(context.TODO(), task.ID), "could not toggle task")
+	require.NoError(svc.ToggleDone(context.TODO(), task.ID, 0), "could not toggle task")
 
 	list, err := svc.List(context.TODO())
 	assert.NoError(err, "could not list tasks")
@@ -77,13 +85,13 @@ func TestRemove(t *testing.T) {
 	var (
 		require = require.New(t)
 		assert  = assert.New(t)
-		svc     = checklist.NewService(inmem.NewTaskRepository())
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
 	)
 
 	task, err := svc.Save(context.TODO(), todo.Task{Name: "Kachra phenk k ao", Done: true})
 	require.NoError(err, "could not save task")
 
-	require.NoError(svc.Remove(context.TODO(), task.ID), "could not remove task")
+	require.NoError(svc.Remove(context.TODO(), task.ID, 0), "could not remove task")
 
 	list, err := svc.List(context.TODO())
 	assert.NoError(err, "could not list tasks")
@@ -94,7 +102,7 @@ func TestUpdate(t *testing.T) {
 	var (
 		require = require.New(t)
 		assert  = assert.New(t)
-		svc     = checklist.NewService(inmem.NewTaskRepository())
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
 	)
 
 	task, err := svc.Save(context.TODO(), todo.Task{Name: "Internet ki complaint karo"})
@@ -113,3 +121,1521 @@ func TestUpdate(t *testing.T) {
 	assert.Equal(list[0].Name, task.Name, "expected Name to be updated")
 	assert.Equal(list[0].Done, task.Done, "expected Done to be updated")
 }
+
+func TestListOverdue(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(func() time.Time { return now }))
+		past    = now.Add(-time.Hour)
+		future  = now.Add(time.Hour)
+	)
+
+	tasks := []todo.Task{
+		{Name: "Bill jama karao", DueDate: &past},
+		{Name: "Dawai le ao", DueDate: &past, Done: true},
+		{Name: "Gaari dhulwa lo", DueDate: &future},
+		{Name: "Kitaab wapas karo"},
+	}
+	for i := range tasks {
+		_, err := svc.Save(context.TODO(), tasks[i])
+		require.NoError(err, "could not save task")
+	}
+
+	list, err := svc.ListOverdue(context.TODO())
+	require.NoError(err, "could not list overdue tasks")
+	require.Len(list, 1, "expected only pending tasks past their due date")
+	assert.Equal("Bill jama karao", list[0].Name)
+}
+
+func TestListDueToday(t *testing.T) {
+	var (
+		require  = require.New(t)
+		assert   = assert.New(t)
+		loc      = time.FixedZone("PKT", 5*60*60)
+		now      = time.Date(2021, time.June, 1, 9, 0, 0, 0, loc)
+		svc      = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(func() time.Time { return now }))
+		today    = time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, loc)
+		tomorrow = today.AddDate(0, 0, 1)
+	)
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Doodh le ao", DueDate: &today})
+	require.NoError(err, "could not save task")
+	_, err = svc.Save(context.TODO(), todo.Task{Name: "Darzi se kapray lo", DueDate: &tomorrow})
+	require.NoError(err, "could not save task")
+
+	list, err := svc.ListDueToday(context.TODO(), loc)
+	require.NoError(err, "could not list tasks due today")
+	require.Len(list, 1)
+	assert.Equal("Doodh le ao", list[0].Name)
+	assert.Equal(time.UTC, list[0].DueDate.Location(), "expected due date to be stored in UTC")
+	assert.True(today.Equal(*list[0].DueDate), "expected due date to be the same instant")
+}
+
+func TestListDueBetween(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		base    = time.Date(2022, time.November, 1, 9, 0, 0, 0, time.UTC)
+	)
+
+	for i := 0; i < 5; i++ {
+		due := base.AddDate(0, 0, 4-i)
+		_, err := svc.Save(context.TODO(), todo.Task{Name: due.Weekday().String(), DueDate: &due})
+		require.NoError(err, "could not save task")
+	}
+
+	list, err := svc.ListDueBetween(context.TODO(), base.AddDate(0, 0, 1), base.AddDate(0, 0, 3))
+	require.NoError(err, "could not list tasks by due date")
+	require.Len(list, 2, "expected range to exclude its upper bound")
+	assert.Equal(base.AddDate(0, 0, 1), *list[0].DueDate, "expected tasks ordered by due date")
+	assert.Equal(base.AddDate(0, 0, 2), *list[1].DueDate, "expected tasks ordered by due date")
+
+	list, err = svc.ListDueBetween(context.TODO(), time.Time{}, base.AddDate(0, 0, 1))
+	require.NoError(err, "could not list tasks by due date")
+	assert.Len(list, 1, "expected zero lower bound to be unbounded")
+}
+
+func TestListOrdersByPriorityThenDueDate(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		soon    = time.Now().Add(time.Hour)
+		later   = time.Now().Add(24 * time.Hour)
+	)
+
+	tasks := []todo.Task{
+		{Name: "Kapray press karo", Priority: todo.PriorityLow},
+		{Name: "Bill jama karao", DueDate: &later},
+		{Name: "Cylinder bharwao", Priority: todo.PriorityUrgent},
+		{Name: "Dawai le ao", DueDate: &soon},
+		{Name: "Gaari ki service karwalo"},
+		{Name: "Fees jama karao", Priority: todo.PriorityHigh, DueDate: &later},
+	}
+	for i := range tasks {
+		_, err := svc.Save(context.TODO(), tasks[i])
+		require.NoError(err, "could not save task")
+	}
+
+	list, err := svc.List(context.TODO())
+	require.NoError(err, "could not list tasks")
+
+	var names []string
+	for _, task := range list {
+		names = append(names, task.Name)
+	}
+	assert.Equal([]string{
+		"Cylinder bharwao",
+		"Fees jama karao",
+		"Dawai le ao",
+		"Bill jama karao",
+		"Gaari ki service karwalo",
+		"Kapray press karo",
+	}, names)
+}
+
+func TestSaveRejectsInvalidPriority(t *testing.T) {
+	svc := checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+
+	_, err := svc.Save(context.TODO(), todo.Task{Name: "Kachra phenk k ao", Priority: todo.Priority(42)})
+	assert.Equal(t, todo.ErrInvalidPriority, err)
+
+	_, _, err = svc.Update(context.TODO(), todo.Task{ID: 1, Name: "Kachra phenk k ao", Priority: todo.Priority(-1)})
+	assert.Equal(t, todo.ErrInvalidPriority, err)
+}
+
+func TestListByTags(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+	)
+
+	tasks := []todo.Task{
+		{Name: "Login page banao", Tags: []string{"Frontend", "auth"}},
+		{Name: "Token refresh theek karo", Tags: []string{"backend", " AUTH "}},
+		{Name: "Logo badlo", Tags: []string{"frontend"}},
+		{Name: "Chai peeyo"},
+	}
+	for i := range tasks {
+		_, err := svc.Save(context.TODO(), tasks[i])
+		require.NoError(err, "could not save task")
+	}
+
+	list, err := svc.ListByTags(context.TODO(), []string{"auth", "frontend"}, false)
+	require.NoError(err, "could not list tasks by tags")
+	assert.Len(list, 3, "expected tasks with any of the tags")
+
+	list, err = svc.ListByTags(context.TODO(), []string{"AUTH", "frontend"}, true)
+	require.NoError(err, "could not list tasks by tags")
+	require.Len(list, 1, "expected only tasks with all of the tags")
+	assert.Equal("Login page banao", list[0].Name)
+	assert.Equal([]string{"auth", "frontend"}, list[0].Tags, "expected tags to be normalized")
+
+	tags, err := svc.ListTags(context.TODO())
+	require.NoError(err, "could not list tags")
+	assert.Equal([]todo.Tag{
+		{Name: "auth", TaskCount: 2},
+		{Name: "backend", TaskCount: 1},
+		{Name: "frontend", TaskCount: 2},
+	}, tags)
+
+	_, err = svc.Save(context.TODO(), todo.Task{Name: "Kuch bhi", Tags: []string{"  "}})
+	assert.Equal(todo.ErrInvalidTag, err)
+}
+
+func TestChecklists(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+	)
+
+	home, err := svc.CreateChecklist(ctx, todo.Checklist{Name: "Ghar"})
+	require.NoError(err, "could not create checklist")
+	office, err := svc.CreateChecklist(ctx, todo.Checklist{Name: "Daftar"})
+	require.NoError(err, "could not create checklist")
+
+	lists, err := svc.ListChecklists(ctx)
+	require.NoError(err, "could not list checklists")
+	assert.Equal([]todo.Checklist{*office, *home}, lists, "expected checklists ordered by name")
+
+	groceries, err := svc.SaveToChecklist(ctx, home.ID, todo.Task{Name: "Sabzi le ao"})
+	require.NoError(err, "could not save task to checklist")
+	report, err := svc.SaveToChecklist(ctx, office.ID, todo.Task{Name: "Report bhejo"})
+	require.NoError(err, "could not save task to checklist")
+	assert.Equal(groceries.Rank, report.Rank, "expected the tasks of each checklist to be ranked on their own")
+
+	tasks, err := svc.ListChecklistTasks(ctx, home.ID)
+	require.NoError(err, "could not list checklist tasks")
+	require.Len(tasks, 1)
+	assert.Equal(groceries.ID, tasks[0].ID)
+	assert.Equal(home.ID, tasks[0].ChecklistID)
+
+	laundry, err := svc.SaveToChecklist(ctx, home.ID, todo.Task{Name: "Kapre dho", Priority: todo.PriorityLow})
+	require.NoError(err, "could not save task to checklist")
+	_, err = svc.Move(ctx, groceries.ID, laundry.ID, true)
+	require.NoError(err, "could not move task")
+	tasks, err = svc.ListChecklistTasks(ctx, home.ID)
+	require.NoError(err, "could not list checklist tasks")
+	require.Len(tasks, 2)
+	assert.Equal([]int64{laundry.ID, groceries.ID}, []int64{tasks[0].ID, tasks[1].ID}, "expected checklist tasks in the order they were moved to")
+	require.NoError(svc.Remove(ctx, laundry.ID, 0), "could not remove task")
+
+	assert.Equal(todo.ErrTaskNotFound, svc.ToggleDoneInChecklist(ctx, home.ID, report.ID, 0), "expected tasks of other checklists to be hidden")
+	assert.Equal(todo.ErrTaskNotFound, svc.RemoveFromChecklist(ctx, home.ID, report.ID, 0), "expected tasks of other checklists to be hidden")
+	_, _, err = svc.UpdateInChecklist(ctx, home.ID, todo.Task{ID: report.ID, Name: "Report jala do"})
+	assert.Equal(todo.ErrTaskAlreadyExists, err, "expected tasks of other checklists not to be overwritten")
+
+	require.NoError(svc.ToggleDoneInChecklist(ctx, home.ID, groceries.ID, 0), "could not toggle task in checklist")
+	tasks, err = svc.ListChecklistTasks(ctx, home.ID)
+	require.NoError(err, "could not list checklist tasks")
+	assert.True(tasks[0].Done, "expected task to be done")
+
+	_, err = svc.Save(ctx, todo.Task{Name: "Ghost task", ChecklistID: 1337})
+	assert.Equal(todo.ErrChecklistNotFound, err, "expected tasks to only be saved to existing checklists")
+
+	require.NoError(svc.RemoveChecklist(ctx, home.ID), "could not remove checklist")
+	_, err = svc.ListChecklistTasks(ctx, home.ID)
+	assert.Equal(todo.ErrChecklistNotFound, err)
+
+	all, err := svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	require.Len(all, 1, "expected tasks of removed checklist to be removed")
+	assert.Equal(report.ID, all[0].ID)
+}
+
+func TestSubtasks(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithMaxSubtaskDepth(2))
+		ctx     = context.TODO()
+	)
+
+	party, err := svc.Save(ctx, todo.Task{Name: "Dawat ki tayyari"})
+	require.NoError(err, "could not save task")
+	food, err := svc.Save(ctx, todo.Task{Name: "Khana banao", ParentID: party.ID})
+	require.NoError(err, "could not save subtask")
+	biryani, err := svc.Save(ctx, todo.Task{Name: "Biryani", ParentID: food.ID})
+	require.NoError(err, "could not save subtask")
+
+	_, err = svc.Save(ctx, todo.Task{Name: "Raita", ParentID: biryani.ID})
+	assert.Equal(todo.ErrSubtaskTooDeep, err, "expected max depth to be enforced")
+	_, err = svc.Save(ctx, todo.Task{Name: "Mehmaan bulao", ParentID: 1337})
+	assert.Equal(todo.ErrParentTaskNotFound, err)
+
+	party.ParentID = biryani.ID
+	_, _, err = svc.Update(ctx, *party)
+	assert.Equal(todo.ErrSubtaskCycle, err, "expected task not to become a subtask of its own subtask")
+
+	food.ParentID = 0
+	food, _, err = svc.Update(ctx, *food)
+	require.NoError(err, "could not move subtask to the top level")
+	food.ParentID = party.ID
+	_, _, err = svc.Update(ctx, *food)
+	require.NoError(err, "could not move subtask back")
+
+	subtasks, err := svc.ListSubtasks(ctx, party.ID)
+	require.NoError(err, "could not list subtasks")
+	require.Len(subtasks, 1)
+	assert.Equal(food.ID, subtasks[0].ID)
+
+	require.NoError(svc.ToggleDoneTree(ctx, party.ID, 0), "could not toggle task tree")
+	list, err := svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	for _, task := range list {
+		assert.True(task.Done, "expected %q to be completed with its parent", task.Name)
+	}
+
+	require.NoError(svc.Remove(ctx, food.ID, 0), "could not remove task")
+	list, err = svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	require.Len(list, 1, "expected subtasks to be removed with their parent")
+	assert.Equal(party.ID, list[0].ID)
+}
+
+func TestToggleDoneTreeCompletesSubtasksLikeTransition(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		r       = inmem.NewRepositories()
+		svc     = checklist.NewService(r, inmem.NewBlobStore(), checklist.WithUnitOfWork(inmem.NewUnitOfWork(r)))
+		ctx     = context.TODO()
+	)
+
+	party, err := svc.Save(ctx, todo.Task{Name: "Dawat ki tayyari"})
+	require.NoError(err, "could not save task")
+	food, err := svc.Save(ctx, todo.Task{Name: "Khana banao", ParentID: party.ID})
+	require.NoError(err, "could not save subtask")
+	biryani, err := svc.Save(ctx, todo.Task{Name: "Biryani", ParentID: food.ID})
+	require.NoError(err, "could not save subtask")
+	music, err := svc.Save(ctx, todo.Task{Name: "Gaane chalao", ParentID: party.ID})
+	require.NoError(err, "could not save subtask")
+	rice, err := svc.Save(ctx, todo.Task{Name: "Chawal lao"})
+	require.NoError(err, "could not save task")
+	require.NoError(svc.AddBlocker(ctx, biryani.ID, rice.ID), "could not add blocker")
+	_, err = svc.Transition(ctx, music.ID, todo.StatusWontDo)
+	require.NoError(err, "could not transition subtask")
+
+	assert.Equal(todo.ErrTaskBlocked, svc.ToggleDoneTree(ctx, party.ID, 0), "expected blocked subtask not to be completed")
+	for _, id := range []int64{party.ID, food.ID, biryani.ID} {
+		task, err := svc.Get(ctx, id)
+		require.NoError(err, "could not get task")
+		assert.False(task.Done, "expected %q to be left pending", task.Name)
+	}
+
+	require.NoError(svc.ToggleDone(ctx, rice.ID, 0), "could not toggle blocker")
+	require.NoError(svc.ToggleDoneTree(ctx, party.ID, 0), "could not toggle task tree")
+	for _, id := range []int64{party.ID, food.ID, biryani.ID} {
+		task, err := svc.Get(ctx, id)
+		require.NoError(err, "could not get task")
+		assert.True(task.Done, "expected %q to be completed with its parent", task.Name)
+	}
+	music, err = svc.Get(ctx, music.ID)
+	require.NoError(err, "could not get task")
+	assert.Equal(todo.StatusWontDo, music.Status, "expected subtask the workflow can't complete to be left as it is")
+}
+
+func TestRecurringTasks(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+		monday  = time.Date(2100, 1, 4, 9, 0, 0, 0, time.UTC)
+	)
+
+	findPending := func(name string) *todo.Task {
+		list, err := svc.List(ctx)
+		require.NoError(err, "could not list tasks")
+		for _, task := range list {
+			if task.Name == name && !task.Done {
+				return &task
+			}
+		}
+		return nil
+	}
+
+	weekly, err := todo.ParseRecurrence("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=2")
+	require.NoError(err, "could not parse recurrence")
+	report, err := svc.Save(ctx, todo.Task{Name: "Weekly report", DueDate: &monday, Recurrence: weekly})
+	require.NoError(err, "could not save task")
+
+	require.NoError(svc.ToggleDone(ctx, report.ID, 0), "could not toggle task")
+	next := findPending("Weekly report")
+	require.NotNil(next, "expected next occurrence to be spawned")
+	assert.NotEqual(report.ID, next.ID)
+	assert.Equal(monday.AddDate(0, 0, 3), *next.DueDate, "expected next occurrence on thursday")
+	assert.Equal("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=1", next.Recurrence.String())
+
+	require.NoError(svc.ToggleDone(ctx, next.ID, 0), "could not toggle task")
+	assert.Nil(findPending("Weekly report"), "expected recurrence to end after COUNT occurrences")
+
+	endOfMonth := time.Date(2100, 1, 31, 9, 0, 0, 0, time.UTC)
+	rent, err := svc.Save(ctx, todo.Task{Name: "Kiraya do", DueDate: &endOfMonth, Recurrence: &todo.Recurrence{Frequency: todo.Monthly, Interval: 1}})
+	require.NoError(err, "could not save task")
+	require.NoError(svc.ToggleDone(ctx, rent.ID, 0), "could not toggle task")
+	next = findPending("Kiraya do")
+	require.NotNil(next, "expected next occurrence to be spawned")
+	assert.Equal(time.Date(2100, 2, 28, 9, 0, 0, 0, time.UTC), *next.DueDate, "expected day to be clamped to end of month")
+
+	longAgo := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	standup, err := svc.Save(ctx, todo.Task{Name: "Stand-up prep", DueDate: &longAgo, Recurrence: &todo.Recurrence{Frequency: todo.Daily, Interval: 1}})
+	require.NoError(err, "could not save task")
+	require.NoError(svc.ToggleDone(ctx, standup.ID, 0), "could not toggle task")
+	next = findPending("Stand-up prep")
+	require.NotNil(next, "expected next occurrence to be spawned")
+	assert.True(next.DueDate.After(time.Now()), "expected missed occurrences to be skipped")
+	assert.Equal(9, next.DueDate.Hour(), "expected time of day to be kept")
+
+	_, err = svc.Save(ctx, todo.Task{Name: "Har ghante", Recurrence: &todo.Recurrence{Frequency: "HOURLY", Interval: 1}})
+	assert.IsType(todo.ErrInvalidRecurrence{}, err)
+}
+
+func TestListPage(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+	)
+
+	for _, name := range []string{"Chai banao", "Doodh lao", "Anday lao", "Bartan dho", "Ande ubalo"} {
+		_, err := svc.Save(ctx, todo.Task{Name: name})
+		require.NoError(err, "could not save task")
+	}
+	require.NoError(svc.ToggleDone(ctx, 4, 0), "could not toggle task")
+
+	names := func(list []todo.Task) []string {
+		var names []string
+		for _, task := range list {
+			names = append(names, task.Name)
+		}
+		return names
+	}
+
+	query := todo.TaskQuery{SortBy: todo.SortByName, Limit: 2}
+	var pages [][]string
+	for {
+		list, next, err := svc.ListPage(ctx, query)
+		require.NoError(err, "could not list page")
+		pages = append(pages, names(list))
+		if next == nil {
+			break
+		}
+		query.After = next
+	}
+	assert.Equal([][]string{{"Anday lao", "Ande ubalo"}, {"Bartan dho", "Chai banao"}, {"Doodh lao"}}, pages)
+
+	pending := false
+	list, next, err := svc.ListPage(ctx, todo.TaskQuery{Done: &pending, Name: "LAO", SortBy: todo.SortByID, Descending: true})
+	require.NoError(err, "could not list page")
+	assert.Nil(next)
+	assert.Equal([]string{"Anday lao", "Doodh lao"}, names(list))
+
+	_, _, err = svc.ListPage(ctx, todo.TaskQuery{SortBy: "color"})
+	assert.Equal(todo.ErrInvalidSortField, err)
+}
+
+func TestUpdateRejectsStaleVersion(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+	)
+
+	saved, err := svc.Save(ctx, todo.Task{Name: "Bijli ka bill"})
+	require.NoError(err, "could not save task")
+	assert.Equal(int64(1), saved.Version)
+
+	stale := *saved
+	saved.Name = "Bijli ka bill bharo"
+	updated, _, err := svc.Update(ctx, *saved)
+	require.NoError(err, "could not update task")
+	assert.Equal(int64(2), updated.Version)
+
+	stale.Done = true
+	_, _, err = svc.Update(ctx, stale)
+	assert.Equal(todo.ErrVersionConflict, err, "expected stale update to be rejected")
+
+	stale.Version = 0
+	updated, _, err = svc.Update(ctx, stale)
+	require.NoError(err, "expected update without version to overwrite")
+	assert.Equal(int64(3), updated.Version)
+
+	_, _, err = svc.Update(ctx, todo.Task{ID: 1337, Name: "Gas ka bill", Version: 1})
+	assert.Equal(todo.ErrTaskNotFound, err, "expected versioned update not to create a task")
+}
+
+func TestToggleAndRemoveRejectStaleVersion(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+	)
+
+	saved, err := svc.Save(ctx, todo.Task{Name: "Bijli ka bill"})
+	require.NoError(err, "could not save task")
+
+	require.NoError(svc.ToggleDone(ctx, saved.ID, saved.Version), "could not toggle task")
+	assert.Equal(todo.ErrVersionConflict, svc.ToggleDone(ctx, saved.ID, saved.Version), "expected stale toggle to be rejected")
+	assert.Equal(todo.ErrVersionConflict, svc.ToggleDoneTree(ctx, saved.ID, saved.Version), "expected stale toggle to be rejected")
+	assert.Equal(todo.ErrVersionConflict, svc.Remove(ctx, saved.ID, saved.Version), "expected stale removal to be rejected")
+
+	found, err := svc.Get(ctx, saved.ID)
+	require.NoError(err, "expected task not to be removed")
+	assert.True(found.Done, "expected task to be toggled only once")
+	assert.NoError(svc.Remove(ctx, saved.ID, found.Version), "could not remove task")
+}
+
+func TestTrash(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithTrashRetention(0))
+		ctx     = context.TODO()
+	)
+
+	party, err := svc.Save(ctx, todo.Task{Name: "Dawat ki tayyari"})
+	require.NoError(err, "could not save task")
+	food, err := svc.Save(ctx, todo.Task{Name: "Khana banao", ParentID: party.ID})
+	require.NoError(err, "could not save subtask")
+	laundry, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+
+	require.NoError(svc.Remove(ctx, party.ID, 0), "could not remove task")
+	list, err := svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	require.Len(list, 1, "expected trashed tasks to be left out")
+	assert.Equal(laundry.ID, list[0].ID)
+	_, err = svc.Get(ctx, food.ID)
+	assert.Equal(todo.ErrTaskNotFound, err, "expected subtask to be trashed with its parent")
+
+	trash, err := svc.ListTrash(ctx)
+	require.NoError(err, "could not list trash")
+	require.Len(trash, 2)
+	assert.NotNil(trash[0].DeletedAt)
+
+	_, _, err = svc.Update(ctx, todo.Task{ID: food.ID, Name: "Khana mangwao"})
+	assert.Equal(todo.ErrTaskAlreadyExists, err, "expected trashed task not to be overwritten")
+	_, err = svc.Restore(ctx, food.ID)
+	assert.Equal(todo.ErrParentTaskTrashed, err)
+
+	restored, err := svc.Restore(ctx, party.ID)
+	require.NoError(err, "could not restore task")
+	assert.Nil(restored.DeletedAt)
+	subtasks, err := svc.ListSubtasks(ctx, party.ID)
+	require.NoError(err, "could not list subtasks")
+	assert.Len(subtasks, 1, "expected subtask to be restored with its parent")
+
+	require.NoError(svc.Remove(ctx, laundry.ID, 0), "could not remove task")
+	purged, err := svc.PurgeTrash(ctx)
+	require.NoError(err, "could not purge trash")
+	assert.Equal(int64(1), purged)
+	_, err = svc.Restore(ctx, laundry.ID)
+	assert.Equal(todo.ErrTaskNotFound, err, "expected purged task to be gone for good")
+}
+
+func TestUpdateDoesNotRecreateTrashedTask(t *testing.T) {
+	repositories := map[string]func(t *testing.T) todo.Repositories{
+		"inmem": func(*testing.T) todo.Repositories { return inmem.NewRepositories() },
+		"postgres": func(t *testing.T) todo.Repositories {
+			source := os.Getenv("TODOAPP_DB_SOURCE")
+			if source == "" {
+				t.Skip("TODOAPP_DB_SOURCE isn't set")
+			}
+			db, err := postgres.NewDB(context.TODO(), source)
+			require.NoError(t, err, "could not connect to database")
+			t.Cleanup(func() { db.Close() })
+			require.NoError(t, postgres.Migrate("file://../postgres/migrations", db), "could not migrate database")
+			return postgres.NewRepositories(db)
+		},
+	}
+
+	for name, newRepositories := range repositories {
+		t.Run(name, func(t *testing.T) {
+			var (
+				assert  = assert.New(t)
+				require = require.New(t)
+				svc     = checklist.NewService(newRepositories(t), inmem.NewBlobStore())
+				ctx     = context.TODO()
+			)
+
+			laundry, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+			require.NoError(err, "could not save task")
+			require.NoError(svc.Remove(ctx, laundry.ID, 0), "could not remove task")
+
+			_, _, err = svc.Update(ctx, todo.Task{ID: laundry.ID, Name: "Kapre istri karo"})
+			assert.Equal(todo.ErrTaskAlreadyExists, err, "expected trashed task not to be overwritten")
+			trash, err := svc.ListTrash(ctx)
+			require.NoError(err, "could not list trash")
+			var trashed *todo.Task
+			for i := range trash {
+				if trash[i].ID == laundry.ID {
+					trashed = &trash[i]
+				}
+			}
+			require.NotNil(trashed, "expected task to stay in the trash")
+			assert.Equal("Kapre dho", trashed.Name)
+			list, err := svc.List(ctx)
+			require.NoError(err, "could not list tasks")
+			for _, task := range list {
+				assert.NotEqual("Kapre istri karo", task.Name, "expected no task to be created in its place")
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = todo.ContextWithActor(context.TODO(), "jarri")
+	)
+
+	task, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+	require.NoError(svc.ToggleDone(ctx, task.ID, 0), "could not toggle task")
+	_, _, err = svc.Update(context.TODO(), todo.Task{ID: task.ID, Name: "Kapre dho aur sukhao"})
+	require.NoError(err, "could not update task")
+	require.NoError(svc.Remove(ctx, task.ID, 0), "could not remove task")
+
+	entries, hasMore, err := svc.ListHistory(ctx, task.ID, 0, 0)
+	require.NoError(err, "could not list history")
+	assert.False(hasMore)
+	require.Len(entries, 4)
+
+	assert.Equal(todo.OperationSave, entries[0].Operation)
+	assert.Nil(entries[0].Before)
+	assert.Equal("Kapre dho", entries[0].After.Name)
+	assert.Equal("jarri", entries[0].Actor)
+
+	assert.Equal(todo.OperationToggleDone, entries[1].Operation)
+	assert.False(entries[1].Before.Done)
+	assert.True(entries[1].After.Done)
+
+	assert.Equal(todo.OperationUpdate, entries[2].Operation)
+	assert.Equal("Kapre dho", entries[2].Before.Name)
+	assert.Equal("Kapre dho aur sukhao", entries[2].After.Name)
+	assert.Equal(todo.AnonymousActor, entries[2].Actor)
+
+	assert.Equal(todo.OperationRemove, entries[3].Operation)
+	assert.Nil(entries[3].After)
+
+	page, hasMore, err := svc.ListHistory(ctx, task.ID, entries[0].ID, 2)
+	require.NoError(err, "could not list history")
+	assert.True(hasMore)
+	assert.Equal(entries[1:3], page)
+
+	_, _, err = svc.ListHistory(ctx, 42, 0, 0)
+	assert.Equal(todo.ErrTaskNotFound, err)
+}
+
+func TestTimestamps(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC)
+		clock   = func() time.Time { return now }
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(clock))
+		ctx     = context.TODO()
+	)
+
+	task, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+	assert.Equal(now, task.CreatedAt)
+	assert.Equal(now, task.UpdatedAt)
+	assert.Nil(task.CompletedAt)
+
+	created := now
+	now = now.Add(time.Hour)
+	require.NoError(svc.ToggleDone(ctx, task.ID, 0), "could not toggle task")
+	task, err = svc.Get(ctx, task.ID)
+	require.NoError(err, "could not get task")
+	assert.Equal(created, task.CreatedAt)
+	assert.Equal(now, task.UpdatedAt)
+	require.NotNil(task.CompletedAt)
+	assert.Equal(now, *task.CompletedAt)
+
+	completed := now
+	now = now.Add(time.Hour)
+	task, _, err = svc.Update(ctx, todo.Task{ID: task.ID, Name: "Kapre dho aur sukhao", Done: true})
+	require.NoError(err, "could not update task")
+	assert.Equal(created, task.CreatedAt)
+	assert.Equal(now, task.UpdatedAt)
+	require.NotNil(task.CompletedAt)
+	assert.Equal(completed, *task.CompletedAt, "expected completion time to be kept while the task stays done")
+
+	task, _, err = svc.Update(ctx, todo.Task{ID: task.ID, Name: "Kapre dho aur sukhao"})
+	require.NoError(err, "could not update task")
+	assert.Nil(task.CompletedAt, "expected completion time to be cleared when the task is reopened")
+
+	done := true
+	list, _, err := svc.ListPage(ctx, todo.TaskQuery{Completed: todo.TimeRange{After: created}})
+	require.NoError(err, "could not list tasks")
+	assert.Empty(list, "expected pending tasks not to match a completion range")
+	list, _, err = svc.ListPage(ctx, todo.TaskQuery{Done: &done})
+	require.NoError(err, "could not list tasks")
+	assert.Empty(list)
+	list, _, err = svc.ListPage(ctx, todo.TaskQuery{Updated: todo.TimeRange{After: now}, SortBy: todo.SortByUpdatedAt})
+	require.NoError(err, "could not list tasks")
+	assert.Len(list, 1)
+}
+
+func TestComments(t *testing.T) {
+	var (
+		require  = require.New(t)
+		assert   = assert.New(t)
+		r        = inmem.NewRepositories()
+		comments = r.Comments
+		svc      = checklist.NewService(r, inmem.NewBlobStore(), checklist.WithTrashRetention(0))
+		ctx      = todo.ContextWithActor(context.TODO(), "jarri")
+	)
+
+	task, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+	other, err := svc.Save(ctx, todo.Task{Name: "Bartan dho"})
+	require.NoError(err, "could not save task")
+
+	comment, err := svc.AddComment(ctx, task.ID, todo.Comment{Body: "Sirf safed wale"})
+	require.NoError(err, "could not add comment")
+	assert.Equal("jarri", comment.Author)
+	_, err = svc.AddComment(ctx, task.ID, todo.Comment{})
+	assert.Equal(todo.ErrEmptyComment, err)
+	_, err = svc.AddComment(ctx, 42, todo.Comment{Body: "Kaunsa kaam?"})
+	assert.Equal(todo.ErrTaskNotFound, err)
+
+	edited, err := svc.EditComment(ctx, task.ID, todo.Comment{ID: comment.ID, Body: "Sirf rangeen wale"})
+	require.NoError(err, "could not edit comment")
+	assert.Equal("Sirf rangeen wale", edited.Body)
+	assert.Equal("jarri", edited.Author)
+	_, err = svc.EditComment(ctx, other.ID, todo.Comment{ID: comment.ID, Body: "Galat kaam"})
+	assert.Equal(todo.ErrCommentNotFound, err, "expected comment not to be found on another task")
+
+	require.NoError(svc.Remove(ctx, task.ID, 0), "could not remove task")
+	_, err = svc.ListComments(ctx, task.ID)
+	assert.Equal(todo.ErrTaskNotFound, err, "expected comments to be archived with the task")
+	_, err = svc.Restore(ctx, task.ID)
+	require.NoError(err, "could not restore task")
+	list, err := svc.ListComments(ctx, task.ID)
+	require.NoError(err, "could not list comments")
+	require.Len(list, 1, "expected comments to be restored with the task")
+	assert.Equal(*edited, list[0])
+
+	require.NoError(svc.RemoveComment(ctx, task.ID, comment.ID), "could not remove comment")
+	assert.Equal(todo.ErrCommentNotFound, svc.RemoveComment(ctx, task.ID, comment.ID))
+
+	_, err = svc.AddComment(ctx, other.ID, todo.Comment{Body: "Raat ko"})
+	require.NoError(err, "could not add comment")
+	require.NoError(svc.Remove(ctx, other.ID, 0), "could not remove task")
+	_, err = svc.PurgeTrash(ctx)
+	require.NoError(err, "could not purge trash")
+	left, err := comments.FindAllByTaskID(ctx, other.ID)
+	require.NoError(err, "could not find comments")
+	assert.Empty(left, "expected comments to be deleted with their task")
+}
+
+func TestDependencies(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+	)
+
+	wash, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+	dry, err := svc.Save(ctx, todo.Task{Name: "Kapre sukhao"})
+	require.NoError(err, "could not save task")
+	iron, err := svc.Save(ctx, todo.Task{Name: "Kapre istri karo"})
+	require.NoError(err, "could not save task")
+
+	require.NoError(svc.AddBlocker(ctx, dry.ID, wash.ID), "could not add blocker")
+	require.NoError(svc.AddBlocker(ctx, iron.ID, dry.ID), "could not add blocker")
+	assert.Equal(todo.ErrDependencyCycle, svc.AddBlocker(ctx, wash.ID, iron.ID))
+	assert.Equal(todo.ErrDependencyCycle, svc.AddBlocker(ctx, wash.ID, wash.ID))
+	assert.Equal(todo.ErrTaskNotFound, svc.AddBlocker(ctx, wash.ID, 42))
+
+	blockers, err := svc.ListBlockers(ctx, iron.ID)
+	require.NoError(err, "could not list blockers")
+	require.Len(blockers, 1)
+	assert.Equal(dry.ID, blockers[0].ID)
+
+	blocked, err := svc.ListBlocked(ctx)
+	require.NoError(err, "could not list blocked tasks")
+	assert.Len(blocked, 2)
+	unblocked, err := svc.ListUnblocked(ctx)
+	require.NoError(err, "could not list unblocked tasks")
+	require.Len(unblocked, 1)
+	assert.Equal(wash.ID, unblocked[0].ID)
+
+	assert.Equal(todo.ErrTaskBlocked, svc.ToggleDone(ctx, dry.ID, 0))
+	_, _, err = svc.Update(ctx, todo.Task{ID: dry.ID, Name: dry.Name, Done: true})
+	assert.Equal(todo.ErrTaskBlocked, err, "expected blocked task not to be done by update")
+	_, _, err = svc.Update(ctx, todo.Task{ID: dry.ID, Name: dry.Name, Status: todo.StatusDone})
+	assert.Equal(todo.ErrTaskBlocked, err, "expected blocked task not to be done by update")
+	results, err := svc.Batch(ctx, []todo.BatchOperation{{Op: todo.BatchUpdate, Task: todo.Task{ID: dry.ID, Name: dry.Name, Done: true}}}, false)
+	require.NoError(err, "could not run batch")
+	assert.Equal([]todo.BatchResult{{Err: todo.ErrTaskBlocked}}, results, "expected blocked task not to be done by batch update")
+	require.NoError(svc.ToggleDone(ctx, wash.ID, 0), "could not toggle task")
+	require.NoError(svc.ToggleDone(ctx, dry.ID, 0), "expected task to be unblocked once its blocker is done")
+
+	require.NoError(svc.RemoveBlocker(ctx, iron.ID, dry.ID), "could not remove blocker")
+	assert.Equal(todo.ErrDependencyNotFound, svc.RemoveBlocker(ctx, iron.ID, dry.ID))
+}
+
+func TestMove(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(), checklist.WithClock(func() time.Time { return now }))
+		ctx     = context.TODO()
+	)
+
+	var ids []int64
+	for _, name := range []string{"Doodh lao", "Anday lao", "Chai banao"} {
+		task, err := svc.Save(ctx, todo.Task{Name: name, Priority: todo.PriorityNormal})
+		require.NoError(err, "could not save task")
+		ids = append(ids, task.ID)
+	}
+	positions := func() []int64 {
+		list, _, err := svc.ListPage(ctx, todo.TaskQuery{SortBy: todo.SortByPosition})
+		require.NoError(err, "could not list tasks")
+		var got []int64
+		for _, task := range list {
+			got = append(got, task.ID)
+		}
+		return got
+	}
+	assert.Equal(ids, positions(), "expected tasks in the order they were saved")
+
+	now = now.Add(time.Hour)
+	moved, err := svc.Move(ctx, ids[2], ids[0], false)
+	require.NoError(err, "could not move task")
+	assert.Equal([]int64{ids[2], ids[0], ids[1]}, positions())
+	assert.Equal(now, moved.UpdatedAt, "expected move to update the task")
+
+	_, err = svc.Move(ctx, ids[2], ids[0], true)
+	require.NoError(err, "could not move task")
+	assert.Equal([]int64{ids[0], ids[2], ids[1]}, positions())
+
+	_, err = svc.Move(ctx, ids[0], ids[1], true)
+	require.NoError(err, "could not move task")
+	assert.Equal([]int64{ids[2], ids[1], ids[0]}, positions())
+
+	updated, _, err := svc.Update(ctx, todo.Task{ID: ids[1], Name: "Anday aur double roti lao", Priority: todo.PriorityNormal})
+	require.NoError(err, "could not update task")
+	assert.Equal([]int64{ids[2], ids[1], ids[0]}, positions(), "expected update to keep the task in place")
+
+	saved, err := svc.Save(ctx, todo.Task{Name: "Nashta karo", Priority: todo.PriorityNormal})
+	require.NoError(err, "could not save task")
+	assert.Equal([]int64{ids[2], ids[1], ids[0], saved.ID}, positions(), "expected new task to be placed last")
+	assert.True(updated.Rank < saved.Rank)
+
+	subtask, err := svc.Save(ctx, todo.Task{Name: "Anday ubalo", ParentID: ids[1]})
+	require.NoError(err, "could not save subtask")
+	topLevel := int64(0)
+	list, _, err := svc.ListPage(ctx, todo.TaskQuery{ParentID: &topLevel, SortBy: todo.SortByPosition})
+	require.NoError(err, "could not list tasks")
+	assert.Len(list, 4, "expected subtask to be left out of top-level tasks")
+	list, _, err = svc.ListPage(ctx, todo.TaskQuery{ParentID: &ids[1]})
+	require.NoError(err, "could not list tasks")
+	assert.Equal([]todo.Task{*subtask}, list)
+
+	second, err := svc.Save(ctx, todo.Task{Name: "Anday chheelo", ParentID: ids[1], Priority: todo.PriorityLow})
+	require.NoError(err, "could not save subtask")
+	_, err = svc.Move(ctx, subtask.ID, second.ID, true)
+	require.NoError(err, "could not move subtask")
+	subtasks, err := svc.ListSubtasks(ctx, ids[1])
+	require.NoError(err, "could not list subtasks")
+	require.Len(subtasks, 2)
+	assert.Equal([]int64{second.ID, subtask.ID}, []int64{subtasks[0].ID, subtasks[1].ID}, "expected subtasks in the order they were moved to")
+
+	_, err = svc.Move(ctx, ids[0], 42, false)
+	assert.Equal(todo.ErrTaskNotFound, err)
+	_, err = svc.Move(ctx, subtask.ID, ids[0], false)
+	assert.Equal(todo.ErrInvalidMoveTarget, err, "expected subtask not to be moved among top-level tasks")
+	errands, err := svc.CreateChecklist(ctx, todo.Checklist{Name: "Bazaar"})
+	require.NoError(err, "could not create checklist")
+	bread, err := svc.SaveToChecklist(ctx, errands.ID, todo.Task{Name: "Double roti lao"})
+	require.NoError(err, "could not save task to checklist")
+	_, err = svc.Move(ctx, ids[0], bread.ID, true)
+	assert.Equal(todo.ErrInvalidMoveTarget, err, "expected task not to be moved among tasks of another checklist")
+}
+
+func TestRanksStayShortWhenAppending(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+	)
+
+	var last *todo.Task
+	for i := 0; i < 2000; i++ {
+		task, err := svc.Save(ctx, todo.Task{Name: fmt.Sprintf("Kaam %d", i)})
+		require.NoError(err, "could not save task")
+		require.LessOrEqual(len(task.Rank), 8, "expected rank of task %d to stay short", i)
+		if last != nil {
+			require.True(last.Rank < task.Rank, "expected task %d to be placed last", i)
+		}
+		last = task
+	}
+
+	for i := 0; i < 100; i++ {
+		list, err := svc.List(ctx)
+		require.NoError(err, "could not list tasks")
+		first := list[0]
+		for _, task := range list {
+			if task.Rank < first.Rank {
+				first = task
+			}
+		}
+		moved, err := svc.Move(ctx, first.ID, last.ID, true)
+		require.NoError(err, "could not move task")
+		last = moved
+	}
+	assert.LessOrEqual(len(last.Rank), 8, "expected moving tasks to the end to keep ranks short")
+}
+
+func TestTransition(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+	)
+
+	task, err := svc.Save(ctx, todo.Task{Name: "Report likho"})
+	require.NoError(err, "could not save task")
+	assert.Equal(todo.StatusTodo, task.Status)
+
+	task, err = svc.Transition(ctx, task.ID, todo.StatusInProgress)
+	require.NoError(err, "could not start task")
+	assert.Equal(todo.StatusInProgress, task.Status)
+	assert.False(task.Done)
+
+	task, err = svc.Transition(ctx, task.ID, todo.StatusReview)
+	require.NoError(err, "could not send task for review")
+	_, err = svc.Transition(ctx, task.ID, todo.StatusTodo)
+	assert.Equal(todo.ErrIllegalTransition, err)
+	_, err = svc.Transition(ctx, task.ID, todo.Status("archived"))
+	assert.Equal(todo.ErrInvalidStatus, err)
+
+	task, err = svc.Transition(ctx, task.ID, todo.StatusDone)
+	require.NoError(err, "could not finish task")
+	assert.True(task.Done)
+	assert.NotNil(task.CompletedAt)
+
+	require.NoError(svc.ToggleDone(ctx, task.ID, 0), "could not toggle task")
+	task, err = svc.Get(ctx, task.ID)
+	require.NoError(err, "could not get task")
+	assert.Equal(todo.StatusTodo, task.Status, "expected toggling a done task to reopen it")
+
+	task.Status = todo.StatusWontDo
+	task, _, err = svc.Update(ctx, *task)
+	require.NoError(err, "could not update task")
+	assert.Equal(todo.StatusWontDo, task.Status)
+	require.NoError(svc.ToggleDone(ctx, task.ID, 0), "expected toggling to ignore the workflow")
+	task, err = svc.Get(ctx, task.ID)
+	require.NoError(err, "could not get task")
+	assert.Equal(todo.StatusDone, task.Status)
+	assert.True(task.Done)
+
+	strict := checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(),
+		checklist.WithWorkflow(todo.Workflow{todo.StatusTodo: {todo.StatusInProgress}}))
+	task, err = strict.Save(ctx, todo.Task{Name: "Report likho"})
+	require.NoError(err, "could not save task")
+	_, err = strict.Transition(ctx, task.ID, todo.StatusDone)
+	assert.Equal(todo.ErrIllegalTransition, err)
+}
+
+func TestAssignees(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = todo.ContextWithActor(context.TODO(), "jarri")
+	)
+
+	jarri, err := svc.CreateUser(ctx, todo.User{Name: " jarri "})
+	require.NoError(err, "could not create user")
+	assert.Equal("jarri", jarri.Name)
+	_, err = svc.CreateUser(ctx, todo.User{Name: "jarri"})
+	assert.Equal(todo.ErrUserAlreadyExists, err)
+	_, err = svc.CreateUser(ctx, todo.User{Name: " "})
+	assert.Equal(todo.ErrInvalidUserName, err)
+	abidi, err := svc.CreateUser(ctx, todo.User{Name: "abidi"})
+	require.NoError(err, "could not create user")
+
+	_, err = svc.Save(ctx, todo.Task{Name: "Bijli ka bill bharo", AssigneeID: 42})
+	assert.Equal(todo.ErrAssigneeNotFound, err)
+	bill, err := svc.Save(ctx, todo.Task{Name: "Bijli ka bill bharo", AssigneeID: jarri.ID})
+	require.NoError(err, "could not save task")
+	gas, err := svc.Save(ctx, todo.Task{Name: "Gas ka bill bharo"})
+	require.NoError(err, "could not save task")
+
+	gas, err = svc.Assign(ctx, gas.ID, abidi.ID)
+	require.NoError(err, "could not assign task")
+	assert.Equal(abidi.ID, gas.AssigneeID)
+	_, err = svc.Assign(ctx, gas.ID, 42)
+	assert.Equal(todo.ErrAssigneeNotFound, err)
+
+	mine, _, err := svc.ListMyTasks(ctx, todo.TaskQuery{})
+	require.NoError(err, "could not list my tasks")
+	require.Len(mine, 1)
+	assert.Equal(bill.ID, mine[0].ID)
+
+	gas, err = svc.Assign(ctx, gas.ID, jarri.ID)
+	require.NoError(err, "could not reassign task")
+	mine, _, err = svc.ListMyTasks(ctx, todo.TaskQuery{})
+	require.NoError(err, "could not list my tasks")
+	assert.Len(mine, 2)
+
+	bill, err = svc.Unassign(ctx, bill.ID)
+	require.NoError(err, "could not unassign task")
+	assert.Zero(bill.AssigneeID)
+	mine, _, err = svc.ListMyTasks(ctx, todo.TaskQuery{})
+	require.NoError(err, "could not list my tasks")
+	require.Len(mine, 1)
+	assert.Equal(gas.ID, mine[0].ID)
+
+	_, _, err = svc.ListMyTasks(context.TODO(), todo.TaskQuery{})
+	assert.Equal(todo.ErrUserNotFound, err, "expected anonymous actor not to be a user")
+}
+
+func TestTimeTracking(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(),
+			checklist.WithClock(func() time.Time { return now }))
+		ctx = todo.ContextWithActor(context.TODO(), "jarri")
+	)
+
+	list, err := svc.CreateChecklist(ctx, todo.Checklist{Name: "Ghar"})
+	require.NoError(err, "could not create checklist")
+	paint, err := svc.SaveToChecklist(ctx, list.ID, todo.Task{Name: "Deewar rang karo", Estimate: 3 * time.Hour})
+	require.NoError(err, "could not save task")
+	fix, err := svc.SaveToChecklist(ctx, list.ID, todo.Task{Name: "Nalka theek karo", Estimate: time.Hour})
+	require.NoError(err, "could not save task")
+	_, err = svc.Save(ctx, todo.Task{Name: "Kuch bhi", Estimate: -time.Hour})
+	assert.Equal(todo.ErrInvalidEstimate, err)
+
+	_, err = svc.StartTimer(ctx, paint.ID)
+	require.NoError(err, "could not start timer")
+	_, err = svc.StartTimer(ctx, fix.ID)
+	assert.Equal(todo.ErrTimerRunning, err, "expected only one timer per user")
+	_, err = svc.StopTimer(ctx, fix.ID)
+	assert.Equal(todo.ErrNoTimerRunning, err)
+	_, err = svc.StartTimer(todo.ContextWithActor(ctx, "abidi"), fix.ID)
+	require.NoError(err, "expected another user to be able to start a timer")
+
+	now = now.Add(90 * time.Minute)
+	entry, err := svc.StopTimer(ctx, paint.ID)
+	require.NoError(err, "could not stop timer")
+	assert.Equal(90*time.Minute, entry.Duration(now))
+
+	start := now.Add(-time.Hour)
+	_, err = svc.AddTimeEntry(ctx, paint.ID, todo.TimeEntry{Start: start, End: &start})
+	assert.Equal(todo.ErrInvalidTimeEntry, err)
+	end := start.Add(30 * time.Minute)
+	manual, err := svc.AddTimeEntry(ctx, paint.ID, todo.TimeEntry{Start: start, End: &end})
+	require.NoError(err, "could not add time entry")
+
+	summary, err := svc.SummarizeTime(ctx, paint.ID)
+	require.NoError(err, "could not summarize time")
+	assert.Equal(todo.TimeSummary{Estimate: 3 * time.Hour, Spent: 2 * time.Hour}, summary)
+
+	summary, err = svc.SummarizeChecklistTime(ctx, list.ID)
+	require.NoError(err, "could not summarize checklist time")
+	assert.Equal(todo.TimeSummary{Estimate: 4 * time.Hour, Spent: 3*time.Hour + 30*time.Minute}, summary, "expected running timers to count up to now")
+
+	require.NoError(svc.RemoveTimeEntry(ctx, paint.ID, manual.ID), "could not remove time entry")
+	assert.Equal(todo.ErrTimeEntryNotFound, svc.RemoveTimeEntry(ctx, fix.ID, entry.ID))
+	entries, err := svc.ListTimeEntries(ctx, paint.ID)
+	require.NoError(err, "could not list time entries")
+	assert.Len(entries, 1)
+}
+
+func TestTemplates(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore())
+		ctx     = context.TODO()
+		day     = 24 * time.Hour
+		before  = -day
+	)
+
+	_, err := svc.CreateTemplate(ctx, todo.Template{Name: "Release"})
+	assert.Equal(todo.ErrEmptyTemplate, err)
+	_, err = svc.CreateTemplate(ctx, todo.Template{Name: "Release", Tasks: []todo.TemplateTask{{Name: "Tag karo", Tags: []string{"a,b"}}}})
+	assert.Equal(todo.ErrInvalidTag, err)
+
+	release, err := svc.CreateTemplate(ctx, todo.Template{Name: "Release", Tasks: []todo.TemplateTask{
+		{Name: "Changelog likho", Tags: []string{"Docs"}, DueOffset: &before},
+		{Name: "Tag karo", Priority: todo.PriorityHigh, Estimate: time.Hour},
+		{Name: "Announce karo", DueOffset: &day},
+	}})
+	require.NoError(err, "could not create template")
+	assert.Equal(todo.PriorityNormal, release.Tasks[0].Priority)
+	assert.Equal([]string{"docs"}, release.Tasks[0].Tags)
+
+	list, err := svc.CreateChecklist(ctx, todo.Checklist{Name: "v1.0"})
+	require.NoError(err, "could not create checklist")
+	existing, err := svc.Save(ctx, todo.Task{Name: "Bugs theek karo"})
+	require.NoError(err, "could not save task")
+
+	_, err = svc.InstantiateTemplate(ctx, 42, list.ID, time.Time{})
+	assert.Equal(todo.ErrTemplateNotFound, err)
+	_, err = svc.InstantiateTemplate(ctx, release.ID, 42, time.Time{})
+	assert.Equal(todo.ErrChecklistNotFound, err)
+	all, err := svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	assert.Len(all, 1, "expected failed instantiations to create no tasks")
+
+	start := time.Date(2021, time.June, 10, 9, 0, 0, 0, time.UTC)
+	tasks, err := svc.InstantiateTemplate(ctx, release.ID, list.ID, start)
+	require.NoError(err, "could not instantiate template")
+	require.Len(tasks, 3)
+	for _, task := range tasks {
+		assert.Equal(list.ID, task.ChecklistID)
+		assert.NotZero(task.ID)
+	}
+	assert.Equal(start.Add(-day), *tasks[0].DueDate)
+	assert.Nil(tasks[1].DueDate)
+	assert.Equal(time.Hour, tasks[1].Estimate)
+	assert.Equal(start.Add(day), *tasks[2].DueDate)
+
+	positions, _, err := svc.ListPage(ctx, todo.TaskQuery{SortBy: todo.SortByPosition})
+	require.NoError(err, "could not list tasks by position")
+	require.Len(positions, 4)
+	assert.Equal([]int64{existing.ID, tasks[0].ID, tasks[1].ID, tasks[2].ID},
+		[]int64{positions[0].ID, positions[1].ID, positions[2].ID, positions[3].ID},
+		"expected instantiated tasks after existing ones in template order")
+
+	release.Tasks = release.Tasks[:1]
+	_, err = svc.UpdateTemplate(ctx, *release)
+	require.NoError(err, "could not update template")
+	tasks, err = svc.InstantiateTemplate(ctx, release.ID, 0, start)
+	require.NoError(err, "could not instantiate updated template")
+	assert.Len(tasks, 1)
+
+	require.NoError(svc.RemoveTemplate(ctx, release.ID), "could not remove template")
+	assert.Equal(todo.ErrTemplateNotFound, svc.RemoveTemplate(ctx, release.ID))
+}
+
+func TestArchive(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+		svc     = checklist.NewService(inmem.NewRepositories(), inmem.NewBlobStore(),
+			checklist.WithClock(func() time.Time { return now }), checklist.WithArchiveAfter(7*24*time.Hour))
+		ctx        = context.TODO()
+		unarchived = false
+	)
+
+	bill, err := svc.Save(ctx, todo.Task{Name: "Bijli ka bill bharo"})
+	require.NoError(err, "could not save task")
+	gas, err := svc.Save(ctx, todo.Task{Name: "Gas ka bill bharo"})
+	require.NoError(err, "could not save task")
+	laundry, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+	require.NoError(svc.ToggleDone(ctx, bill.ID, 0), "could not toggle task")
+	require.NoError(svc.ToggleDone(ctx, gas.ID, 0), "could not toggle task")
+
+	now = now.Add(7 * 24 * time.Hour)
+	archived, err := svc.ArchiveCompleted(ctx)
+	require.NoError(err, "could not archive tasks")
+	assert.Equal(int64(0), archived, "expected tasks done for exactly the archive period to be kept")
+
+	now = now.Add(time.Second)
+	archived, err = svc.ArchiveCompleted(ctx)
+	require.NoError(err, "could not archive tasks")
+	assert.Equal(int64(2), archived)
+
+	list, _, err := svc.ListPage(ctx, todo.TaskQuery{Archived: &unarchived})
+	require.NoError(err, "could not list tasks")
+	require.Len(list, 1)
+	assert.Equal(laundry.ID, list[0].ID)
+	all, err := svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	assert.Len(all, 3, "expected the zero query to include archived tasks")
+
+	gas, _, err = svc.Update(ctx, todo.Task{ID: gas.ID, Name: "Sui gas ka bill bharo", Done: true})
+	require.NoError(err, "could not update task")
+	assert.Equal(now, *gas.ArchivedAt, "expected task that stays done to stay archived")
+	require.NoError(svc.ToggleDone(ctx, bill.ID, 0), "could not toggle task")
+	bill, err = svc.Get(ctx, bill.ID)
+	require.NoError(err, "could not get task")
+	assert.Nil(bill.ArchivedAt, "expected reopened task to leave the archive")
+
+	archived, err = svc.ArchiveCompleted(ctx)
+	require.NoError(err, "could not archive tasks")
+	assert.Equal(int64(0), archived, "expected archived tasks not to be archived again")
+
+	require.NoError(svc.ToggleDone(ctx, laundry.ID, 0), "could not toggle task")
+	now = now.Add(8 * 24 * time.Hour)
+	archiver := checklist.NewArchiver(svc, time.Hour)
+	archiver.Start()
+	archiver.Stop()
+	laundry, err = svc.Get(ctx, laundry.ID)
+	require.NoError(err, "could not get task")
+	assert.NotNil(laundry.ArchivedAt, "expected archiver to archive tasks as soon as it starts")
+}
+
+// notifier records the notifications it's asked to deliver, failing them while err is set.
+type notifier struct {
+	sent []todo.Notification
+	err  error
+}
+
+func (n *notifier) Notify(_ context.Context, notification todo.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func TestReminders(t *testing.T) {
+	var (
+		require   = require.New(t)
+		assert    = assert.New(t)
+		now       = time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+		r         = inmem.NewRepositories()
+		reminders = r.Reminders
+		svc       = checklist.NewService(r, inmem.NewBlobStore(),
+			checklist.WithClock(func() time.Time { return now }))
+		ctx    = context.TODO()
+		due    = now.Add(24 * time.Hour)
+		at     = now.Add(time.Hour)
+		before = 2 * time.Hour
+		n      = &notifier{}
+	)
+
+	bill, err := svc.Save(ctx, todo.Task{Name: "Bijli ka bill bharo", DueDate: &due})
+	require.NoError(err, "could not save task")
+	laundry, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+
+	_, err = svc.AddReminder(ctx, bill.ID, todo.Reminder{})
+	assert.Equal(todo.ErrInvalidReminder, err, "expected reminder without a time to be rejected")
+	_, err = svc.AddReminder(ctx, bill.ID, todo.Reminder{At: &at, Before: &before})
+	assert.Equal(todo.ErrInvalidReminder, err, "expected reminder with both a time and a duration to be rejected")
+	_, err = svc.AddReminder(ctx, laundry.ID, todo.Reminder{Before: &before})
+	assert.Equal(todo.ErrTaskNotDue, err)
+
+	fixed, err := svc.AddReminder(ctx, laundry.ID, todo.Reminder{At: &at})
+	require.NoError(err, "could not add reminder")
+	relative, err := svc.AddReminder(ctx, bill.ID, todo.Reminder{Before: &before})
+	require.NoError(err, "could not add reminder")
+	list, err := svc.ListReminders(ctx, bill.ID)
+	require.NoError(err, "could not list reminders")
+	assert.Equal([]todo.Reminder{*relative}, list)
+
+	sent, err := svc.SendDueReminders(ctx, n)
+	require.NoError(err, "could not send reminders")
+	assert.Equal(0, sent, "expected reminders that aren't due yet to be held back")
+
+	now = at
+	n.err = errors.New("mail server is down")
+	sent, err = svc.SendDueReminders(ctx, n)
+	assert.Error(err, "expected failed delivery to be reported")
+	assert.Equal(0, sent)
+	pending, err := reminders.FindByID(ctx, fixed.ID)
+	require.NoError(err, "could not find reminder")
+	assert.Nil(pending.SentAt, "expected reminder that failed to stay pending")
+	assert.Equal(1, pending.Attempts)
+	assert.Equal("mail server is down", pending.LastError)
+
+	n.err = nil
+	sent, err = svc.SendDueReminders(ctx, n)
+	require.NoError(err, "could not send reminders")
+	assert.Equal(1, sent)
+	require.Len(n.sent, 1)
+	assert.Equal(laundry.ID, n.sent[0].Task.ID)
+	delivered, err := reminders.FindByID(ctx, fixed.ID)
+	require.NoError(err, "could not find reminder")
+	assert.Equal(now, *delivered.SentAt)
+	assert.Equal(2, delivered.Attempts)
+	assert.Empty(delivered.LastError)
+
+	// moving the due date moves the reminders relative to it
+	due = now.Add(time.Hour)
+	_, _, err = svc.Update(ctx, todo.Task{ID: bill.ID, Name: bill.Name, DueDate: &due})
+	require.NoError(err, "could not update task")
+	sent, err = svc.SendDueReminders(ctx, n)
+	require.NoError(err, "could not send reminders")
+	assert.Equal(1, sent)
+	sent, err = svc.SendDueReminders(ctx, n)
+	require.NoError(err, "could not send reminders")
+	assert.Equal(0, sent, "expected reminders to be sent only once")
+
+	_, err = svc.AddReminder(ctx, laundry.ID, todo.Reminder{At: &at})
+	require.NoError(err, "could not add reminder")
+	require.NoError(svc.ToggleDone(ctx, laundry.ID, 0), "could not toggle task")
+	sent, err = svc.SendDueReminders(ctx, n)
+	require.NoError(err, "could not send reminders")
+	assert.Equal(0, sent, "expected reminders of done tasks to be held back")
+
+	assert.Equal(todo.ErrReminderNotFound, svc.RemoveReminder(ctx, bill.ID, fixed.ID), "expected reminder of another task not to be found")
+	require.NoError(svc.RemoveReminder(ctx, laundry.ID, fixed.ID), "could not remove reminder")
+	list, err = svc.ListReminders(ctx, laundry.ID)
+	require.NoError(err, "could not list reminders")
+	assert.Len(list, 1)
+
+	_, err = svc.AddReminder(ctx, bill.ID, todo.Reminder{At: &now})
+	require.NoError(err, "could not add reminder")
+	scheduler := checklist.NewReminderScheduler(svc, n, time.Hour)
+	scheduler.Start()
+	scheduler.Stop()
+	assert.Len(n.sent, 3, "expected scheduler to send due reminders as soon as it starts")
+}
+
+func TestAttachments(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		now     = time.Date(2021, time.June, 1, 9, 0, 0, 0, time.UTC)
+		blobs   = inmem.NewBlobStore()
+		svc     = checklist.NewService(inmem.NewRepositories(), blobs,
+			checklist.WithClock(func() time.Time { return now }), checklist.WithMaxAttachmentSize(16), checklist.WithTrashRetention(0))
+		ctx = todo.ContextWithActor(context.TODO(), "jarri")
+		png = "\x89PNG\r\n\x1a\n"
+	)
+
+	task, err := svc.Save(ctx, todo.Task{Name: "Login ka bug theek karo"})
+	require.NoError(err, "could not save task")
+
+	screenshot, err := svc.AddAttachment(ctx, task.ID, todo.Attachment{Name: `C:\Users\jarri\screenshot.png`}, strings.NewReader(png))
+	require.NoError(err, "could not add attachment")
+	assert.Equal(todo.Attachment{
+		ID:          screenshot.ID,
+		TaskID:      task.ID,
+		Name:        "screenshot.png",
+		ContentType: "image/png",
+		Size:        int64(len(png)),
+		Key:         screenshot.Key,
+		UploadedBy:  "jarri",
+		CreatedAt:   now,
+	}, *screenshot)
+
+	logs, err := svc.AddAttachment(ctx, task.ID, todo.Attachment{Name: "server.log"}, strings.NewReader("panic: nil map!"))
+	require.NoError(err, "could not add attachment")
+	assert.Equal("text/plain; charset=utf-8", logs.ContentType, "expected type to be told from the content")
+
+	_, err = svc.AddAttachment(ctx, task.ID, todo.Attachment{Name: "server.log"}, strings.NewReader("panic: nil map!!!"))
+	assert.Equal(todo.ErrAttachmentTooLarge, err)
+	_, err = svc.AddAttachment(ctx, task.ID, todo.Attachment{Name: "page.html"}, strings.NewReader("<html></html>"))
+	assert.Equal(todo.ErrUnsupportedContentType, err)
+	_, err = svc.AddAttachment(ctx, task.ID, todo.Attachment{Name: " "}, strings.NewReader(png))
+	assert.Equal(todo.ErrInvalidAttachmentName, err)
+
+	list, err := svc.ListAttachments(ctx, task.ID)
+	require.NoError(err, "could not list attachments")
+	assert.Equal([]todo.Attachment{*screenshot, *logs}, list, "expected rejected attachments not to be kept")
+
+	found, content, err := svc.OpenAttachment(ctx, task.ID, logs.ID)
+	require.NoError(err, "could not open attachment")
+	data, err := ioutil.ReadAll(content)
+	require.NoError(err, "could not read attachment")
+	require.NoError(content.Close())
+	assert.Equal(*logs, *found)
+	assert.Equal("panic: nil map!", string(data))
+
+	other, err := svc.Save(ctx, todo.Task{Name: "Kapre dho"})
+	require.NoError(err, "could not save task")
+	assert.Equal(todo.ErrAttachmentNotFound, svc.RemoveAttachment(ctx, other.ID, logs.ID), "expected attachment of another task not to be found")
+	require.NoError(svc.RemoveAttachment(ctx, task.ID, logs.ID), "could not remove attachment")
+	_, err = blobs.Get(ctx, logs.Key)
+	assert.Equal(todo.ErrBlobNotFound, err, "expected content of removed attachment to be deleted")
+
+	require.NoError(svc.Remove(ctx, task.ID, 0), "could not remove task")
+	now = now.Add(time.Minute)
+	_, err = svc.PurgeTrash(ctx)
+	require.NoError(err, "could not purge trash")
+	_, err = blobs.Get(ctx, screenshot.Key)
+	assert.Equal(todo.ErrBlobNotFound, err, "expected content of purged task's attachments to be deleted")
+}
+
+func TestBatch(t *testing.T) {
+	var (
+		require    = require.New(t)
+		assert     = assert.New(t)
+		r          = inmem.NewRepositories()
+		unitOfWork = inmem.NewUnitOfWork(r)
+		svc        = checklist.NewService(r, inmem.NewBlobStore(),
+			checklist.WithUnitOfWork(unitOfWork))
+		ctx = context.TODO()
+	)
+
+	existing, err := svc.Save(ctx, todo.Task{Name: "Doodh le aao"})
+	require.NoError(err, "could not save task")
+
+	_, err = svc.Batch(ctx, nil, true)
+	assert.Equal(todo.ErrInvalidBatchSize, err)
+	_, err = svc.Batch(ctx, make([]todo.BatchOperation, todo.MaxBatchSize+1), true)
+	assert.Equal(todo.ErrInvalidBatchSize, err)
+
+	// without a unit of work the operations before a failed one couldn't be rolled back
+	_, err = checklist.NewService(r, inmem.NewBlobStore()).Batch(ctx, []todo.BatchOperation{
+		{Op: todo.BatchCreate, Task: todo.Task{Name: "Bijli ka bill bharo"}},
+	}, true)
+	assert.Equal(todo.ErrBatchNotAtomic, err)
+
+	results, err := svc.Batch(ctx, []todo.BatchOperation{
+		{Op: todo.BatchCreate, Task: todo.Task{Name: "Bijli ka bill bharo"}},
+		{Op: todo.BatchToggle, ID: existing.ID},
+		{Op: todo.BatchDelete, ID: 404},
+		{Op: todo.BatchCreate, Task: todo.Task{Name: "Gaari dhulwao"}},
+	}, true)
+	require.NoError(err, "could not run batch")
+	assert.Equal([]todo.BatchResult{
+		{Err: todo.ErrBatchRolledBack},
+		{Err: todo.ErrBatchRolledBack},
+		{Err: todo.ErrTaskNotFound},
+		{Err: todo.ErrBatchSkipped},
+	}, results)
+
+	list, err := svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	assert.Equal([]todo.Task{*existing}, list, "expected changes of failed batch to be rolled back")
+	entries, _, err := svc.ListHistory(ctx, existing.ID, 0, 10)
+	require.NoError(err, "could not list history")
+	assert.Len(entries, 1, "expected history of failed batch to be rolled back")
+
+	results, err = svc.Batch(ctx, []todo.BatchOperation{
+		{Op: todo.BatchCreate, Task: todo.Task{Name: "Bijli ka bill bharo"}},
+		{Op: todo.BatchToggle, ID: existing.ID},
+		{Op: todo.BatchDelete, ID: 404},
+		{Op: "archive", ID: existing.ID},
+		{Op: todo.BatchUpdate, Task: todo.Task{ID: 7, Name: "Gaari dhulwao"}},
+	}, false)
+	require.NoError(err, "could not run batch")
+	require.Len(results, 5)
+	require.NoError(results[0].Err)
+	assert.Equal("Bijli ka bill bharo", results[0].Task.Name)
+	require.NoError(results[1].Err)
+	assert.True(results[1].Task.Done, "expected toggled task to be returned")
+	assert.Equal(todo.ErrTaskNotFound, results[2].Err)
+	assert.Equal(todo.ErrInvalidBatchOp, results[3].Err)
+	require.NoError(results[4].Err)
+	assert.True(results[4].Created, "expected update of missing task to create it")
+
+	list, err = svc.List(ctx)
+	require.NoError(err, "could not list tasks")
+	assert.Len(list, 3, "expected successful operations to be kept")
+}
+
+// failingHistory is a history repository that fails to record anything while fail is set.
+type failingHistory struct {
+	todo.HistoryRepository
+	fail bool
+}
+
+func (h *failingHistory) Insert(ctx context.Context, entry *todo.HistoryEntry) error {
+	if h.fail {
+		return errors.New("disk full")
+	}
+	return h.HistoryRepository.Insert(ctx, entry)
+}
+
+func TestUnitOfWork(t *testing.T) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+		history = &failingHistory{HistoryRepository: inmem.NewHistoryRepository()}
+		r       = todo.Repositories{
+			Tasks:        inmem.NewTaskRepository(),
+			Checklists:   inmem.NewChecklistRepository(),
+			History:      history,
+			Comments:     inmem.NewCommentRepository(),
+			Dependencies: inmem.NewDependencyRepository(),
+			Users:        inmem.NewUserRepository(),
+			TimeEntries:  inmem.NewTimeEntryRepository(),
+			Templates:    inmem.NewTemplateRepository(),
+			Reminders:    inmem.NewReminderRepository(),
+			Attachments:  inmem.NewAttachmentRepository(),
+		}
+		unitOfWork = inmem.NewUnitOfWork(r)
+		svc        = checklist.NewService(r, inmem.NewBlobStore(),
+			checklist.WithUnitOfWork(unitOfWork))
+		ctx = context.TODO()
+	)
+
+	task, err := svc.Save(ctx, todo.Task{Name: "Paudon ko paani do"})
+	require.NoError(err, "could not save task")
+
+	history.fail = true
+	assert.Error(svc.ToggleDone(ctx, task.ID, 0))
+	_, _, err = svc.Update(ctx, todo.Task{ID: 7, Name: "Gamle badlo"})
+	assert.Error(err)
+	history.fail = false
+
+	found, err := svc.Get(ctx, task.ID)
+	require.NoError(err, "could not get task")
+	assert.False(found.Done, "expected toggle to be rolled back when its history couldn't be recorded")
+	_, err = svc.Get(ctx, 7)
+	assert.Equal(todo.ErrTaskNotFound, err, "expected creation to be rolled back when its history couldn't be recorded")
+
+	// every toggle reads the task and writes it back, which only works out if none overlaps another
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- svc.ToggleDone(ctx, task.ID, 0)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(err, "expected concurrent toggles not to conflict")
+	}
+
+	found, err = svc.Get(ctx, task.ID)
+	require.NoError(err, "could not get task")
+	assert.False(found.Done, "expected an even number of toggles to leave the task pending")
+	entries, _, err := svc.ListHistory(ctx, task.ID, 0, 0)
+	require.NoError(err, "could not list history")
+	assert.Len(entries, 21, "expected every toggle to be recorded")
+
+	// a change made outside of a unit of work while it runs must survive the unit failing
+	var (
+		created *todo.Checklist
+		done    = make(chan error)
+	)
+	err = unitOfWork.Do(ctx, func(tx todo.Repositories) error {
+		go func() {
+			var err error
+			created, err = svc.CreateChecklist(ctx, todo.Checklist{Name: "Baghbani"})
+			done <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		if err := tx.Tasks.Insert(ctx, &todo.Task{ID: 8, Name: "Khaad dalo"}); err != nil {
+			return err
+		}
+		return errors.New("unit of work failed")
+	})
+	assert.Error(err)
+	require.NoError(<-done, "could not create checklist")
+
+	_, err = svc.GetChecklist(ctx, created.ID)
+	assert.NoError(err, "expected checklist created outside of the failed unit of work to be kept")
+	_, err = svc.Get(ctx, 8)
+	assert.Equal(todo.ErrTaskNotFound, err, "expected task inserted in the failed unit of work to be rolled back")
+}
@@ -0,0 +1,104 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type attachmentRepository struct {
+	guard
+	*attachmentState
+}
+
+type attachmentState struct {
+	attachments map[int64]todo.Attachment
+	counter     int64
+}
+
+// NewAttachmentRepository returns an in-memory implementation of todo.AttachmentRepository.
+func NewAttachmentRepository() todo.AttachmentRepository {
+	return &attachmentRepository{guard: newGuard(), attachmentState: &attachmentState{attachments: make(map[int64]todo.Attachment)}}
+}
+
+func (as *attachmentRepository) Insert(_ context.Context, attachment *todo.Attachment) error {
+	as.Lock()
+	defer as.Unlock()
+
+	as.counter++
+	attachment.ID = as.counter
+	as.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (as *attachmentRepository) FindAllByTaskIDs(_ context.Context, taskIDs []int64) ([]todo.Attachment, error) {
+	as.RLock()
+	defer as.RUnlock()
+
+	tasks := make(map[int64]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+	list := []todo.Attachment{}
+	for _, attachment := range as.attachments {
+		if tasks[attachment.TaskID] {
+			list = append(list, attachment)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (as *attachmentRepository) FindByID(_ context.Context, id int64) (*todo.Attachment, error) {
+	as.RLock()
+	defer as.RUnlock()
+
+	attachment, ok := as.attachments[id]
+	if !ok {
+		return nil, todo.ErrAttachmentNotFound
+	}
+	return &attachment, nil
+}
+
+func (as *attachmentRepository) DeleteByID(_ context.Context, id int64) error {
+	as.Lock()
+	defer as.Unlock()
+
+	if _, ok := as.attachments[id]; !ok {
+		return todo.ErrAttachmentNotFound
+	}
+	delete(as.attachments, id)
+	return nil
+}
+
+func (as *attachmentRepository) DeleteAllByTaskIDs(_ context.Context, taskIDs []int64) error {
+	as.Lock()
+	defer as.Unlock()
+
+	tasks := make(map[int64]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+	for id, attachment := range as.attachments {
+		if tasks[attachment.TaskID] {
+			delete(as.attachments, id)
+		}
+	}
+	return nil
+}
+
+func (as *attachmentRepository) snapshot() func() {
+	saved := *as.attachmentState
+	saved.attachments = make(map[int64]todo.Attachment, len(as.attachments))
+	for k, v := range as.attachments {
+		saved.attachments[k] = v
+	}
+	return func() { *as.attachmentState = saved }
+}
+
+func (as *attachmentRepository) inUnit(changed func()) interface{} {
+	view := *as
+	view.changed = changed
+	return &view
+}
@@ -0,0 +1,53 @@
+package inmem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type blobStore struct {
+	sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewBlobStore returns an in-memory implementation of todo.BlobStore.
+func NewBlobStore() todo.BlobStore {
+	return &blobStore{blobs: make(map[string][]byte)}
+}
+
+func (bs *blobStore) Put(_ context.Context, key string, r io.Reader, _ string) (int64, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	bs.Lock()
+	defer bs.Unlock()
+
+	bs.blobs[key] = data
+	return int64(len(data)), nil
+}
+
+func (bs *blobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	bs.RLock()
+	defer bs.RUnlock()
+
+	data, ok := bs.blobs[key]
+	if !ok {
+		return nil, todo.ErrBlobNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (bs *blobStore) Delete(_ context.Context, key string) error {
+	bs.Lock()
+	defer bs.Unlock()
+
+	delete(bs.blobs, key)
+	return nil
+}
@@ -0,0 +1,93 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type checklistRepository struct {
+	guard
+	*checklistState
+}
+
+type checklistState struct {
+	checklists map[int64]todo.Checklist
+	counter    int64
+}
+
+// NewChecklistRepository returns an in-memory implementation of todo.ChecklistRepository.
+func NewChecklistRepository() todo.ChecklistRepository {
+	return &checklistRepository{guard: newGuard(), checklistState: &checklistState{checklists: make(map[int64]todo.Checklist)}}
+}
+
+func (cs *checklistRepository) Insert(_ context.Context, checklist *todo.Checklist) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cs.counter++
+	checklist.ID = cs.counter
+	cs.checklists[checklist.ID] = *checklist
+	return nil
+}
+
+func (cs *checklistRepository) FindAll(_ context.Context) ([]todo.Checklist, error) {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	list := make([]todo.Checklist, 0, len(cs.checklists))
+	for _, checklist := range cs.checklists {
+		list = append(list, checklist)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (cs *checklistRepository) FindByID(_ context.Context, id int64) (*todo.Checklist, error) {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	checklist, ok := cs.checklists[id]
+	if !ok {
+		return nil, todo.ErrChecklistNotFound
+	}
+	return &checklist, nil
+}
+
+func (cs *checklistRepository) Update(_ context.Context, checklist *todo.Checklist) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if _, ok := cs.checklists[checklist.ID]; !ok {
+		return todo.ErrChecklistNotFound
+	}
+	cs.checklists[checklist.ID] = *checklist
+	return nil
+}
+
+func (cs *checklistRepository) DeleteByID(_ context.Context, id int64) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if _, ok := cs.checklists[id]; !ok {
+		return todo.ErrChecklistNotFound
+	}
+	delete(cs.checklists, id)
+	return nil
+}
+
+func (cs *checklistRepository) snapshot() func() {
+	saved := *cs.checklistState
+	saved.checklists = make(map[int64]todo.Checklist, len(cs.checklists))
+	for k, v := range cs.checklists {
+		saved.checklists[k] = v
+	}
+	return func() { *cs.checklistState = saved }
+}
+
+func (cs *checklistRepository) inUnit(changed func()) interface{} {
+	view := *cs
+	view.changed = changed
+	return &view
+}
@@ -0,0 +1,114 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type commentRepository struct {
+	guard
+	*commentState
+}
+
+type commentState struct {
+	comments map[int64]todo.Comment
+	counter  int64
+}
+
+// NewCommentRepository returns an in-memory implementation of todo.CommentRepository.
+func NewCommentRepository() todo.CommentRepository {
+	return &commentRepository{guard: newGuard(), commentState: &commentState{comments: make(map[int64]todo.Comment)}}
+}
+
+func (cs *commentRepository) Insert(_ context.Context, comment *todo.Comment) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	cs.counter++
+	comment.ID = cs.counter
+	cs.comments[comment.ID] = *comment
+	return nil
+}
+
+func (cs *commentRepository) FindAllByTaskID(_ context.Context, taskID int64) ([]todo.Comment, error) {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	list := []todo.Comment{}
+	for _, comment := range cs.comments {
+		if comment.TaskID == taskID {
+			list = append(list, comment)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (cs *commentRepository) FindByID(_ context.Context, id int64) (*todo.Comment, error) {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	comment, ok := cs.comments[id]
+	if !ok {
+		return nil, todo.ErrCommentNotFound
+	}
+	return &comment, nil
+}
+
+func (cs *commentRepository) Update(_ context.Context, comment *todo.Comment) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	stored, ok := cs.comments[comment.ID]
+	if !ok {
+		return todo.ErrCommentNotFound
+	}
+	stored.Body, stored.UpdatedAt = comment.Body, comment.UpdatedAt
+	cs.comments[comment.ID] = stored
+	*comment = stored
+	return nil
+}
+
+func (cs *commentRepository) DeleteByID(_ context.Context, id int64) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if _, ok := cs.comments[id]; !ok {
+		return todo.ErrCommentNotFound
+	}
+	delete(cs.comments, id)
+	return nil
+}
+
+func (cs *commentRepository) DeleteAllByTaskIDs(_ context.Context, taskIDs []int64) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	tasks := make(map[int64]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+	for id, comment := range cs.comments {
+		if tasks[comment.TaskID] {
+			delete(cs.comments, id)
+		}
+	}
+	return nil
+}
+
+func (cs *commentRepository) snapshot() func() {
+	saved := *cs.commentState
+	saved.comments = make(map[int64]todo.Comment, len(cs.comments))
+	for k, v := range cs.comments {
+		saved.comments[k] = v
+	}
+	return func() { *cs.commentState = saved }
+}
+
+func (cs *commentRepository) inUnit(changed func()) interface{} {
+	view := *cs
+	view.changed = changed
+	return &view
+}
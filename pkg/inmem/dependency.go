@@ -0,0 +1,100 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type dependencyRepository struct {
+	guard
+	*dependencyState
+}
+
+type dependencyState struct {
+	dependencies map[todo.Dependency]bool
+}
+
+// NewDependencyRepository returns an in-memory implementation of todo.DependencyRepository.
+func NewDependencyRepository() todo.DependencyRepository {
+	return &dependencyRepository{guard: newGuard(), dependencyState: &dependencyState{dependencies: make(map[todo.Dependency]bool)}}
+}
+
+func (ds *dependencyRepository) Insert(_ context.Context, dep todo.Dependency) error {
+	ds.Lock()
+	defer ds.Unlock()
+
+	ds.dependencies[dep] = true
+	return nil
+}
+
+func (ds *dependencyRepository) FindAll(_ context.Context) ([]todo.Dependency, error) {
+	return ds.filter(func(todo.Dependency) bool { return true }), nil
+}
+
+func (ds *dependencyRepository) FindAllByTaskID(_ context.Context, taskID int64) ([]todo.Dependency, error) {
+	return ds.filter(func(dep todo.Dependency) bool { return dep.TaskID == taskID }), nil
+}
+
+func (ds *dependencyRepository) Delete(_ context.Context, dep todo.Dependency) error {
+	ds.Lock()
+	defer ds.Unlock()
+
+	if !ds.dependencies[dep] {
+		return todo.ErrDependencyNotFound
+	}
+	delete(ds.dependencies, dep)
+	return nil
+}
+
+func (ds *dependencyRepository) DeleteAllByTaskIDs(_ context.Context, taskIDs []int64) error {
+	ds.Lock()
+	defer ds.Unlock()
+
+	tasks := make(map[int64]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+	for dep := range ds.dependencies {
+		if tasks[dep.TaskID] || tasks[dep.BlockerID] {
+			delete(ds.dependencies, dep)
+		}
+	}
+	return nil
+}
+
+// filter returns the dependencies matching keep, ordered by task and blocker.
+func (ds *dependencyRepository) filter(keep func(todo.Dependency) bool) []todo.Dependency {
+	ds.RLock()
+	defer ds.RUnlock()
+
+	list := []todo.Dependency{}
+	for dep := range ds.dependencies {
+		if keep(dep) {
+			list = append(list, dep)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].TaskID != list[j].TaskID {
+			return list[i].TaskID < list[j].TaskID
+		}
+		return list[i].BlockerID < list[j].BlockerID
+	})
+	return list
+}
+
+func (ds *dependencyRepository) snapshot() func() {
+	saved := *ds.dependencyState
+	saved.dependencies = make(map[todo.Dependency]bool, len(ds.dependencies))
+	for k, v := range ds.dependencies {
+		saved.dependencies[k] = v
+	}
+	return func() { *ds.dependencyState = saved }
+}
+
+func (ds *dependencyRepository) inUnit(changed func()) interface{} {
+	view := *ds
+	view.changed = changed
+	return &view
+}
@@ -0,0 +1,71 @@
+package inmem
+
+import (
+	"context"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type historyRepository struct {
+	guard
+	*historyState
+}
+
+type historyState struct {
+	entries []todo.HistoryEntry // ordered by ID
+}
+
+// NewHistoryRepository returns an in-memory implementation of todo.HistoryRepository.
+func NewHistoryRepository() todo.HistoryRepository {
+	return &historyRepository{guard: newGuard(), historyState: &historyState{}}
+}
+
+func (hs *historyRepository) Insert(_ context.Context, entry *todo.HistoryEntry) error {
+	hs.Lock()
+	defer hs.Unlock()
+
+	entry.ID = int64(len(hs.entries)) + 1
+	hs.entries = append(hs.entries, cloneEntry(*entry))
+	return nil
+}
+
+func (hs *historyRepository) FindAllByTaskID(_ context.Context, taskID, afterID int64, limit int) ([]todo.HistoryEntry, error) {
+	hs.RLock()
+	defer hs.RUnlock()
+
+	list := []todo.HistoryEntry{}
+	for _, entry := range hs.entries {
+		if limit > 0 && len(list) == limit {
+			break
+		}
+		if entry.TaskID == taskID && entry.ID > afterID {
+			list = append(list, cloneEntry(entry))
+		}
+	}
+	return list, nil
+}
+
+// cloneEntry copies an entry so that the recorded tasks can't be changed through it.
+func cloneEntry(entry todo.HistoryEntry) todo.HistoryEntry {
+	if entry.Before != nil {
+		before := clone(*entry.Before)
+		entry.Before = &before
+	}
+	if entry.After != nil {
+		after := clone(*entry.After)
+		entry.After = &after
+	}
+	return entry
+}
+
+func (hs *historyRepository) snapshot() func() {
+	// entries are only ever appended, so dropping those added since is enough to restore them
+	n := len(hs.entries)
+	return func() { hs.entries = hs.entries[:n] }
+}
+
+func (hs *historyRepository) inUnit(changed func()) interface{} {
+	view := *hs
+	view.changed = changed
+	return &view
+}
@@ -2,89 +2,413 @@ package inmem
 
 import (
 	"context"
-	"sync"
+	"sort"
+	"time"
 
 	"github.com/jarri-abidi/todo/pkg/todo"
 )
 
 type taskRepository struct {
-	sync.RWMutex
-	tasklist []todo.Task
-	used     map[int64]bool
-	counter  int64
+	guard
+	*taskState
+}
+
+type taskState struct {
+	checklists map[int64][]todo.Task // tasks keyed by the ID of the checklist they belong to
+	used       map[int64]bool
+	counter    int64
 }
 
 // NewTaskRepository returns an in-memory implementation of todo.TaskRepository.
 // This lets us run tests and start the app locally without a persistent database.
 func NewTaskRepository() todo.TaskRepository {
-	return &taskRepository{tasklist: []todo.Task{}, used: make(map[int64]bool)}
+	return &taskRepository{guard: newGuard(), taskState: &taskState{checklists: make(map[int64][]todo.Task), used: make(map[int64]bool)}}
 }
 
 func (ts *taskRepository) Insert(_ context.Context, task *todo.Task) error {
 	ts.Lock()
 	defer ts.Unlock()
 
-	if task.ID != 0 {
-		if ts.used[task.ID] {
+	if task.ID != 0 && ts.used[task.ID] {
+		return todo.ErrTaskAlreadyExists
+	}
+	ts.insert(task)
+	return nil
+}
+
+func (ts *taskRepository) InsertAll(_ context.Context, tasks []todo.Task) error {
+	ts.Lock()
+	defer ts.Unlock()
+
+	// check every task before inserting any so that a failure leaves nothing behind
+	ids := make(map[int64]bool, len(tasks))
+	for _, task := range tasks {
+		if task.ID != 0 && (ts.used[task.ID] || ids[task.ID]) {
 			return todo.ErrTaskAlreadyExists
 		}
-
-		ts.used[task.ID] = true
-		ts.tasklist = append(ts.tasklist, *task)
-		return nil
+		ids[task.ID] = true
+	}
+	for i := range tasks {
+		ts.insert(&tasks[i])
 	}
+	return nil
+}
 
-	ts.counter++
-	for ts.used[ts.counter] {
+// insert stores the task, assigning it the next free ID unless it already has one.
+func (ts *taskRepository) insert(task *todo.Task) {
+	if task.ID == 0 {
 		ts.counter++
+		for ts.used[ts.counter] {
+			ts.counter++
+		}
+		task.ID = ts.counter
 	}
 	ts.used[task.ID] = true
-	task.ID = ts.counter
-	ts.tasklist = append(ts.tasklist, *task)
-	return nil
+	task.Version = 1
+	ts.checklists[task.ChecklistID] = append(ts.checklists[task.ChecklistID], clone(*task))
 }
 
-func (ts *taskRepository) FindAll(_ context.Context) ([]todo.Task, error) {
+func (ts *taskRepository) FindAll(_ context.Context, query todo.TaskQuery) ([]todo.Task, error) {
+	list := ts.filter(func(task todo.Task) bool { return query.Matches(task) && query.IsAfterCursor(task) })
+
+	sort.Slice(list, func(i, j int) bool { return query.Less(list[i], list[j]) })
+	if query.Limit > 0 && len(list) > query.Limit {
+		list = list[:query.Limit]
+	}
+	return list, nil
+}
+
+func (ts *taskRepository) FindAllDueBetween(_ context.Context, after, before time.Time) ([]todo.Task, error) {
+	list := ts.filter(func(task todo.Task) bool {
+		if task.DueDate == nil {
+			return false
+		}
+		if !after.IsZero() && task.DueDate.Before(after) {
+			return false
+		}
+		return before.IsZero() || task.DueDate.Before(before)
+	})
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].DueDate.Before(*list[j].DueDate) })
+	return list, nil
+}
+
+func (ts *taskRepository) FindAllByTags(_ context.Context, tags []string, all bool) ([]todo.Task, error) {
+	return ts.filter(func(task todo.Task) bool { return task.HasTags(tags, all) }), nil
+}
+
+func (ts *taskRepository) FindAllTags(_ context.Context) ([]todo.Tag, error) {
 	ts.RLock()
 	defer ts.RUnlock()
 
-	return ts.tasklist, nil
+	counts := make(map[string]int)
+	for _, tasklist := range ts.checklists {
+		for _, task := range tasklist {
+			if task.DeletedAt != nil {
+				continue
+			}
+			for _, tag := range task.Tags {
+				counts[tag]++
+			}
+		}
+	}
+
+	tags := make([]todo.Tag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, todo.Tag{Name: name, TaskCount: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (ts *taskRepository) FindAllByChecklistID(_ context.Context, checklistID int64) ([]todo.Task, error) {
+	return sortByPosition(ts.filter(func(task todo.Task) bool { return task.ChecklistID == checklistID })), nil
+}
+
+func (ts *taskRepository) FindAllByParentID(_ context.Context, parentID int64) ([]todo.Task, error) {
+	return sortByPosition(ts.filter(func(task todo.Task) bool { return task.ParentID == parentID })), nil
 }
 
 func (ts *taskRepository) FindByID(_ context.Context, id int64) (*todo.Task, error) {
 	ts.RLock()
 	defer ts.RUnlock()
 
-	for i, task := range ts.tasklist {
-		if task.ID == id {
-			return &ts.tasklist[i], nil
-		}
+	checklistID, i, ok := ts.find(id)
+	if !ok || ts.checklists[checklistID][i].DeletedAt != nil {
+		return nil, todo.ErrTaskNotFound
 	}
-	return nil, todo.ErrTaskNotFound
+	task := clone(ts.checklists[checklistID][i])
+	return &task, nil
 }
 
 func (ts *taskRepository) Update(_ context.Context, task *todo.Task) error {
 	ts.Lock()
 	defer ts.Unlock()
 
-	for i, t := range ts.tasklist {
-		if t.ID == task.ID {
-			ts.tasklist[i] = *task
-			return nil
+	checklistID, i, ok := ts.find(task.ID)
+	if !ok || ts.checklists[checklistID][i].DeletedAt != nil {
+		return todo.ErrTaskNotFound
+	}
+	stored := ts.checklists[checklistID][i]
+	if task.Version != 0 && task.Version != stored.Version {
+		return todo.ErrVersionConflict
+	}
+	task.Version = stored.Version + 1
+
+	if checklistID == task.ChecklistID {
+		ts.checklists[checklistID][i] = clone(*task)
+		return nil
+	}
+
+	ts.remove(checklistID, i)
+	ts.checklists[task.ChecklistID] = append(ts.checklists[task.ChecklistID], clone(*task))
+	return nil
+}
+
+func (ts *taskRepository) DeleteByID(_ context.Context, id, version int64, at time.Time) error {
+	ts.Lock()
+	defer ts.Unlock()
+
+	checklistID, i, ok := ts.find(id)
+	if !ok || ts.checklists[checklistID][i].DeletedAt != nil {
+		return todo.ErrTaskNotFound
+	}
+	if version != 0 && version != ts.checklists[checklistID][i].Version {
+		return todo.ErrVersionConflict
+	}
+	ts.trashTree(id, at)
+	return nil
+}
+
+func (ts *taskRepository) DeleteAllByChecklistID(_ context.Context, checklistID int64) error {
+	ts.Lock()
+	defer ts.Unlock()
+
+	for _, task := range append([]todo.Task(nil), ts.checklists[checklistID]...) {
+		ts.deleteTree(task.ID)
+	}
+	delete(ts.checklists, checklistID)
+	return nil
+}
+
+func (ts *taskRepository) FindAllDeleted(_ context.Context) ([]todo.Task, error) {
+	ts.RLock()
+	defer ts.RUnlock()
+
+	list := []todo.Task{}
+	for _, tasklist := range ts.checklists {
+		for _, task := range tasklist {
+			if task.DeletedAt != nil {
+				list = append(list, clone(task))
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].DeletedAt.Equal(*list[j].DeletedAt) {
+			return list[i].DeletedAt.After(*list[j].DeletedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (ts *taskRepository) RestoreByID(_ context.Context, id int64) error {
+	ts.Lock()
+	defer ts.Unlock()
+
+	checklistID, i, ok := ts.find(id)
+	if !ok || ts.checklists[checklistID][i].DeletedAt == nil {
+		return todo.ErrTaskNotFound
+	}
+	task := ts.checklists[checklistID][i]
+	if task.ParentID != 0 {
+		if parentChecklistID, j, ok := ts.find(task.ParentID); ok && ts.checklists[parentChecklistID][j].DeletedAt != nil {
+			return todo.ErrParentTaskTrashed
+		}
+	}
+	ts.restoreTree(id, *task.DeletedAt)
+	return nil
+}
+
+func (ts *taskRepository) PurgeDeletedBefore(_ context.Context, before time.Time) (int64, error) {
+	ts.Lock()
+	defer ts.Unlock()
+
+	var expired []int64
+	for _, tasklist := range ts.checklists {
+		for _, task := range tasklist {
+			if task.DeletedAt != nil && task.DeletedAt.Before(before) {
+				expired = append(expired, task.ID)
+			}
 		}
 	}
-	return todo.ErrTaskNotFound
+	for _, id := range expired {
+		ts.deleteTree(id)
+	}
+	return int64(len(expired)), nil
 }
 
-func (ts *taskRepository) DeleteByID(_ context.Context, id int64) error {
+func (ts *taskRepository) ArchiveCompletedBefore(_ context.Context, before, at time.Time) (int64, error) {
 	ts.Lock()
 	defer ts.Unlock()
 
-	for i, task := range ts.tasklist {
-		if task.ID == id {
-			ts.tasklist = append(ts.tasklist[:i], ts.tasklist[i+1:]...)
-			return nil
+	var archived int64
+	ts.each(func(task *todo.Task) {
+		if task.Done && task.ArchivedAt == nil && task.DeletedAt == nil &&
+			task.CompletedAt != nil && task.CompletedAt.Before(before) {
+			archivedAt := at
+			task.ArchivedAt = &archivedAt
+			task.Version++
+			archived++
+		}
+	})
+	return archived, nil
+}
+
+// trashTree moves the task with the given id and, recursively, its subtasks that
+// aren't in the trash yet to the trash. It must be called with the write lock held.
+func (ts *taskRepository) trashTree(id int64, at time.Time) {
+	ts.each(func(task *todo.Task) {
+		if task.ID == id && task.DeletedAt == nil {
+			task.DeletedAt = &at
+			task.Version++
+		}
+	})
+	for _, subtaskID := range ts.subtaskIDs(id) {
+		ts.trashTree(subtaskID, at)
+	}
+}
+
+// restoreTree takes the task with the given id and, recursively, its subtasks that were
+// trashed at the same time out of the trash. It must be called with the write lock held.
+func (ts *taskRepository) restoreTree(id int64, at time.Time) {
+	restored := false
+	ts.each(func(task *todo.Task) {
+		if task.ID == id && task.DeletedAt != nil && task.DeletedAt.Equal(at) {
+			task.DeletedAt = nil
+			task.Version++
+			restored = true
+		}
+	})
+	if !restored {
+		return
+	}
+	for _, subtaskID := range ts.subtaskIDs(id) {
+		ts.restoreTree(subtaskID, at)
+	}
+}
+
+// each calls fn with a pointer to every stored task. It must be called with the write lock held.
+func (ts *taskRepository) each(fn func(*todo.Task)) {
+	for _, tasklist := range ts.checklists {
+		for i := range tasklist {
+			fn(&tasklist[i])
 		}
 	}
-	return todo.ErrTaskNotFound
+}
+
+// subtaskIDs returns the IDs of the direct subtasks of the given task, trashed or not.
+// It must be called with the lock held.
+func (ts *taskRepository) subtaskIDs(parentID int64) []int64 {
+	var ids []int64
+	for _, tasklist := range ts.checklists {
+		for _, task := range tasklist {
+			if task.ParentID == parentID {
+				ids = append(ids, task.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// deleteTree deletes the task with the given id and, recursively, all of its subtasks
+// just like the foreign key cascade does in postgres. It must be called with the write lock held.
+func (ts *taskRepository) deleteTree(id int64) {
+	if checklistID, i, ok := ts.find(id); ok {
+		ts.remove(checklistID, i)
+	}
+	for _, subtaskID := range ts.subtaskIDs(id) {
+		ts.deleteTree(subtaskID)
+	}
+}
+
+// find returns the checklist and index under which the task with the given id is stored.
+// It must be called with the lock held.
+func (ts *taskRepository) find(id int64) (checklistID int64, index int, ok bool) {
+	for checklistID, tasklist := range ts.checklists {
+		for i, task := range tasklist {
+			if task.ID == id {
+				return checklistID, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// remove deletes the task at index i of the given checklist.
+// It must be called with the write lock held.
+func (ts *taskRepository) remove(checklistID int64, i int) {
+	tasklist := ts.checklists[checklistID]
+	ts.checklists[checklistID] = append(tasklist[:i], tasklist[i+1:]...)
+}
+
+// filter returns copies of the tasks matching keep across all checklists,
+// leaving out those in the trash and ordered by todo.Task.Less.
+func (ts *taskRepository) filter(keep func(todo.Task) bool) []todo.Task {
+	ts.RLock()
+	defer ts.RUnlock()
+
+	list := []todo.Task{}
+	for _, tasklist := range ts.checklists {
+		for _, task := range tasklist {
+			if task.DeletedAt == nil && keep(task) {
+				list = append(list, clone(task))
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Less(list[j]) })
+	return list
+}
+
+// sortByPosition sorts tasks in the order the user arranged them, by rank.
+func sortByPosition(list []todo.Task) []todo.Task {
+	query := todo.TaskQuery{SortBy: todo.SortByPosition}
+	sort.Slice(list, func(i, j int) bool { return query.Less(list[i], list[j]) })
+	return list
+}
+
+// snapshot copies the tasks of every checklist and returns a func that restores them. Tasks
+// are changed in place within their checklist's slice but only ever have their fields
+// replaced, so copying the slices is enough.
+func (ts *taskRepository) snapshot() func() {
+	saved := *ts.taskState
+	saved.checklists = make(map[int64][]todo.Task, len(ts.checklists))
+	for id, tasks := range ts.checklists {
+		saved.checklists[id] = append([]todo.Task(nil), tasks...)
+	}
+	saved.used = make(map[int64]bool, len(ts.used))
+	for id := range ts.used {
+		saved.used[id] = true
+	}
+	return func() { *ts.taskState = saved }
+}
+
+func (ts *taskRepository) inUnit(changed func()) interface{} {
+	view := *ts
+	view.changed = changed
+	return &view
+}
+
+// clone copies a task so that callers never share slices or pointers with the repository.
+func clone(task todo.Task) todo.Task {
+	if task.Tags != nil {
+		task.Tags = append([]string(nil), task.Tags...)
+	}
+	if task.Recurrence != nil {
+		r := *task.Recurrence
+		r.ByDay = append([]time.Weekday(nil), r.ByDay...)
+		r.ByMonthDay = append([]int(nil), r.ByMonthDay...)
+		task.Recurrence = &r
+	}
+	return task
 }
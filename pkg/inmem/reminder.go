@@ -0,0 +1,123 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type reminderRepository struct {
+	guard
+	*reminderState
+}
+
+type reminderState struct {
+	reminders map[int64]todo.Reminder
+	counter   int64
+}
+
+// NewReminderRepository returns an in-memory implementation of todo.ReminderRepository.
+func NewReminderRepository() todo.ReminderRepository {
+	return &reminderRepository{guard: newGuard(), reminderState: &reminderState{reminders: make(map[int64]todo.Reminder)}}
+}
+
+func (rs *reminderRepository) Insert(_ context.Context, reminder *todo.Reminder) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	rs.counter++
+	reminder.ID = rs.counter
+	rs.reminders[reminder.ID] = *reminder
+	return nil
+}
+
+func (rs *reminderRepository) FindAllByTaskID(_ context.Context, taskID int64) ([]todo.Reminder, error) {
+	return rs.filter(func(reminder todo.Reminder) bool { return reminder.TaskID == taskID }), nil
+}
+
+func (rs *reminderRepository) FindByID(_ context.Context, id int64) (*todo.Reminder, error) {
+	rs.RLock()
+	defer rs.RUnlock()
+
+	reminder, ok := rs.reminders[id]
+	if !ok {
+		return nil, todo.ErrReminderNotFound
+	}
+	return &reminder, nil
+}
+
+func (rs *reminderRepository) FindAllPending(_ context.Context) ([]todo.Reminder, error) {
+	return rs.filter(func(reminder todo.Reminder) bool { return reminder.SentAt == nil }), nil
+}
+
+func (rs *reminderRepository) Update(_ context.Context, reminder *todo.Reminder) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	stored, ok := rs.reminders[reminder.ID]
+	if !ok {
+		return todo.ErrReminderNotFound
+	}
+	stored.SentAt, stored.Attempts, stored.LastError = reminder.SentAt, reminder.Attempts, reminder.LastError
+	rs.reminders[reminder.ID] = stored
+	*reminder = stored
+	return nil
+}
+
+func (rs *reminderRepository) DeleteByID(_ context.Context, id int64) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	if _, ok := rs.reminders[id]; !ok {
+		return todo.ErrReminderNotFound
+	}
+	delete(rs.reminders, id)
+	return nil
+}
+
+func (rs *reminderRepository) DeleteAllByTaskIDs(_ context.Context, taskIDs []int64) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	tasks := make(map[int64]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+	for id, reminder := range rs.reminders {
+		if tasks[reminder.TaskID] {
+			delete(rs.reminders, id)
+		}
+	}
+	return nil
+}
+
+// filter returns the reminders that keep reports true for, in the order they were added.
+func (rs *reminderRepository) filter(keep func(todo.Reminder) bool) []todo.Reminder {
+	rs.RLock()
+	defer rs.RUnlock()
+
+	list := []todo.Reminder{}
+	for _, reminder := range rs.reminders {
+		if keep(reminder) {
+			list = append(list, reminder)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func (rs *reminderRepository) snapshot() func() {
+	saved := *rs.reminderState
+	saved.reminders = make(map[int64]todo.Reminder, len(rs.reminders))
+	for k, v := range rs.reminders {
+		saved.reminders[k] = v
+	}
+	return func() { *rs.reminderState = saved }
+}
+
+func (rs *reminderRepository) inUnit(changed func()) interface{} {
+	view := *rs
+	view.changed = changed
+	return &view
+}
@@ -0,0 +1,114 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type templateRepository struct {
+	guard
+	*templateState
+}
+
+type templateState struct {
+	templates map[int64]todo.Template
+	counter   int64
+}
+
+// NewTemplateRepository returns an in-memory implementation of todo.TemplateRepository.
+func NewTemplateRepository() todo.TemplateRepository {
+	return &templateRepository{guard: newGuard(), templateState: &templateState{templates: make(map[int64]todo.Template)}}
+}
+
+func (ts *templateRepository) Insert(_ context.Context, template *todo.Template) error {
+	ts.Lock()
+	defer ts.Unlock()
+
+	ts.counter++
+	template.ID = ts.counter
+	ts.templates[template.ID] = cloneTemplate(*template)
+	return nil
+}
+
+func (ts *templateRepository) FindAll(_ context.Context) ([]todo.Template, error) {
+	ts.RLock()
+	defer ts.RUnlock()
+
+	list := make([]todo.Template, 0, len(ts.templates))
+	for _, template := range ts.templates {
+		list = append(list, cloneTemplate(template))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (ts *templateRepository) FindByID(_ context.Context, id int64) (*todo.Template, error) {
+	ts.RLock()
+	defer ts.RUnlock()
+
+	template, ok := ts.templates[id]
+	if !ok {
+		return nil, todo.ErrTemplateNotFound
+	}
+	template = cloneTemplate(template)
+	return &template, nil
+}
+
+func (ts *templateRepository) Update(_ context.Context, template *todo.Template) error {
+	ts.Lock()
+	defer ts.Unlock()
+
+	if _, ok := ts.templates[template.ID]; !ok {
+		return todo.ErrTemplateNotFound
+	}
+	ts.templates[template.ID] = cloneTemplate(*template)
+	return nil
+}
+
+func (ts *templateRepository) DeleteByID(_ context.Context, id int64) error {
+	ts.Lock()
+	defer ts.Unlock()
+
+	if _, ok := ts.templates[id]; !ok {
+		return todo.ErrTemplateNotFound
+	}
+	delete(ts.templates, id)
+	return nil
+}
+
+// cloneTemplate copies the tasks of the template so that callers can't modify the stored ones.
+func cloneTemplate(template todo.Template) todo.Template {
+	tasks := make([]todo.TemplateTask, len(template.Tasks))
+	for i, task := range template.Tasks {
+		task.Tags = append([]string(nil), task.Tags...)
+		if task.DueOffset != nil {
+			offset := *task.DueOffset
+			task.DueOffset = &offset
+		}
+		tasks[i] = task
+	}
+	template.Tasks = tasks
+	return template
+}
+
+func (ts *templateRepository) snapshot() func() {
+	saved := *ts.templateState
+	saved.templates = make(map[int64]todo.Template, len(ts.templates))
+	for k, v := range ts.templates {
+		saved.templates[k] = v
+	}
+	return func() { *ts.templateState = saved }
+}
+
+func (ts *templateRepository) inUnit(changed func()) interface{} {
+	view := *ts
+	view.changed = changed
+	return &view
+}
@@ -0,0 +1,142 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type timeEntryRepository struct {
+	guard
+	*timeEntryState
+}
+
+type timeEntryState struct {
+	entries map[int64]todo.TimeEntry
+	counter int64
+}
+
+// NewTimeEntryRepository returns an in-memory implementation of todo.TimeEntryRepository.
+func NewTimeEntryRepository() todo.TimeEntryRepository {
+	return &timeEntryRepository{guard: newGuard(), timeEntryState: &timeEntryState{entries: make(map[int64]todo.TimeEntry)}}
+}
+
+func (es *timeEntryRepository) Insert(_ context.Context, entry *todo.TimeEntry) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if entry.IsRunning() {
+		for _, e := range es.entries {
+			if e.IsRunning() && e.User == entry.User {
+				return todo.ErrTimerRunning
+			}
+		}
+	}
+	es.counter++
+	entry.ID = es.counter
+	es.entries[entry.ID] = *entry
+	return nil
+}
+
+func (es *timeEntryRepository) FindAllByTaskIDs(_ context.Context, taskIDs []int64) ([]todo.TimeEntry, error) {
+	es.RLock()
+	defer es.RUnlock()
+
+	tasks := make(map[int64]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+	list := []todo.TimeEntry{}
+	for _, entry := range es.entries {
+		if tasks[entry.TaskID] {
+			list = append(list, entry)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Start.Equal(list[j].Start) {
+			return list[i].Start.Before(list[j].Start)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (es *timeEntryRepository) FindByID(_ context.Context, id int64) (*todo.TimeEntry, error) {
+	es.RLock()
+	defer es.RUnlock()
+
+	entry, ok := es.entries[id]
+	if !ok {
+		return nil, todo.ErrTimeEntryNotFound
+	}
+	return &entry, nil
+}
+
+func (es *timeEntryRepository) FindRunning(_ context.Context, user string) (*todo.TimeEntry, error) {
+	es.RLock()
+	defer es.RUnlock()
+
+	for _, entry := range es.entries {
+		if entry.IsRunning() && entry.User == user {
+			return &entry, nil
+		}
+	}
+	return nil, todo.ErrTimeEntryNotFound
+}
+
+func (es *timeEntryRepository) Update(_ context.Context, entry *todo.TimeEntry) error {
+	es.Lock()
+	defer es.Unlock()
+
+	stored, ok := es.entries[entry.ID]
+	if !ok {
+		return todo.ErrTimeEntryNotFound
+	}
+	stored.Start, stored.End = entry.Start, entry.End
+	es.entries[entry.ID] = stored
+	*entry = stored
+	return nil
+}
+
+func (es *timeEntryRepository) DeleteByID(_ context.Context, id int64) error {
+	es.Lock()
+	defer es.Unlock()
+
+	if _, ok := es.entries[id]; !ok {
+		return todo.ErrTimeEntryNotFound
+	}
+	delete(es.entries, id)
+	return nil
+}
+
+func (es *timeEntryRepository) DeleteAllByTaskIDs(_ context.Context, taskIDs []int64) error {
+	es.Lock()
+	defer es.Unlock()
+
+	tasks := make(map[int64]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+	for id, entry := range es.entries {
+		if tasks[entry.TaskID] {
+			delete(es.entries, id)
+		}
+	}
+	return nil
+}
+
+func (es *timeEntryRepository) snapshot() func() {
+	saved := *es.timeEntryState
+	saved.entries = make(map[int64]todo.TimeEntry, len(es.entries))
+	for k, v := range es.entries {
+		saved.entries[k] = v
+	}
+	return func() { *es.timeEntryState = saved }
+}
+
+func (es *timeEntryRepository) inUnit(changed func()) interface{} {
+	view := *es
+	view.changed = changed
+	return &view
+}
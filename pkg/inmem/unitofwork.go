@@ -0,0 +1,125 @@
+package inmem
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+// guard is the lock of a repository, which every method of it takes. The views of a repository
+// a unit of work gives fn don't take it, as the unit holds it already, and instead call changed
+// before their first change.
+type guard struct {
+	mu      *sync.RWMutex
+	changed func() // set only in views
+}
+
+func newGuard() guard {
+	return guard{mu: &sync.RWMutex{}}
+}
+
+func (g guard) mutex() *sync.RWMutex { return g.mu }
+
+func (g guard) Lock() {
+	if g.changed != nil {
+		g.changed()
+		return
+	}
+	g.mu.Lock()
+}
+
+func (g guard) Unlock() {
+	if g.changed == nil {
+		g.mu.Unlock()
+	}
+}
+
+func (g guard) RLock() {
+	if g.changed == nil {
+		g.mu.RLock()
+	}
+}
+
+func (g guard) RUnlock() {
+	if g.changed == nil {
+		g.mu.RUnlock()
+	}
+}
+
+// repository is implemented by the repositories of this package.
+type repository interface {
+	mutex() *sync.RWMutex
+	// snapshot copies the state of the repository and returns a func that restores it.
+	// It must be called with the write lock held.
+	snapshot() (restore func())
+	// inUnit returns a view of the repository for a unit of work holding its lock,
+	// which calls changed before every change it makes.
+	inUnit(changed func()) interface{}
+}
+
+// NewRepositories returns in-memory implementations of all the repositories.
+func NewRepositories() todo.Repositories {
+	return todo.Repositories{
+		Tasks:        NewTaskRepository(),
+		Checklists:   NewChecklistRepository(),
+		History:      NewHistoryRepository(),
+		Comments:     NewCommentRepository(),
+		Dependencies: NewDependencyRepository(),
+		Users:        NewUserRepository(),
+		TimeEntries:  NewTimeEntryRepository(),
+		Templates:    NewTemplateRepository(),
+		Reminders:    NewReminderRepository(),
+		Attachments:  NewAttachmentRepository(),
+	}
+}
+
+type unitOfWork struct {
+	repositories todo.Repositories
+}
+
+// NewUnitOfWork returns an in-memory implementation of todo.UnitOfWork over repositories
+// created by this package. A unit of work holds the locks of all the repositories while it
+// runs, so nothing else can read or change them until it's done, and snapshots each one
+// before changing it for the first time so that it can be restored if the unit fails.
+func NewUnitOfWork(repositories todo.Repositories) todo.UnitOfWork {
+	return &unitOfWork{repositories: repositories}
+}
+
+func (u *unitOfWork) Do(_ context.Context, fn func(todo.Repositories) error) error {
+	var (
+		mu       sync.Mutex
+		restores []func()
+	)
+	tx := u.repositories
+	// the repositories are always locked in the order of their fields so that units of work
+	// running at the same time can't deadlock
+	fields := reflect.ValueOf(&tx).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		r, ok := fields.Field(i).Interface().(repository)
+		if !ok {
+			continue
+		}
+		r.mutex().Lock()
+		defer r.mutex().Unlock()
+
+		var once sync.Once
+		fields.Field(i).Set(reflect.ValueOf(r.inUnit(func() {
+			once.Do(func() {
+				mu.Lock()
+				defer mu.Unlock()
+
+				restores = append(restores, r.snapshot())
+			})
+		})))
+	}
+
+	if err := fn(tx); err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type userRepository struct {
+	guard
+	*userState
+}
+
+type userState struct {
+	users   map[int64]todo.User
+	counter int64
+}
+
+// NewUserRepository returns an in-memory implementation of todo.UserRepository.
+func NewUserRepository() todo.UserRepository {
+	return &userRepository{guard: newGuard(), userState: &userState{users: make(map[int64]todo.User)}}
+}
+
+func (us *userRepository) Insert(_ context.Context, user *todo.User) error {
+	us.Lock()
+	defer us.Unlock()
+
+	for _, u := range us.users {
+		if u.Name == user.Name {
+			return todo.ErrUserAlreadyExists
+		}
+	}
+	us.counter++
+	user.ID = us.counter
+	us.users[user.ID] = *user
+	return nil
+}
+
+func (us *userRepository) FindAll(_ context.Context) ([]todo.User, error) {
+	us.RLock()
+	defer us.RUnlock()
+
+	list := make([]todo.User, 0, len(us.users))
+	for _, user := range us.users {
+		list = append(list, user)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (us *userRepository) FindByID(_ context.Context, id int64) (*todo.User, error) {
+	us.RLock()
+	defer us.RUnlock()
+
+	user, ok := us.users[id]
+	if !ok {
+		return nil, todo.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (us *userRepository) FindByName(_ context.Context, name string) (*todo.User, error) {
+	us.RLock()
+	defer us.RUnlock()
+
+	for _, user := range us.users {
+		if user.Name == name {
+			return &user, nil
+		}
+	}
+	return nil, todo.ErrUserNotFound
+}
+
+func (us *userRepository) snapshot() func() {
+	saved := *us.userState
+	saved.users = make(map[int64]todo.User, len(us.users))
+	for k, v := range us.users {
+		saved.users[k] = v
+	}
+	return func() { *us.userState = saved }
+}
+
+func (us *userRepository) inUnit(changed func()) interface{} {
+	view := *us
+	view.changed = changed
+	return &view
+}
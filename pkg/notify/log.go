@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type logNotifier struct {
+	logger log.Logger
+}
+
+// NewLogNotifier returns a todo.Notifier that writes notifications to the logger,
+// which is handy during development or when nobody needs to be told.
+func NewLogNotifier(logger log.Logger) todo.Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Notify(_ context.Context, notification todo.Notification) error {
+	return n.logger.Log(
+		"msg", subject(notification),
+		"reminder_id", notification.Reminder.ID,
+		"task_id", notification.Task.ID,
+		"due_date", notification.Task.DueDate,
+	)
+}
@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+// subject is the one line summary of the notification. Line breaks in the task's
+// name are replaced so that it can't add headers to an email.
+func subject(n todo.Notification) string {
+	return "Reminder: " + strings.NewReplacer("\r", " ", "\n", " ").Replace(n.Task.Name)
+}
+
+// body is the text of the notification as it's sent by email.
+func body(n todo.Notification) string {
+	text := fmt.Sprintf("This is a reminder about task #%d, %q.\r\n", n.Task.ID, n.Task.Name)
+	if n.Task.DueDate != nil {
+		text += fmt.Sprintf("It is due %s.\r\n", n.Task.DueDate.Format(time.RFC1123))
+	}
+	if n.Task.Description != "" {
+		text += "\r\n" + n.Task.Description + "\r\n"
+	}
+	return text
+}
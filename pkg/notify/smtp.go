@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type smtpNotifier struct {
+	address string
+	from    string
+	to      []string
+}
+
+// NewSMTPNotifier returns a todo.Notifier that emails notifications to the recipients
+// through the SMTP server at address, e.g. a local relay or a stand-in such as MailHog.
+// It doesn't authenticate, so the server must accept mail from the app as is.
+func NewSMTPNotifier(address, from string, to []string) todo.Notifier {
+	return &smtpNotifier{address: address, from: from, to: to}
+}
+
+func (n *smtpNotifier) Notify(_ context.Context, notification todo.Notification) error {
+	msg := "From: " + n.from + "\r\n" +
+		"To: " + strings.Join(n.to, ", ") + "\r\n" +
+		"Subject: " + subject(notification) + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		body(notification)
+
+	if err := smtp.SendMail(n.address, nil, n.from, n.to, []byte(msg)); err != nil {
+		return fmt.Errorf("could not send email: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a todo.Notifier that POSTs notifications as JSON to the url.
+// Any response other than a 2xx counts as a failed delivery.
+func NewWebhookNotifier(url string, client *http.Client) todo.Notifier {
+	return &webhookNotifier{url: url, client: client}
+}
+
+type webhookPayload struct {
+	ReminderID int64      `json:"reminder_id"`
+	TaskID     int64      `json:"task_id"`
+	Name       string     `json:"name"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	Subject    string     `json:"subject"`
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, notification todo.Notification) error {
+	payload, err := json.Marshal(webhookPayload{
+		ReminderID: notification.Reminder.ID,
+		TaskID:     notification.Task.ID,
+		Name:       notification.Task.Name,
+		DueDate:    notification.Task.DueDate,
+		Subject:    subject(notification),
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode webhook payload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("could not create webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not call webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
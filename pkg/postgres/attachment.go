@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type attachmentRepository struct {
+	queries *gen.Queries
+}
+
+func NewAttachmentRepository(db *sql.DB) todo.AttachmentRepository {
+	return &attachmentRepository{queries: gen.New(db)}
+}
+
+func (r *attachmentRepository) Insert(ctx context.Context, attachment *todo.Attachment) error {
+	id, err := r.queries.InsertAttachment(ctx, gen.InsertAttachmentParams{
+		TaskID:      attachment.TaskID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.Size,
+		BlobKey:     attachment.Key,
+		UploadedBy:  attachment.UploadedBy,
+		CreatedAt:   attachment.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	attachment.ID = id
+	return nil
+}
+
+func (r *attachmentRepository) FindAllByTaskIDs(ctx context.Context, taskIDs []int64) ([]todo.Attachment, error) {
+	attachments, err := r.queries.FindAttachmentsByTasks(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]todo.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		list = append(list, toAttachment(attachment))
+	}
+	return list, nil
+}
+
+func (r *attachmentRepository) FindByID(ctx context.Context, id int64) (*todo.Attachment, error) {
+	attachment, err := r.queries.FindAttachment(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	found := toAttachment(attachment)
+	return &found, nil
+}
+
+func (r *attachmentRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := r.queries.DeleteAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (r *attachmentRepository) DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error {
+	return r.queries.DeleteAttachmentsByTasks(ctx, taskIDs)
+}
+
+func toAttachment(attachment gen.Attachment) todo.Attachment {
+	return todo.Attachment{
+		ID:          attachment.ID,
+		TaskID:      attachment.TaskID,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Size:        attachment.SizeBytes,
+		Key:         attachment.BlobKey,
+		UploadedBy:  attachment.UploadedBy,
+		CreatedAt:   attachment.CreatedAt.UTC(),
+	}
+}
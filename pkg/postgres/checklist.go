@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type checklistRepository struct {
+	queries *gen.Queries
+}
+
+func NewChecklistRepository(db *sql.DB) todo.ChecklistRepository {
+	return &checklistRepository{queries: gen.New(db)}
+}
+
+func (r *checklistRepository) Insert(ctx context.Context, checklist *todo.Checklist) error {
+	inserted, err := r.queries.InsertChecklist(ctx, checklist.Name)
+	if err != nil {
+		return err
+	}
+	checklist.ID = inserted.ID
+	return nil
+}
+
+func (r *checklistRepository) FindAll(ctx context.Context) ([]todo.Checklist, error) {
+	checklists, err := r.queries.FindAllChecklists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]todo.Checklist, 0, len(checklists))
+	for _, checklist := range checklists {
+		list = append(list, todo.Checklist{ID: checklist.ID, Name: checklist.Name})
+	}
+	return list, nil
+}
+
+func (r *checklistRepository) FindByID(ctx context.Context, id int64) (*todo.Checklist, error) {
+	checklist, err := r.queries.FindChecklist(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrChecklistNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &todo.Checklist{ID: checklist.ID, Name: checklist.Name}, nil
+}
+
+func (r *checklistRepository) Update(ctx context.Context, checklist *todo.Checklist) error {
+	n, err := r.queries.UpdateChecklist(ctx, gen.UpdateChecklistParams{ID: checklist.ID, Name: checklist.Name})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrChecklistNotFound
+	}
+	return nil
+}
+
+func (r *checklistRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := r.queries.DeleteChecklist(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrChecklistNotFound
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type commentRepository struct {
+	queries *gen.Queries
+}
+
+func NewCommentRepository(db *sql.DB) todo.CommentRepository {
+	return &commentRepository{queries: gen.New(db)}
+}
+
+func (r *commentRepository) Insert(ctx context.Context, comment *todo.Comment) error {
+	id, err := r.queries.InsertComment(ctx, gen.InsertCommentParams{
+		TaskID:    comment.TaskID,
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	comment.ID = id
+	return nil
+}
+
+func (r *commentRepository) FindAllByTaskID(ctx context.Context, taskID int64) ([]todo.Comment, error) {
+	comments, err := r.queries.FindCommentsByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]todo.Comment, 0, len(comments))
+	for _, comment := range comments {
+		list = append(list, toComment(comment))
+	}
+	return list, nil
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id int64) (*todo.Comment, error) {
+	comment, err := r.queries.FindComment(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	found := toComment(comment)
+	return &found, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *todo.Comment) error {
+	updated, err := r.queries.UpdateComment(ctx, gen.UpdateCommentParams{
+		ID:        comment.ID,
+		Body:      comment.Body,
+		UpdatedAt: comment.UpdatedAt,
+	})
+	if err == sql.ErrNoRows {
+		return todo.ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*comment = toComment(updated)
+	return nil
+}
+
+func (r *commentRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := r.queries.DeleteComment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *commentRepository) DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error {
+	return r.queries.DeleteCommentsByTasks(ctx, taskIDs)
+}
+
+func toComment(comment gen.Comment) todo.Comment {
+	return todo.Comment{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt.UTC(),
+		UpdatedAt: comment.UpdatedAt.UTC(),
+	}
+}
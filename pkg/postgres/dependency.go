@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type dependencyRepository struct {
+	queries *gen.Queries
+}
+
+func NewDependencyRepository(db *sql.DB) todo.DependencyRepository {
+	return &dependencyRepository{queries: gen.New(db)}
+}
+
+func (r *dependencyRepository) Insert(ctx context.Context, dep todo.Dependency) error {
+	return r.queries.InsertDependency(ctx, gen.InsertDependencyParams{TaskID: dep.TaskID, BlockerID: dep.BlockerID})
+}
+
+func (r *dependencyRepository) FindAll(ctx context.Context) ([]todo.Dependency, error) {
+	deps, err := r.queries.FindAllDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toDependencies(deps), nil
+}
+
+func (r *dependencyRepository) FindAllByTaskID(ctx context.Context, taskID int64) ([]todo.Dependency, error) {
+	deps, err := r.queries.FindDependenciesByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return toDependencies(deps), nil
+}
+
+func (r *dependencyRepository) Delete(ctx context.Context, dep todo.Dependency) error {
+	n, err := r.queries.DeleteDependency(ctx, gen.DeleteDependencyParams{TaskID: dep.TaskID, BlockerID: dep.BlockerID})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrDependencyNotFound
+	}
+	return nil
+}
+
+func (r *dependencyRepository) DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error {
+	return r.queries.DeleteDependenciesByTasks(ctx, taskIDs)
+}
+
+func toDependencies(deps []gen.TaskDependency) []todo.Dependency {
+	list := make([]todo.Dependency, 0, len(deps))
+	for _, dep := range deps {
+		list = append(list, todo.Dependency{TaskID: dep.TaskID, BlockerID: dep.BlockerID})
+	}
+	return list
+}
@@ -6,10 +6,107 @@ package gen
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+type Attachment struct {
+	ID          int64
+	TaskID      int64
+	Name        string
+	ContentType string
+	SizeBytes   int64
+	BlobKey     string
+	UploadedBy  string
+	CreatedAt   time.Time
+}
+
+type Checklist struct {
+	ID   int64
+	Name string
+}
+
+type Comment struct {
+	ID        int64
+	TaskID    int64
+	Author    string
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Reminder struct {
+	ID            int64
+	TaskID        int64
+	RemindAt      sql.NullTime
+	BeforeSeconds sql.NullInt64
+	SentAt        sql.NullTime
+	Attempts      int32
+	LastError     string
+}
+
+type Tag struct {
+	ID   int64
+	Name string
+}
+
 type Task struct {
+	ID              int64
+	Name            string
+	Done            sql.NullBool
+	DueDate         sql.NullTime
+	Priority        int16
+	ChecklistID     sql.NullInt64
+	ParentID        sql.NullInt64
+	Recurrence      sql.NullString
+	Version         int64
+	DeletedAt       sql.NullTime
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	CompletedAt     sql.NullTime
+	Description     string
+	Rank            string
+	Status          string
+	AssigneeID      sql.NullInt64
+	EstimateSeconds int64
+	ArchivedAt      sql.NullTime
+}
+
+type TaskDependency struct {
+	TaskID    int64
+	BlockerID int64
+}
+
+type TaskHistory struct {
+	ID         int64
+	TaskID     int64
+	Actor      string
+	OccurredAt time.Time
+	Operation  string
+	Before     json.RawMessage
+	After      json.RawMessage
+}
+
+type TaskTag struct {
+	TaskID int64
+	TagID  int64
+}
+
+type Template struct {
+	ID    int64
+	Name  string
+	Tasks json.RawMessage
+}
+
+type TimeEntry struct {
+	ID        int64
+	TaskID    int64
+	UserName  string
+	StartedAt time.Time
+	EndedAt   sql.NullTime
+}
+
+type User struct {
 	ID   int64
 	Name string
-	Done sql.NullBool
 }
@@ -8,25 +8,53 @@ package gen
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
-const deleteTask = `-- name: DeleteTask :exec
+const archiveTasksCompletedBefore = `-- name: ArchiveTasksCompletedBefore :execrows
+UPDATE tasks
+  set archived_at = $1,
+  version = version + 1
+WHERE done
+  AND archived_at IS NULL
+  AND deleted_at IS NULL
+  AND completed_at < $2
+`
+
+type ArchiveTasksCompletedBeforeParams struct {
+	ArchivedAt  sql.NullTime
+	CompletedAt sql.NullTime
+}
+
+func (q *Queries) ArchiveTasksCompletedBefore(ctx context.Context, arg ArchiveTasksCompletedBeforeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveTasksCompletedBefore, arg.ArchivedAt, arg.CompletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTasksByChecklist = `-- name: DeleteTasksByChecklist :exec
 DELETE FROM tasks
-WHERE id = $1
+WHERE checklist_id = $1
 `
 
-func (q *Queries) DeleteTask(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteTask, id)
+func (q *Queries) DeleteTasksByChecklist(ctx context.Context, checklistID sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, deleteTasksByChecklist, checklistID)
 	return err
 }
 
-const findAllTasks = `-- name: FindAllTasks :many
-SELECT id, name, done FROM tasks
-ORDER BY name
+const findSubtasks = `-- name: FindSubtasks :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE parent_id = $1
+  AND deleted_at IS NULL
+ORDER BY rank COLLATE "C", id
 `
 
-func (q *Queries) FindAllTasks(ctx context.Context) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, findAllTasks)
+func (q *Queries) FindSubtasks(ctx context.Context, parentID sql.NullInt64) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findSubtasks, parentID)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +62,27 @@ func (q *Queries) FindAllTasks(ctx context.Context) ([]Task, error) {
 	items := []Task{}
 	for rows.Next() {
 		var i Task
-		if err := rows.Scan(&i.ID, &i.Name, &i.Done); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -49,44 +97,1593 @@ func (q *Queries) FindAllTasks(ctx context.Context) ([]Task, error) {
 }
 
 const findTask = `-- name: FindTask :one
-SELECT id, name, done FROM tasks
-WHERE id = $1 LIMIT 1
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 func (q *Queries) FindTask(ctx context.Context, id int64) (Task, error) {
 	row := q.db.QueryRowContext(ctx, findTask, id)
 	var i Task
-	err := row.Scan(&i.ID, &i.Name, &i.Done)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Done,
+		&i.DueDate,
+		&i.Priority,
+		&i.ChecklistID,
+		&i.ParentID,
+		&i.Recurrence,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.Description,
+		&i.Rank,
+		&i.Status,
+		&i.AssigneeID,
+		&i.EstimateSeconds,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const findTaskForUpdate = `-- name: FindTaskForUpdate :one
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) FindTaskForUpdate(ctx context.Context, id int64) (Task, error) {
+	row := q.db.QueryRowContext(ctx, findTaskForUpdate, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Done,
+		&i.DueDate,
+		&i.Priority,
+		&i.ChecklistID,
+		&i.ParentID,
+		&i.Recurrence,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.Description,
+		&i.Rank,
+		&i.Status,
+		&i.AssigneeID,
+		&i.EstimateSeconds,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const findTasksByChecklist = `-- name: FindTasksByChecklist :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE checklist_id = $1
+  AND deleted_at IS NULL
+ORDER BY rank COLLATE "C", id
+`
+
+func (q *Queries) FindTasksByChecklist(ctx context.Context, checklistID sql.NullInt64) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksByChecklist, checklistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksDueBetween = `-- name: FindTasksDueBetween :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND due_date IS NOT NULL
+  AND ($1::timestamptz IS NULL OR due_date >= $1)
+  AND ($2::timestamptz IS NULL OR due_date < $2)
+ORDER BY due_date
+`
+
+type FindTasksDueBetweenParams struct {
+	After  sql.NullTime
+	Before sql.NullTime
+}
+
+func (q *Queries) FindTasksDueBetween(ctx context.Context, arg FindTasksDueBetweenParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksDueBetween, arg.After, arg.Before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByCompletedAt = `-- name: FindTasksSortedByCompletedAt :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN (COALESCE(completed_at, 'infinity'), id) < (COALESCE($26::timestamptz, 'infinity'), $24)
+    ELSE (COALESCE(completed_at, 'infinity'), id) > (COALESCE($26::timestamptz, 'infinity'), $24)
+  END)
+ORDER BY
+  CASE WHEN $25 THEN COALESCE(completed_at, 'infinity') END DESC,
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN COALESCE(completed_at, 'infinity') END,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $27::int
+`
+
+type FindTasksSortedByCompletedAtParams struct {
+	Done              sql.NullBool
+	Archived          sql.NullBool
+	Status            sql.NullString
+	AssigneeID        sql.NullInt64
+	ByChecklist       bool
+	ChecklistID       sql.NullInt64
+	ByParent          bool
+	ParentID          sql.NullInt64
+	NamePattern       string
+	CreatedAfter      sql.NullTime
+	CreatedBefore     sql.NullTime
+	UpdatedAfter      sql.NullTime
+	UpdatedBefore     sql.NullTime
+	CompletedAfter    sql.NullTime
+	CompletedBefore   sql.NullTime
+	DueAfter          sql.NullTime
+	DueBefore         sql.NullTime
+	ByTags            bool
+	Tags              []string
+	AllTags           bool
+	ByIDs             bool
+	IDs               []int64
+	ExcludeIDs        []int64
+	CursorID          sql.NullInt64
+	Descending        bool
+	CursorCompletedAt sql.NullTime
+	Limit             sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByCompletedAt(ctx context.Context, arg FindTasksSortedByCompletedAtParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByCompletedAt,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorCompletedAt,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByCreatedAt = `-- name: FindTasksSortedByCreatedAt :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN (created_at, id) < ($26::timestamptz, $24)
+    ELSE (created_at, id) > ($26::timestamptz, $24)
+  END)
+ORDER BY
+  CASE WHEN $25 THEN created_at END DESC,
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN created_at END,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $27::int
+`
+
+type FindTasksSortedByCreatedAtParams struct {
+	Done            sql.NullBool
+	Archived        sql.NullBool
+	Status          sql.NullString
+	AssigneeID      sql.NullInt64
+	ByChecklist     bool
+	ChecklistID     sql.NullInt64
+	ByParent        bool
+	ParentID        sql.NullInt64
+	NamePattern     string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	UpdatedAfter    sql.NullTime
+	UpdatedBefore   sql.NullTime
+	CompletedAfter  sql.NullTime
+	CompletedBefore sql.NullTime
+	DueAfter        sql.NullTime
+	DueBefore       sql.NullTime
+	ByTags          bool
+	Tags            []string
+	AllTags         bool
+	ByIDs           bool
+	IDs             []int64
+	ExcludeIDs      []int64
+	CursorID        sql.NullInt64
+	Descending      bool
+	CursorCreatedAt time.Time
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByCreatedAt(ctx context.Context, arg FindTasksSortedByCreatedAtParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByCreatedAt,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorCreatedAt,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByDueDate = `-- name: FindTasksSortedByDueDate :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN (COALESCE(due_date, 'infinity'), id) < (COALESCE($26::timestamptz, 'infinity'), $24)
+    ELSE (COALESCE(due_date, 'infinity'), id) > (COALESCE($26::timestamptz, 'infinity'), $24)
+  END)
+ORDER BY
+  CASE WHEN $25 THEN COALESCE(due_date, 'infinity') END DESC,
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN COALESCE(due_date, 'infinity') END,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $27::int
+`
+
+type FindTasksSortedByDueDateParams struct {
+	Done            sql.NullBool
+	Archived        sql.NullBool
+	Status          sql.NullString
+	AssigneeID      sql.NullInt64
+	ByChecklist     bool
+	ChecklistID     sql.NullInt64
+	ByParent        bool
+	ParentID        sql.NullInt64
+	NamePattern     string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	UpdatedAfter    sql.NullTime
+	UpdatedBefore   sql.NullTime
+	CompletedAfter  sql.NullTime
+	CompletedBefore sql.NullTime
+	DueAfter        sql.NullTime
+	DueBefore       sql.NullTime
+	ByTags          bool
+	Tags            []string
+	AllTags         bool
+	ByIDs           bool
+	IDs             []int64
+	ExcludeIDs      []int64
+	CursorID        sql.NullInt64
+	Descending      bool
+	CursorDueDate   sql.NullTime
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByDueDate(ctx context.Context, arg FindTasksSortedByDueDateParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByDueDate,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorDueDate,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByID = `-- name: FindTasksSortedByID :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN id < $24
+    ELSE id > $24
+  END)
+ORDER BY
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $26::int
+`
+
+type FindTasksSortedByIDParams struct {
+	Done            sql.NullBool
+	Archived        sql.NullBool
+	Status          sql.NullString
+	AssigneeID      sql.NullInt64
+	ByChecklist     bool
+	ChecklistID     sql.NullInt64
+	ByParent        bool
+	ParentID        sql.NullInt64
+	NamePattern     string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	UpdatedAfter    sql.NullTime
+	UpdatedBefore   sql.NullTime
+	CompletedAfter  sql.NullTime
+	CompletedBefore sql.NullTime
+	DueAfter        sql.NullTime
+	DueBefore       sql.NullTime
+	ByTags          bool
+	Tags            []string
+	AllTags         bool
+	ByIDs           bool
+	IDs             []int64
+	ExcludeIDs      []int64
+	CursorID        sql.NullInt64
+	Descending      bool
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByID(ctx context.Context, arg FindTasksSortedByIDParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByID,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByName = `-- name: FindTasksSortedByName :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN (name COLLATE "C", id) < ($26::text COLLATE "C", $24)
+    ELSE (name COLLATE "C", id) > ($26::text COLLATE "C", $24)
+  END)
+ORDER BY
+  CASE WHEN $25 THEN name COLLATE "C" END DESC,
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN name COLLATE "C" END,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $27::int
+`
+
+type FindTasksSortedByNameParams struct {
+	Done            sql.NullBool
+	Archived        sql.NullBool
+	Status          sql.NullString
+	AssigneeID      sql.NullInt64
+	ByChecklist     bool
+	ChecklistID     sql.NullInt64
+	ByParent        bool
+	ParentID        sql.NullInt64
+	NamePattern     string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	UpdatedAfter    sql.NullTime
+	UpdatedBefore   sql.NullTime
+	CompletedAfter  sql.NullTime
+	CompletedBefore sql.NullTime
+	DueAfter        sql.NullTime
+	DueBefore       sql.NullTime
+	ByTags          bool
+	Tags            []string
+	AllTags         bool
+	ByIDs           bool
+	IDs             []int64
+	ExcludeIDs      []int64
+	CursorID        sql.NullInt64
+	Descending      bool
+	CursorName      string
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByName(ctx context.Context, arg FindTasksSortedByNameParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByName,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorName,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByPosition = `-- name: FindTasksSortedByPosition :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN (rank COLLATE "C", id) < ($26::text COLLATE "C", $24)
+    ELSE (rank COLLATE "C", id) > ($26::text COLLATE "C", $24)
+  END)
+ORDER BY
+  CASE WHEN $25 THEN rank COLLATE "C" END DESC,
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN rank COLLATE "C" END,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $27::int
+`
+
+type FindTasksSortedByPositionParams struct {
+	Done            sql.NullBool
+	Archived        sql.NullBool
+	Status          sql.NullString
+	AssigneeID      sql.NullInt64
+	ByChecklist     bool
+	ChecklistID     sql.NullInt64
+	ByParent        bool
+	ParentID        sql.NullInt64
+	NamePattern     string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	UpdatedAfter    sql.NullTime
+	UpdatedBefore   sql.NullTime
+	CompletedAfter  sql.NullTime
+	CompletedBefore sql.NullTime
+	DueAfter        sql.NullTime
+	DueBefore       sql.NullTime
+	ByTags          bool
+	Tags            []string
+	AllTags         bool
+	ByIDs           bool
+	IDs             []int64
+	ExcludeIDs      []int64
+	CursorID        sql.NullInt64
+	Descending      bool
+	CursorRank      string
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByPosition(ctx context.Context, arg FindTasksSortedByPositionParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByPosition,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorRank,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByPriority = `-- name: FindTasksSortedByPriority :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN (-priority, COALESCE(due_date, 'infinity'), id) < (-$26::smallint, COALESCE($27::timestamptz, 'infinity'), $24)
+    ELSE (-priority, COALESCE(due_date, 'infinity'), id) > (-$26::smallint, COALESCE($27::timestamptz, 'infinity'), $24)
+  END)
+ORDER BY
+  CASE WHEN $25 THEN priority END,
+  CASE WHEN $25 THEN COALESCE(due_date, 'infinity') END DESC,
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN priority END DESC,
+  CASE WHEN NOT $25 THEN COALESCE(due_date, 'infinity') END,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $28::int
+`
+
+type FindTasksSortedByPriorityParams struct {
+	Done            sql.NullBool
+	Archived        sql.NullBool
+	Status          sql.NullString
+	AssigneeID      sql.NullInt64
+	ByChecklist     bool
+	ChecklistID     sql.NullInt64
+	ByParent        bool
+	ParentID        sql.NullInt64
+	NamePattern     string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	UpdatedAfter    sql.NullTime
+	UpdatedBefore   sql.NullTime
+	CompletedAfter  sql.NullTime
+	CompletedBefore sql.NullTime
+	DueAfter        sql.NullTime
+	DueBefore       sql.NullTime
+	ByTags          bool
+	Tags            []string
+	AllTags         bool
+	ByIDs           bool
+	IDs             []int64
+	ExcludeIDs      []int64
+	CursorID        sql.NullInt64
+	Descending      bool
+	CursorPriority  int16
+	CursorDueDate   sql.NullTime
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByPriority(ctx context.Context, arg FindTasksSortedByPriorityParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByPriority,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorPriority,
+		arg.CursorDueDate,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTasksSortedByUpdatedAt = `-- name: FindTasksSortedByUpdatedAt :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NULL
+  AND ($1::boolean IS NULL OR done = $1)
+  AND ($2::boolean IS NULL OR (archived_at IS NOT NULL) = $2)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::bigint IS NULL OR assignee_id = $4)
+  AND (NOT $5::boolean OR checklist_id IS NOT DISTINCT FROM $6::bigint)
+  AND (NOT $7::boolean OR parent_id IS NOT DISTINCT FROM $8::bigint)
+  AND name ILIKE $9
+  AND ($10::timestamptz IS NULL OR created_at >= $10)
+  AND ($11::timestamptz IS NULL OR created_at < $11)
+  AND ($12::timestamptz IS NULL OR updated_at >= $12)
+  AND ($13::timestamptz IS NULL OR updated_at < $13)
+  AND ($14::timestamptz IS NULL OR completed_at >= $14)
+  AND ($15::timestamptz IS NULL OR completed_at < $15)
+  AND ($16::timestamptz IS NULL OR due_date >= $16)
+  AND ($17::timestamptz IS NULL OR due_date < $17)
+  AND (NOT $18::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY($19::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT $20::boolean OR COUNT(DISTINCT tags.name) = cardinality($19::text[])
+  ))
+  AND (NOT $21::boolean OR id = ANY($22::bigint[]))
+  AND (id = ANY($23::bigint[])) IS NOT TRUE
+  AND ($24::bigint IS NULL OR CASE WHEN $25::boolean
+    THEN (updated_at, id) < ($26::timestamptz, $24)
+    ELSE (updated_at, id) > ($26::timestamptz, $24)
+  END)
+ORDER BY
+  CASE WHEN $25 THEN updated_at END DESC,
+  CASE WHEN $25 THEN id END DESC,
+  CASE WHEN NOT $25 THEN updated_at END,
+  CASE WHEN NOT $25 THEN id END
+LIMIT $27::int
+`
+
+type FindTasksSortedByUpdatedAtParams struct {
+	Done            sql.NullBool
+	Archived        sql.NullBool
+	Status          sql.NullString
+	AssigneeID      sql.NullInt64
+	ByChecklist     bool
+	ChecklistID     sql.NullInt64
+	ByParent        bool
+	ParentID        sql.NullInt64
+	NamePattern     string
+	CreatedAfter    sql.NullTime
+	CreatedBefore   sql.NullTime
+	UpdatedAfter    sql.NullTime
+	UpdatedBefore   sql.NullTime
+	CompletedAfter  sql.NullTime
+	CompletedBefore sql.NullTime
+	DueAfter        sql.NullTime
+	DueBefore       sql.NullTime
+	ByTags          bool
+	Tags            []string
+	AllTags         bool
+	ByIDs           bool
+	IDs             []int64
+	ExcludeIDs      []int64
+	CursorID        sql.NullInt64
+	Descending      bool
+	CursorUpdatedAt time.Time
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) FindTasksSortedByUpdatedAt(ctx context.Context, arg FindTasksSortedByUpdatedAtParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTasksSortedByUpdatedAt,
+		arg.Done,
+		arg.Archived,
+		arg.Status,
+		arg.AssigneeID,
+		arg.ByChecklist,
+		arg.ChecklistID,
+		arg.ByParent,
+		arg.ParentID,
+		arg.NamePattern,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.UpdatedAfter,
+		arg.UpdatedBefore,
+		arg.CompletedAfter,
+		arg.CompletedBefore,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.ByTags,
+		pq.Array(arg.Tags),
+		arg.AllTags,
+		arg.ByIDs,
+		pq.Array(arg.IDs),
+		pq.Array(arg.ExcludeIDs),
+		arg.CursorID,
+		arg.Descending,
+		arg.CursorUpdatedAt,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findTrashedTask = `-- name: FindTrashedTask :one
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1
+`
+
+func (q *Queries) FindTrashedTask(ctx context.Context, id int64) (Task, error) {
+	row := q.db.QueryRowContext(ctx, findTrashedTask, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Done,
+		&i.DueDate,
+		&i.Priority,
+		&i.ChecklistID,
+		&i.ParentID,
+		&i.Recurrence,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.Description,
+		&i.Rank,
+		&i.Status,
+		&i.AssigneeID,
+		&i.EstimateSeconds,
+		&i.ArchivedAt,
+	)
 	return i, err
 }
 
+const findTrashedTasks = `-- name: FindTrashedTasks :many
+SELECT id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at FROM tasks
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id
+`
+
+func (q *Queries) FindTrashedTasks(ctx context.Context) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, findTrashedTasks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Task{}
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Done,
+			&i.DueDate,
+			&i.Priority,
+			&i.ChecklistID,
+			&i.ParentID,
+			&i.Recurrence,
+			&i.Version,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.Description,
+			&i.Rank,
+			&i.Status,
+			&i.AssigneeID,
+			&i.EstimateSeconds,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertTask = `-- name: InsertTask :one
-INSERT INTO tasks (name) 
-VALUES ($1)
-RETURNING id, name, done
+INSERT INTO tasks (checklist_id, parent_id, assignee_id, name, description, done, status, due_date, priority, recurrence, estimate_seconds, created_at, updated_at, completed_at, rank, archived_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+RETURNING id, name, done, due_date, priority, checklist_id, parent_id, recurrence, version, deleted_at, created_at, updated_at, completed_at, description, rank, status, assignee_id, estimate_seconds, archived_at
 `
 
-func (q *Queries) InsertTask(ctx context.Context, name string) (Task, error) {
-	row := q.db.QueryRowContext(ctx, insertTask, name)
+type InsertTaskParams struct {
+	ChecklistID     sql.NullInt64
+	ParentID        sql.NullInt64
+	AssigneeID      sql.NullInt64
+	Name            string
+	Description     string
+	Done            sql.NullBool
+	Status          string
+	DueDate         sql.NullTime
+	Priority        int16
+	Recurrence      sql.NullString
+	EstimateSeconds int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	CompletedAt     sql.NullTime
+	Rank            string
+	ArchivedAt      sql.NullTime
+}
+
+func (q *Queries) InsertTask(ctx context.Context, arg InsertTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, insertTask,
+		arg.ChecklistID,
+		arg.ParentID,
+		arg.AssigneeID,
+		arg.Name,
+		arg.Description,
+		arg.Done,
+		arg.Status,
+		arg.DueDate,
+		arg.Priority,
+		arg.Recurrence,
+		arg.EstimateSeconds,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+		arg.Rank,
+		arg.ArchivedAt,
+	)
 	var i Task
-	err := row.Scan(&i.ID, &i.Name, &i.Done)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Done,
+		&i.DueDate,
+		&i.Priority,
+		&i.ChecklistID,
+		&i.ParentID,
+		&i.Recurrence,
+		&i.Version,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.Description,
+		&i.Rank,
+		&i.Status,
+		&i.AssigneeID,
+		&i.EstimateSeconds,
+		&i.ArchivedAt,
+	)
 	return i, err
 }
 
-const updateTask = `-- name: UpdateTask :exec
+const lockTasks = `-- name: LockTasks :exec
+SELECT pg_advisory_xact_lock($1)
+`
+
+func (q *Queries) LockTasks(ctx context.Context, key int64) error {
+	_, err := q.db.ExecContext(ctx, lockTasks, key)
+	return err
+}
+
+const purgeTasksTrashedBefore = `-- name: PurgeTasksTrashedBefore :execrows
+DELETE FROM tasks
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeTasksTrashedBefore(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTasksTrashedBefore, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreTaskTree = `-- name: RestoreTaskTree :execrows
+WITH RECURSIVE tree AS (
+  SELECT id, deleted_at FROM tasks WHERE tasks.id = $1 AND deleted_at IS NOT NULL
+  UNION
+  SELECT tasks.id, tasks.deleted_at FROM tasks
+  JOIN tree ON tasks.parent_id = tree.id AND tasks.deleted_at = tree.deleted_at
+)
+UPDATE tasks
+  set deleted_at = NULL,
+  version = version + 1
+WHERE id IN (SELECT id FROM tree)
+`
+
+func (q *Queries) RestoreTaskTree(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreTaskTree, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const taskExists = `-- name: TaskExists :one
+SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1)
+`
+
+func (q *Queries) TaskExists(ctx context.Context, id int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, taskExists, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const trashTaskTree = `-- name: TrashTaskTree :execrows
+WITH RECURSIVE tree AS (
+  SELECT id FROM tasks WHERE tasks.id = $1 AND deleted_at IS NULL
+  UNION
+  SELECT tasks.id FROM tasks
+  JOIN tree ON tasks.parent_id = tree.id
+  WHERE tasks.deleted_at IS NULL
+)
+UPDATE tasks
+  set deleted_at = $2,
+  version = version + 1
+WHERE id IN (SELECT id FROM tree)
+`
+
+type TrashTaskTreeParams struct {
+	ID        int64
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) TrashTaskTree(ctx context.Context, arg TrashTaskTreeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, trashTaskTree, arg.ID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateTask = `-- name: UpdateTask :one
 UPDATE tasks
   set name = $2,
-  done = $3
+  done = $3,
+  due_date = $4,
+  priority = $5,
+  checklist_id = $6,
+  parent_id = $7,
+  recurrence = $8,
+  updated_at = $9,
+  completed_at = $10,
+  description = $11,
+  rank = $12,
+  status = $13,
+  assignee_id = $14,
+  estimate_seconds = $15,
+  archived_at = $16,
+  version = version + 1
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($17::bigint IS NULL OR version = $17)
+RETURNING version
 `
 
 type UpdateTaskParams struct {
-	ID   int64
-	Name string
-	Done sql.NullBool
+	ID              int64
+	Name            string
+	Done            sql.NullBool
+	DueDate         sql.NullTime
+	Priority        int16
+	ChecklistID     sql.NullInt64
+	ParentID        sql.NullInt64
+	Recurrence      sql.NullString
+	UpdatedAt       time.Time
+	CompletedAt     sql.NullTime
+	Description     string
+	Rank            string
+	Status          string
+	AssigneeID      sql.NullInt64
+	EstimateSeconds int64
+	ArchivedAt      sql.NullTime
+	Version         sql.NullInt64
 }
 
-func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) error {
-	_, err := q.db.ExecContext(ctx, updateTask, arg.ID, arg.Name, arg.Done)
-	return err
+func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, updateTask,
+		arg.ID,
+		arg.Name,
+		arg.Done,
+		arg.DueDate,
+		arg.Priority,
+		arg.ChecklistID,
+		arg.ParentID,
+		arg.Recurrence,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+		arg.Description,
+		arg.Rank,
+		arg.Status,
+		arg.AssigneeID,
+		arg.EstimateSeconds,
+		arg.ArchivedAt,
+		arg.Version,
+	)
+	var version int64
+	err := row.Scan(&version)
+	return version, err
 }
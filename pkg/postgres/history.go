@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type historyRepository struct {
+	queries *gen.Queries
+}
+
+func NewHistoryRepository(db *sql.DB) todo.HistoryRepository {
+	return &historyRepository{queries: gen.New(db)}
+}
+
+func (r *historyRepository) Insert(ctx context.Context, entry *todo.HistoryEntry) error {
+	before, err := json.Marshal(toSnapshot(entry.Before))
+	if err != nil {
+		return errors.Wrap(err, "could not encode task before change")
+	}
+	after, err := json.Marshal(toSnapshot(entry.After))
+	if err != nil {
+		return errors.Wrap(err, "could not encode task after change")
+	}
+
+	id, err := r.queries.InsertHistoryEntry(ctx, gen.InsertHistoryEntryParams{
+		TaskID:     entry.TaskID,
+		Actor:      entry.Actor,
+		OccurredAt: entry.Time,
+		Operation:  string(entry.Operation),
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+	return nil
+}
+
+func (r *historyRepository) FindAllByTaskID(ctx context.Context, taskID, afterID int64, limit int) ([]todo.HistoryEntry, error) {
+	rows, err := r.queries.FindTaskHistory(ctx, gen.FindTaskHistoryParams{
+		TaskID: taskID,
+		ID:     afterID,
+		Limit:  sql.NullInt32{Int32: int32(limit), Valid: limit > 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]todo.HistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entry := todo.HistoryEntry{
+			ID:        row.ID,
+			TaskID:    row.TaskID,
+			Actor:     row.Actor,
+			Time:      row.OccurredAt.UTC(),
+			Operation: todo.Operation(row.Operation),
+		}
+		if entry.Before, err = fromSnapshot(row.Before); err != nil {
+			return nil, errors.Wrapf(err, "could not decode task before change %d", row.ID)
+		}
+		if entry.After, err = fromSnapshot(row.After); err != nil {
+			return nil, errors.Wrapf(err, "could not decode task after change %d", row.ID)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// taskSnapshot is how a task is recorded in its history. It is stored as JSON
+// so that entries stay readable no matter how the tasks table evolves.
+// A nil snapshot is stored as JSON null.
+type taskSnapshot struct {
+	ID          int64      `json:"id"`
+	ChecklistID int64      `json:"checklist_id,omitempty"`
+	ParentID    int64      `json:"parent_id,omitempty"`
+	AssigneeID  int64      `json:"assignee_id,omitempty"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Done        bool       `json:"done"`
+	Status      string     `json:"status,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Priority    int        `json:"priority"`
+	Tags        []string   `json:"tags,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Estimate    int64      `json:"estimate_seconds,omitempty"`
+	Rank        string     `json:"rank,omitempty"`
+	Version     int64      `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+}
+
+func toSnapshot(task *todo.Task) *taskSnapshot {
+	if task == nil {
+		return nil
+	}
+	return &taskSnapshot{
+		ID:          task.ID,
+		ChecklistID: task.ChecklistID,
+		ParentID:    task.ParentID,
+		AssigneeID:  task.AssigneeID,
+		Name:        task.Name,
+		Description: task.Description,
+		Done:        task.Done,
+		Status:      string(task.Status),
+		DueDate:     task.DueDate,
+		Priority:    int(task.Priority),
+		Tags:        task.Tags,
+		Recurrence:  toNullRecurrence(task.Recurrence).String,
+		Estimate:    int64(task.Estimate / time.Second),
+		Rank:        task.Rank,
+		Version:     task.Version,
+		DeletedAt:   task.DeletedAt,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		CompletedAt: task.CompletedAt,
+		ArchivedAt:  task.ArchivedAt,
+	}
+}
+
+func fromSnapshot(data json.RawMessage) (*todo.Task, error) {
+	var snapshot *taskSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	recurrence, err := todo.ParseRecurrence(snapshot.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	return &todo.Task{
+		ID:          snapshot.ID,
+		ChecklistID: snapshot.ChecklistID,
+		ParentID:    snapshot.ParentID,
+		AssigneeID:  snapshot.AssigneeID,
+		Name:        snapshot.Name,
+		Description: snapshot.Description,
+		Done:        snapshot.Done,
+		Status:      todo.Status(snapshot.Status),
+		DueDate:     snapshot.DueDate,
+		Priority:    todo.Priority(snapshot.Priority),
+		Tags:        snapshot.Tags,
+		Recurrence:  recurrence,
+		Estimate:    time.Duration(snapshot.Estimate) * time.Second,
+		Rank:        snapshot.Rank,
+		Version:     snapshot.Version,
+		DeletedAt:   snapshot.DeletedAt,
+		CreatedAt:   snapshot.CreatedAt,
+		UpdatedAt:   snapshot.UpdatedAt,
+		CompletedAt: snapshot.CompletedAt,
+		ArchivedAt:  snapshot.ArchivedAt,
+	}, nil
+}
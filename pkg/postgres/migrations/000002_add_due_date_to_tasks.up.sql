@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_date timestamptz;
+
+CREATE INDEX IF NOT EXISTS tasks_due_date_idx ON tasks (due_date);
@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority smallint NOT NULL DEFAULT 2
+  CHECK (priority BETWEEN 1 AND 4);
+
+CREATE INDEX IF NOT EXISTS tasks_priority_due_date_idx ON tasks (priority DESC, due_date ASC NULLS LAST);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tags (
+  id   BIGSERIAL PRIMARY KEY,
+  name text      NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS task_tags (
+  task_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+  tag_id  bigint NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+  PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx ON task_tags (tag_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS tasks_checklist_id_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS checklist_id;
+
+DROP TABLE IF EXISTS checklists;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS checklists (
+  id   BIGSERIAL PRIMARY KEY,
+  name text      NOT NULL
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS checklist_id bigint REFERENCES checklists (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS tasks_checklist_id_idx ON tasks (checklist_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
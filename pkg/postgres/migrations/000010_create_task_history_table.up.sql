@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS task_history (
+  id          bigserial PRIMARY KEY,
+  task_id     bigint NOT NULL,
+  actor       text NOT NULL,
+  occurred_at timestamptz NOT NULL,
+  operation   text NOT NULL,
+  before      jsonb NOT NULL,
+  after       jsonb NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS task_history_task_id_idx ON task_history (task_id, id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS comments (
+  id         BIGSERIAL   PRIMARY KEY,
+  task_id    bigint      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+  author     text        NOT NULL,
+  body       text        NOT NULL,
+  created_at timestamptz NOT NULL,
+  updated_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS comments_task_id_idx ON comments (task_id);
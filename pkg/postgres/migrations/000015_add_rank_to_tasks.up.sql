@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rank text NOT NULL DEFAULT '';
+-- existing tasks keep the order they were created in
+UPDATE tasks SET rank = lpad(to_hex(id), 16, '0') WHERE rank = '';
+CREATE INDEX IF NOT EXISTS tasks_rank_idx ON tasks (rank COLLATE "C", id);
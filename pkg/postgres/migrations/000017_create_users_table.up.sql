@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS users (
+  id   BIGSERIAL PRIMARY KEY,
+  name text      NOT NULL UNIQUE
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee_id bigint REFERENCES users (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS tasks_assignee_id_idx ON tasks (assignee_id);
@@ -0,0 +1,13 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimate_seconds bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS time_entries (
+  id         BIGSERIAL   PRIMARY KEY,
+  task_id    bigint      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+  user_name  text        NOT NULL,
+  started_at timestamptz NOT NULL,
+  ended_at   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS time_entries_task_id_idx ON time_entries (task_id);
+-- nobody can have more than one timer running at a time
+CREATE UNIQUE INDEX IF NOT EXISTS time_entries_running_idx ON time_entries (user_name) WHERE ended_at IS NULL;
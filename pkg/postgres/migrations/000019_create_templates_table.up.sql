@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS templates (
+  id    BIGSERIAL PRIMARY KEY,
+  name  text      NOT NULL,
+  tasks jsonb     NOT NULL
+);
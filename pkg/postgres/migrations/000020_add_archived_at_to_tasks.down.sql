@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_unarchived_completed_at_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS archived_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS tasks_unarchived_completed_at_idx ON tasks (completed_at) WHERE done AND archived_at IS NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reminders (
+  id             BIGSERIAL   PRIMARY KEY,
+  task_id        bigint      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+  remind_at      timestamptz,
+  before_seconds bigint      CHECK (before_seconds >= 0),
+  sent_at        timestamptz,
+  attempts       integer     NOT NULL DEFAULT 0,
+  last_error     text        NOT NULL DEFAULT '',
+  -- a reminder is either at a fixed time or relative to the due date of its task
+  CHECK ((remind_at IS NULL) <> (before_seconds IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS reminders_task_id_idx ON reminders (task_id);
+-- the scheduler only ever looks for reminders that are still to be sent
+CREATE INDEX IF NOT EXISTS reminders_pending_idx ON reminders (id) WHERE sent_at IS NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS attachments (
+  id           BIGSERIAL   PRIMARY KEY,
+  task_id      bigint      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+  name         text        NOT NULL,
+  content_type text        NOT NULL,
+  size_bytes   bigint      NOT NULL CHECK (size_bytes >= 0),
+  -- where the content is kept in the blob store
+  blob_key     text        NOT NULL UNIQUE,
+  uploaded_by  text        NOT NULL,
+  created_at   timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS attachments_task_id_idx ON attachments (task_id);
@@ -0,0 +1,21 @@
+-- name: InsertAttachment :one
+INSERT INTO attachments (task_id, name, content_type, size_bytes, blob_key, uploaded_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id;
+
+-- name: FindAttachmentsByTasks :many
+SELECT * FROM attachments
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[])
+ORDER BY id;
+
+-- name: FindAttachment :one
+SELECT * FROM attachments
+WHERE id = $1 LIMIT 1;
+
+-- name: DeleteAttachment :execrows
+DELETE FROM attachments
+WHERE id = $1;
+
+-- name: DeleteAttachmentsByTasks :exec
+DELETE FROM attachments
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[]);
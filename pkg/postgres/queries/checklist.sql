@@ -0,0 +1,21 @@
+-- name: InsertChecklist :one
+INSERT INTO checklists (name)
+VALUES ($1)
+RETURNING *;
+
+-- name: FindAllChecklists :many
+SELECT * FROM checklists
+ORDER BY name;
+
+-- name: FindChecklist :one
+SELECT * FROM checklists
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateChecklist :execrows
+UPDATE checklists
+  set name = $2
+WHERE id = $1;
+
+-- name: DeleteChecklist :execrows
+DELETE FROM checklists
+WHERE id = $1;
@@ -0,0 +1,28 @@
+-- name: InsertComment :one
+INSERT INTO comments (task_id, author, body, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: FindCommentsByTask :many
+SELECT * FROM comments
+WHERE task_id = $1
+ORDER BY id;
+
+-- name: FindComment :one
+SELECT * FROM comments
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateComment :one
+UPDATE comments
+  set body = $2,
+  updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteComment :execrows
+DELETE FROM comments
+WHERE id = $1;
+
+-- name: DeleteCommentsByTasks :exec
+DELETE FROM comments
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[]);
@@ -0,0 +1,22 @@
+-- name: InsertDependency :exec
+INSERT INTO task_dependencies (task_id, blocker_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: FindAllDependencies :many
+SELECT * FROM task_dependencies
+ORDER BY task_id, blocker_id;
+
+-- name: FindDependenciesByTask :many
+SELECT * FROM task_dependencies
+WHERE task_id = $1
+ORDER BY blocker_id;
+
+-- name: DeleteDependency :execrows
+DELETE FROM task_dependencies
+WHERE task_id = $1 AND blocker_id = $2;
+
+-- name: DeleteDependenciesByTasks :exec
+DELETE FROM task_dependencies
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[])
+  OR blocker_id = ANY(sqlc.arg('task_ids')::bigint[]);
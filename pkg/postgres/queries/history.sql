@@ -0,0 +1,10 @@
+-- name: InsertHistoryEntry :one
+INSERT INTO task_history (task_id, actor, occurred_at, operation, before, after)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: FindTaskHistory :many
+SELECT * FROM task_history
+WHERE task_id = $1 AND id > $2
+ORDER BY id
+LIMIT sqlc.narg('limit')::int;
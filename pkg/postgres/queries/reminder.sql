@@ -0,0 +1,33 @@
+-- name: InsertReminder :one
+INSERT INTO reminders (task_id, remind_at, before_seconds)
+VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: FindRemindersByTask :many
+SELECT * FROM reminders
+WHERE task_id = $1
+ORDER BY id;
+
+-- name: FindReminder :one
+SELECT * FROM reminders
+WHERE id = $1 LIMIT 1;
+
+-- name: FindPendingReminders :many
+SELECT * FROM reminders
+WHERE sent_at IS NULL
+ORDER BY id;
+
+-- name: UpdateReminder :execrows
+UPDATE reminders
+  set sent_at = $2,
+  attempts = $3,
+  last_error = $4
+WHERE id = $1;
+
+-- name: DeleteReminder :execrows
+DELETE FROM reminders
+WHERE id = $1;
+
+-- name: DeleteRemindersByTasks :exec
+DELETE FROM reminders
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[]);
@@ -0,0 +1,49 @@
+-- name: UpsertTag :one
+INSERT INTO tags (name)
+VALUES ($1)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id;
+
+-- name: InsertTaskTag :exec
+INSERT INTO task_tags (task_id, tag_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTaskTags :exec
+DELETE FROM task_tags
+WHERE task_id = $1;
+
+-- name: FindTaskTags :many
+SELECT task_tags.task_id, tags.name FROM task_tags
+JOIN tags ON tags.id = task_tags.tag_id
+WHERE task_tags.task_id = ANY(sqlc.arg('task_ids')::bigint[])
+ORDER BY tags.name;
+
+-- name: FindTagUsage :many
+SELECT tags.name, COUNT(task_tags.task_id) AS task_count FROM tags
+JOIN task_tags ON task_tags.tag_id = tags.id
+JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL
+GROUP BY tags.name
+ORDER BY tags.name;
+
+-- name: FindTasksWithAnyTag :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND id IN (
+  SELECT task_tags.task_id FROM task_tags
+  JOIN tags ON tags.id = task_tags.tag_id
+  WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+)
+ORDER BY priority DESC, due_date ASC NULLS LAST, id;
+
+-- name: FindTasksWithAllTags :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND id IN (
+  SELECT task_tags.task_id FROM task_tags
+  JOIN tags ON tags.id = task_tags.tag_id
+  WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+  GROUP BY task_tags.task_id
+  HAVING COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+)
+ORDER BY priority DESC, due_date ASC NULLS LAST, id;
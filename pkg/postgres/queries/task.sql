@@ -1,22 +1,417 @@
 -- name: InsertTask :one
-INSERT INTO tasks (name) 
-VALUES ($1)
+INSERT INTO tasks (checklist_id, parent_id, assignee_id, name, description, done, status, due_date, priority, recurrence, estimate_seconds, created_at, updated_at, completed_at, rank, archived_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 RETURNING *;
 
--- name: FindAllTasks :many
+-- name: FindTasksSortedByPriority :many
 SELECT * FROM tasks
-ORDER BY name;
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN (-priority, COALESCE(due_date, 'infinity'), id) < (-sqlc.arg('cursor_priority')::smallint, COALESCE(sqlc.narg('cursor_due_date')::timestamptz, 'infinity'), sqlc.narg('cursor_id'))
+    ELSE (-priority, COALESCE(due_date, 'infinity'), id) > (-sqlc.arg('cursor_priority')::smallint, COALESCE(sqlc.narg('cursor_due_date')::timestamptz, 'infinity'), sqlc.narg('cursor_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN priority END,
+  CASE WHEN sqlc.arg('descending') THEN COALESCE(due_date, 'infinity') END DESC,
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN priority END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN COALESCE(due_date, 'infinity') END,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksSortedByDueDate :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN (COALESCE(due_date, 'infinity'), id) < (COALESCE(sqlc.narg('cursor_due_date')::timestamptz, 'infinity'), sqlc.narg('cursor_id'))
+    ELSE (COALESCE(due_date, 'infinity'), id) > (COALESCE(sqlc.narg('cursor_due_date')::timestamptz, 'infinity'), sqlc.narg('cursor_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN COALESCE(due_date, 'infinity') END DESC,
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN COALESCE(due_date, 'infinity') END,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksSortedByName :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN (name COLLATE "C", id) < (sqlc.arg('cursor_name')::text COLLATE "C", sqlc.narg('cursor_id'))
+    ELSE (name COLLATE "C", id) > (sqlc.arg('cursor_name')::text COLLATE "C", sqlc.narg('cursor_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN name COLLATE "C" END DESC,
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN name COLLATE "C" END,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksSortedByID :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN id < sqlc.narg('cursor_id')
+    ELSE id > sqlc.narg('cursor_id')
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksSortedByCreatedAt :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id'))
+    ELSE (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.narg('cursor_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN created_at END DESC,
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN created_at END,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksSortedByUpdatedAt :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN (updated_at, id) < (sqlc.arg('cursor_updated_at')::timestamptz, sqlc.narg('cursor_id'))
+    ELSE (updated_at, id) > (sqlc.arg('cursor_updated_at')::timestamptz, sqlc.narg('cursor_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN updated_at END DESC,
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN updated_at END,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksSortedByCompletedAt :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN (COALESCE(completed_at, 'infinity'), id) < (COALESCE(sqlc.narg('cursor_completed_at')::timestamptz, 'infinity'), sqlc.narg('cursor_id'))
+    ELSE (COALESCE(completed_at, 'infinity'), id) > (COALESCE(sqlc.narg('cursor_completed_at')::timestamptz, 'infinity'), sqlc.narg('cursor_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN COALESCE(completed_at, 'infinity') END DESC,
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN COALESCE(completed_at, 'infinity') END,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksSortedByPosition :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('done')::boolean IS NULL OR done = sqlc.narg('done'))
+  AND (sqlc.narg('archived')::boolean IS NULL OR (archived_at IS NOT NULL) = sqlc.narg('archived'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('assignee_id')::bigint IS NULL OR assignee_id = sqlc.narg('assignee_id'))
+  AND (NOT sqlc.arg('by_checklist')::boolean OR checklist_id IS NOT DISTINCT FROM sqlc.narg('checklist_id')::bigint)
+  AND (NOT sqlc.arg('by_parent')::boolean OR parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::bigint)
+  AND name ILIKE sqlc.arg('name_pattern')
+  AND (sqlc.narg('created_after')::timestamptz IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('updated_after')::timestamptz IS NULL OR updated_at >= sqlc.narg('updated_after'))
+  AND (sqlc.narg('updated_before')::timestamptz IS NULL OR updated_at < sqlc.narg('updated_before'))
+  AND (sqlc.narg('completed_after')::timestamptz IS NULL OR completed_at >= sqlc.narg('completed_after'))
+  AND (sqlc.narg('completed_before')::timestamptz IS NULL OR completed_at < sqlc.narg('completed_before'))
+  AND (sqlc.narg('due_after')::timestamptz IS NULL OR due_date >= sqlc.narg('due_after'))
+  AND (sqlc.narg('due_before')::timestamptz IS NULL OR due_date < sqlc.narg('due_before'))
+  AND (NOT sqlc.arg('by_tags')::boolean OR id IN (
+    SELECT task_tags.task_id FROM task_tags
+    JOIN tags ON tags.id = task_tags.tag_id
+    WHERE tags.name = ANY(sqlc.arg('tags')::text[])
+    GROUP BY task_tags.task_id
+    HAVING NOT sqlc.arg('all_tags')::boolean OR COUNT(DISTINCT tags.name) = cardinality(sqlc.arg('tags')::text[])
+  ))
+  AND (NOT sqlc.arg('by_ids')::boolean OR id = ANY(sqlc.arg('ids')::bigint[]))
+  AND (id = ANY(sqlc.arg('exclude_ids')::bigint[])) IS NOT TRUE
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR CASE WHEN sqlc.arg('descending')::boolean
+    THEN (rank COLLATE "C", id) < (sqlc.arg('cursor_rank')::text COLLATE "C", sqlc.narg('cursor_id'))
+    ELSE (rank COLLATE "C", id) > (sqlc.arg('cursor_rank')::text COLLATE "C", sqlc.narg('cursor_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('descending') THEN rank COLLATE "C" END DESC,
+  CASE WHEN sqlc.arg('descending') THEN id END DESC,
+  CASE WHEN NOT sqlc.arg('descending') THEN rank COLLATE "C" END,
+  CASE WHEN NOT sqlc.arg('descending') THEN id END
+LIMIT sqlc.narg('limit')::int;
+
+-- name: FindTasksDueBetween :many
+SELECT * FROM tasks
+WHERE deleted_at IS NULL
+  AND due_date IS NOT NULL
+  AND (sqlc.narg('after')::timestamptz IS NULL OR due_date >= sqlc.narg('after'))
+  AND (sqlc.narg('before')::timestamptz IS NULL OR due_date < sqlc.narg('before'))
+ORDER BY due_date;
+
+-- name: FindTasksByChecklist :many
+SELECT * FROM tasks
+WHERE checklist_id = $1
+  AND deleted_at IS NULL
+ORDER BY rank COLLATE "C", id;
+
+-- name: FindSubtasks :many
+SELECT * FROM tasks
+WHERE parent_id = $1
+  AND deleted_at IS NULL
+ORDER BY rank COLLATE "C", id;
 
 -- name: FindTask :one
 SELECT * FROM tasks
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: LockTasks :exec
+SELECT pg_advisory_xact_lock($1);
+
+-- name: FindTaskForUpdate :one
+SELECT * FROM tasks
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE;
+
+-- name: FindTrashedTask :one
+SELECT * FROM tasks
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1;
+
+-- name: TaskExists :one
+SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1);
+
+-- name: FindTrashedTasks :many
+SELECT * FROM tasks
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id;
 
--- name: UpdateTask :exec
+-- name: UpdateTask :one
 UPDATE tasks
   set name = $2,
-  done = $3
-WHERE id = $1;
+  done = $3,
+  due_date = $4,
+  priority = $5,
+  checklist_id = $6,
+  parent_id = $7,
+  recurrence = $8,
+  updated_at = $9,
+  completed_at = $10,
+  description = $11,
+  rank = $12,
+  status = $13,
+  assignee_id = $14,
+  estimate_seconds = $15,
+  archived_at = $16,
+  version = version + 1
+WHERE id = $1
+  AND deleted_at IS NULL
+  AND (sqlc.narg('version')::bigint IS NULL OR version = sqlc.narg('version'))
+RETURNING version;
+
+-- name: TrashTaskTree :execrows
+WITH RECURSIVE tree AS (
+  SELECT id FROM tasks WHERE tasks.id = $1 AND deleted_at IS NULL
+  UNION
+  SELECT tasks.id FROM tasks
+  JOIN tree ON tasks.parent_id = tree.id
+  WHERE tasks.deleted_at IS NULL
+)
+UPDATE tasks
+  set deleted_at = $2,
+  version = version + 1
+WHERE id IN (SELECT id FROM tree);
+
+-- name: RestoreTaskTree :execrows
+WITH RECURSIVE tree AS (
+  SELECT id, deleted_at FROM tasks WHERE tasks.id = $1 AND deleted_at IS NOT NULL
+  UNION
+  SELECT tasks.id, tasks.deleted_at FROM tasks
+  JOIN tree ON tasks.parent_id = tree.id AND tasks.deleted_at = tree.deleted_at
+)
+UPDATE tasks
+  set deleted_at = NULL,
+  version = version + 1
+WHERE id IN (SELECT id FROM tree);
+
+-- name: PurgeTasksTrashedBefore :execrows
+DELETE FROM tasks
+WHERE deleted_at < $1;
+
+-- name: ArchiveTasksCompletedBefore :execrows
+UPDATE tasks
+  set archived_at = $1,
+  version = version + 1
+WHERE done
+  AND archived_at IS NULL
+  AND deleted_at IS NULL
+  AND completed_at < $2;
 
--- name: DeleteTask :exec
+-- name: DeleteTasksByChecklist :exec
 DELETE FROM tasks
-WHERE id = $1;
+WHERE checklist_id = $1;
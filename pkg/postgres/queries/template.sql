@@ -0,0 +1,22 @@
+-- name: InsertTemplate :one
+INSERT INTO templates (name, tasks)
+VALUES ($1, $2)
+RETURNING id;
+
+-- name: FindAllTemplates :many
+SELECT * FROM templates
+ORDER BY name, id;
+
+-- name: FindTemplate :one
+SELECT * FROM templates
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateTemplate :execrows
+UPDATE templates
+  set name = $2,
+  tasks = $3
+WHERE id = $1;
+
+-- name: DeleteTemplate :execrows
+DELETE FROM templates
+WHERE id = $1;
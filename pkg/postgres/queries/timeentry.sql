@@ -0,0 +1,32 @@
+-- name: InsertTimeEntry :one
+INSERT INTO time_entries (task_id, user_name, started_at, ended_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_name) WHERE ended_at IS NULL DO NOTHING
+RETURNING id;
+
+-- name: FindTimeEntriesByTasks :many
+SELECT * FROM time_entries
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[])
+ORDER BY started_at, id;
+
+-- name: FindTimeEntry :one
+SELECT * FROM time_entries
+WHERE id = $1 LIMIT 1;
+
+-- name: FindRunningTimeEntry :one
+SELECT * FROM time_entries
+WHERE user_name = $1 AND ended_at IS NULL LIMIT 1;
+
+-- name: UpdateTimeEntry :execrows
+UPDATE time_entries
+  set started_at = $2,
+  ended_at = $3
+WHERE id = $1;
+
+-- name: DeleteTimeEntry :execrows
+DELETE FROM time_entries
+WHERE id = $1;
+
+-- name: DeleteTimeEntriesByTasks :exec
+DELETE FROM time_entries
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[]);
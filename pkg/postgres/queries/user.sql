@@ -0,0 +1,17 @@
+-- name: InsertUser :one
+INSERT INTO users (name)
+VALUES ($1)
+ON CONFLICT (name) DO NOTHING
+RETURNING id;
+
+-- name: FindAllUsers :many
+SELECT * FROM users
+ORDER BY name;
+
+-- name: FindUser :one
+SELECT * FROM users
+WHERE id = $1 LIMIT 1;
+
+-- name: FindUserByName :one
+SELECT * FROM users
+WHERE name = $1 LIMIT 1;
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type reminderRepository struct {
+	queries *gen.Queries
+}
+
+func NewReminderRepository(db *sql.DB) todo.ReminderRepository {
+	return &reminderRepository{queries: gen.New(db)}
+}
+
+func (r *reminderRepository) Insert(ctx context.Context, reminder *todo.Reminder) error {
+	params := gen.InsertReminderParams{
+		TaskID:   reminder.TaskID,
+		RemindAt: toNullTime(reminder.At),
+	}
+	if reminder.Before != nil {
+		params.BeforeSeconds = sql.NullInt64{Int64: int64(*reminder.Before / time.Second), Valid: true}
+	}
+	id, err := r.queries.InsertReminder(ctx, params)
+	if err != nil {
+		return err
+	}
+	reminder.ID = id
+	return nil
+}
+
+func (r *reminderRepository) FindAllByTaskID(ctx context.Context, taskID int64) ([]todo.Reminder, error) {
+	reminders, err := r.queries.FindRemindersByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return toReminders(reminders), nil
+}
+
+func (r *reminderRepository) FindByID(ctx context.Context, id int64) (*todo.Reminder, error) {
+	reminder, err := r.queries.FindReminder(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrReminderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	found := toReminder(reminder)
+	return &found, nil
+}
+
+func (r *reminderRepository) FindAllPending(ctx context.Context) ([]todo.Reminder, error) {
+	reminders, err := r.queries.FindPendingReminders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toReminders(reminders), nil
+}
+
+func (r *reminderRepository) Update(ctx context.Context, reminder *todo.Reminder) error {
+	n, err := r.queries.UpdateReminder(ctx, gen.UpdateReminderParams{
+		ID:        reminder.ID,
+		SentAt:    toNullTime(reminder.SentAt),
+		Attempts:  int32(reminder.Attempts),
+		LastError: reminder.LastError,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrReminderNotFound
+	}
+	return nil
+}
+
+func (r *reminderRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := r.queries.DeleteReminder(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrReminderNotFound
+	}
+	return nil
+}
+
+func (r *reminderRepository) DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error {
+	return r.queries.DeleteRemindersByTasks(ctx, taskIDs)
+}
+
+func toReminders(reminders []gen.Reminder) []todo.Reminder {
+	list := make([]todo.Reminder, 0, len(reminders))
+	for _, reminder := range reminders {
+		list = append(list, toReminder(reminder))
+	}
+	return list
+}
+
+func toReminder(reminder gen.Reminder) todo.Reminder {
+	found := todo.Reminder{
+		ID:        reminder.ID,
+		TaskID:    reminder.TaskID,
+		At:        fromNullTime(reminder.RemindAt),
+		SentAt:    fromNullTime(reminder.SentAt),
+		Attempts:  int(reminder.Attempts),
+		LastError: reminder.LastError,
+	}
+	if reminder.BeforeSeconds.Valid {
+		before := time.Duration(reminder.BeforeSeconds.Int64) * time.Second
+		found.Before = &before
+	}
+	return found
+}
@@ -3,55 +3,681 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/jarri-abidi/todo/pkg/postgres/gen"
 	"github.com/jarri-abidi/todo/pkg/todo"
 )
 
 type taskRepository struct {
+	db      *sql.DB
 	queries *gen.Queries
+	inTx    bool // whether queries are part of a unit of work's transaction already
 }
 
 func NewTaskRepository(db *sql.DB) todo.TaskRepository {
-	return &taskRepository{queries: gen.New(db)}
+	return &taskRepository{db: db, queries: gen.New(db)}
 }
 
 func (r *taskRepository) Insert(ctx context.Context, task *todo.Task) error {
-	inserted, err := r.queries.InsertTask(ctx, task.Name)
+	return r.withTx(ctx, func(q *gen.Queries) error {
+		return insertTask(ctx, q, task)
+	})
+}
+
+func (r *taskRepository) InsertAll(ctx context.Context, tasks []todo.Task) error {
+	return r.withTx(ctx, func(q *gen.Queries) error {
+		for i := range tasks {
+			if err := insertTask(ctx, q, &tasks[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *taskRepository) FindAll(ctx context.Context, query todo.TaskQuery) ([]todo.Task, error) {
+	var (
+		done            sql.NullBool
+		archived        sql.NullBool
+		status          = sql.NullString{String: string(query.Status), Valid: query.Status != ""}
+		assigneeID      = toNullID(query.AssigneeID)
+		checklistID     sql.NullInt64
+		parentID        sql.NullInt64
+		namePattern     = "%" + likeEscaper.Replace(query.Name) + "%"
+		createdAfter    = toNullBound(query.Created.After)
+		createdBefore   = toNullBound(query.Created.Before)
+		updatedAfter    = toNullBound(query.Updated.After)
+		updatedBefore   = toNullBound(query.Updated.Before)
+		completedAfter  = toNullBound(query.Completed.After)
+		completedBefore = toNullBound(query.Completed.Before)
+		dueAfter        = toNullBound(query.Due.After)
+		dueBefore       = toNullBound(query.Due.Before)
+		cursor          todo.Cursor
+		cursorID        sql.NullInt64
+		limit           = sql.NullInt32{Int32: int32(query.Limit), Valid: query.Limit > 0}
+	)
+	if query.Done != nil {
+		done = sql.NullBool{Bool: *query.Done, Valid: true}
+	}
+	if query.Archived != nil {
+		archived = sql.NullBool{Bool: *query.Archived, Valid: true}
+	}
+	if query.ChecklistID != nil {
+		checklistID = toNullID(*query.ChecklistID)
+	}
+	if query.ParentID != nil {
+		parentID = toNullID(*query.ParentID)
+	}
+	if query.After != nil {
+		cursor = *query.After
+		cursorID = sql.NullInt64{Int64: cursor.ID, Valid: true}
+	}
+
+	var (
+		tasks []gen.Task
+		err   error
+	)
+	switch query.SortBy {
+	case todo.SortByDueDate:
+		tasks, err = r.queries.FindTasksSortedByDueDate(ctx, gen.FindTasksSortedByDueDateParams{
+			Done:            done,
+			Archived:        archived,
+			Status:          status,
+			AssigneeID:      assigneeID,
+			ByChecklist:     query.ChecklistID != nil,
+			ChecklistID:     checklistID,
+			ByParent:        query.ParentID != nil,
+			ParentID:        parentID,
+			NamePattern:     namePattern,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
+			UpdatedAfter:    updatedAfter,
+			UpdatedBefore:   updatedBefore,
+			CompletedAfter:  completedAfter,
+			CompletedBefore: completedBefore,
+			DueAfter:        dueAfter,
+			DueBefore:       dueBefore,
+			ByTags:          len(query.Tags) > 0,
+			Tags:            query.Tags,
+			AllTags:         query.AllTags,
+			ByIDs:           query.IDs != nil,
+			IDs:             query.IDs,
+			ExcludeIDs:      query.ExcludeIDs,
+			CursorID:        cursorID,
+			Descending:      query.Descending,
+			CursorDueDate:   toNullTime(cursor.DueDate),
+			Limit:           limit,
+		})
+	case todo.SortByName:
+		tasks, err = r.queries.FindTasksSortedByName(ctx, gen.FindTasksSortedByNameParams{
+			Done:            done,
+			Archived:        archived,
+			Status:          status,
+			AssigneeID:      assigneeID,
+			ByChecklist:     query.ChecklistID != nil,
+			ChecklistID:     checklistID,
+			ByParent:        query.ParentID != nil,
+			ParentID:        parentID,
+			NamePattern:     namePattern,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
+			UpdatedAfter:    updatedAfter,
+			UpdatedBefore:   updatedBefore,
+			CompletedAfter:  completedAfter,
+			CompletedBefore: completedBefore,
+			DueAfter:        dueAfter,
+			DueBefore:       dueBefore,
+			ByTags:          len(query.Tags) > 0,
+			Tags:            query.Tags,
+			AllTags:         query.AllTags,
+			ByIDs:           query.IDs != nil,
+			IDs:             query.IDs,
+			ExcludeIDs:      query.ExcludeIDs,
+			CursorID:        cursorID,
+			Descending:      query.Descending,
+			CursorName:      cursor.Name,
+			Limit:           limit,
+		})
+	case todo.SortByCreatedAt:
+		tasks, err = r.queries.FindTasksSortedByCreatedAt(ctx, gen.FindTasksSortedByCreatedAtParams{
+			Done:            done,
+			Archived:        archived,
+			Status:          status,
+			AssigneeID:      assigneeID,
+			ByChecklist:     query.ChecklistID != nil,
+			ChecklistID:     checklistID,
+			ByParent:        query.ParentID != nil,
+			ParentID:        parentID,
+			NamePattern:     namePattern,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
+			UpdatedAfter:    updatedAfter,
+			UpdatedBefore:   updatedBefore,
+			CompletedAfter:  completedAfter,
+			CompletedBefore: completedBefore,
+			DueAfter:        dueAfter,
+			DueBefore:       dueBefore,
+			ByTags:          len(query.Tags) > 0,
+			Tags:            query.Tags,
+			AllTags:         query.AllTags,
+			ByIDs:           query.IDs != nil,
+			IDs:             query.IDs,
+			ExcludeIDs:      query.ExcludeIDs,
+			CursorID:        cursorID,
+			Descending:      query.Descending,
+			CursorCreatedAt: cursor.CreatedAt,
+			Limit:           limit,
+		})
+	case todo.SortByUpdatedAt:
+		tasks, err = r.queries.FindTasksSortedByUpdatedAt(ctx, gen.FindTasksSortedByUpdatedAtParams{
+			Done:            done,
+			Archived:        archived,
+			Status:          status,
+			AssigneeID:      assigneeID,
+			ByChecklist:     query.ChecklistID != nil,
+			ChecklistID:     checklistID,
+			ByParent:        query.ParentID != nil,
+			ParentID:        parentID,
+			NamePattern:     namePattern,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
+			UpdatedAfter:    updatedAfter,
+			UpdatedBefore:   updatedBefore,
+			CompletedAfter:  completedAfter,
+			CompletedBefore: completedBefore,
+			DueAfter:        dueAfter,
+			DueBefore:       dueBefore,
+			ByTags:          len(query.Tags) > 0,
+			Tags:            query.Tags,
+			AllTags:         query.AllTags,
+			ByIDs:           query.IDs != nil,
+			IDs:             query.IDs,
+			ExcludeIDs:      query.ExcludeIDs,
+			CursorID:        cursorID,
+			Descending:      query.Descending,
+			CursorUpdatedAt: cursor.UpdatedAt,
+			Limit:           limit,
+		})
+	case todo.SortByCompletedAt:
+		tasks, err = r.queries.FindTasksSortedByCompletedAt(ctx, gen.FindTasksSortedByCompletedAtParams{
+			Done:              done,
+			Archived:          archived,
+			Status:            status,
+			AssigneeID:        assigneeID,
+			ByChecklist:       query.ChecklistID != nil,
+			ChecklistID:       checklistID,
+			ByParent:          query.ParentID != nil,
+			ParentID:          parentID,
+			NamePattern:       namePattern,
+			CreatedAfter:      createdAfter,
+			CreatedBefore:     createdBefore,
+			UpdatedAfter:      updatedAfter,
+			UpdatedBefore:     updatedBefore,
+			CompletedAfter:    completedAfter,
+			CompletedBefore:   completedBefore,
+			DueAfter:          dueAfter,
+			DueBefore:         dueBefore,
+			ByTags:            len(query.Tags) > 0,
+			Tags:              query.Tags,
+			AllTags:           query.AllTags,
+			ByIDs:             query.IDs != nil,
+			IDs:               query.IDs,
+			ExcludeIDs:        query.ExcludeIDs,
+			CursorID:          cursorID,
+			Descending:        query.Descending,
+			CursorCompletedAt: toNullTime(cursor.CompletedAt),
+			Limit:             limit,
+		})
+	case todo.SortByPosition:
+		tasks, err = r.queries.FindTasksSortedByPosition(ctx, gen.FindTasksSortedByPositionParams{
+			Done:            done,
+			Archived:        archived,
+			Status:          status,
+			AssigneeID:      assigneeID,
+			ByChecklist:     query.ChecklistID != nil,
+			ChecklistID:     checklistID,
+			ByParent:        query.ParentID != nil,
+			ParentID:        parentID,
+			NamePattern:     namePattern,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
+			UpdatedAfter:    updatedAfter,
+			UpdatedBefore:   updatedBefore,
+			CompletedAfter:  completedAfter,
+			CompletedBefore: completedBefore,
+			DueAfter:        dueAfter,
+			DueBefore:       dueBefore,
+			ByTags:          len(query.Tags) > 0,
+			Tags:            query.Tags,
+			AllTags:         query.AllTags,
+			ByIDs:           query.IDs != nil,
+			IDs:             query.IDs,
+			ExcludeIDs:      query.ExcludeIDs,
+			CursorID:        cursorID,
+			Descending:      query.Descending,
+			CursorRank:      cursor.Rank,
+			Limit:           limit,
+		})
+	case todo.SortByID:
+		tasks, err = r.queries.FindTasksSortedByID(ctx, gen.FindTasksSortedByIDParams{
+			Done:            done,
+			Archived:        archived,
+			Status:          status,
+			AssigneeID:      assigneeID,
+			ByChecklist:     query.ChecklistID != nil,
+			ChecklistID:     checklistID,
+			ByParent:        query.ParentID != nil,
+			ParentID:        parentID,
+			NamePattern:     namePattern,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
+			UpdatedAfter:    updatedAfter,
+			UpdatedBefore:   updatedBefore,
+			CompletedAfter:  completedAfter,
+			CompletedBefore: completedBefore,
+			DueAfter:        dueAfter,
+			DueBefore:       dueBefore,
+			ByTags:          len(query.Tags) > 0,
+			Tags:            query.Tags,
+			AllTags:         query.AllTags,
+			ByIDs:           query.IDs != nil,
+			IDs:             query.IDs,
+			ExcludeIDs:      query.ExcludeIDs,
+			CursorID:        cursorID,
+			Descending:      query.Descending,
+			Limit:           limit,
+		})
+	default:
+		tasks, err = r.queries.FindTasksSortedByPriority(ctx, gen.FindTasksSortedByPriorityParams{
+			Done:            done,
+			Archived:        archived,
+			Status:          status,
+			AssigneeID:      assigneeID,
+			ByChecklist:     query.ChecklistID != nil,
+			ChecklistID:     checklistID,
+			ByParent:        query.ParentID != nil,
+			ParentID:        parentID,
+			NamePattern:     namePattern,
+			CreatedAfter:    createdAfter,
+			CreatedBefore:   createdBefore,
+			UpdatedAfter:    updatedAfter,
+			UpdatedBefore:   updatedBefore,
+			CompletedAfter:  completedAfter,
+			CompletedBefore: completedBefore,
+			DueAfter:        dueAfter,
+			DueBefore:       dueBefore,
+			ByTags:          len(query.Tags) > 0,
+			Tags:            query.Tags,
+			AllTags:         query.AllTags,
+			ByIDs:           query.IDs != nil,
+			IDs:             query.IDs,
+			ExcludeIDs:      query.ExcludeIDs,
+			CursorID:        cursorID,
+			Descending:      query.Descending,
+			CursorPriority:  int16(cursor.Priority),
+			CursorDueDate:   toNullTime(cursor.DueDate),
+			Limit:           limit,
+		})
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	task.ID = inserted.ID
-	return nil
+	return r.toTasks(ctx, tasks)
+}
+
+func (r *taskRepository) FindAllDueBetween(ctx context.Context, after, before time.Time) ([]todo.Task, error) {
+	tasks, err := r.queries.FindTasksDueBetween(ctx, gen.FindTasksDueBetweenParams{
+		After:  sql.NullTime{Time: after, Valid: !after.IsZero()},
+		Before: sql.NullTime{Time: before, Valid: !before.IsZero()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.toTasks(ctx, tasks)
+}
+
+func (r *taskRepository) FindAllByTags(ctx context.Context, tags []string, all bool) ([]todo.Task, error) {
+	find := r.queries.FindTasksWithAnyTag
+	if all {
+		find = r.queries.FindTasksWithAllTags
+	}
+
+	tasks, err := find(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	return r.toTasks(ctx, tasks)
 }
 
-func (r *taskRepository) FindAll(ctx context.Context) ([]todo.Task, error) {
-	var list []todo.Task
-	tasks, err := r.queries.FindAllTasks(ctx)
+func (r *taskRepository) FindAllTags(ctx context.Context) ([]todo.Tag, error) {
+	rows, err := r.queries.FindTagUsage(ctx)
 	if err != nil {
-		return list, err
+		return nil, err
+	}
+
+	tags := make([]todo.Tag, 0, len(rows))
+	for _, row := range rows {
+		tags = append(tags, todo.Tag{Name: row.Name, TaskCount: int(row.TaskCount)})
 	}
+	return tags, nil
+}
 
-	for _, task := range tasks {
-		list = append(list, todo.Task{ID: task.ID, Name: task.Name, Done: task.Done.Bool})
+func (r *taskRepository) FindAllByChecklistID(ctx context.Context, checklistID int64) ([]todo.Task, error) {
+	tasks, err := r.queries.FindTasksByChecklist(ctx, toNullID(checklistID))
+	if err != nil {
+		return nil, err
 	}
-	return list, nil
+	return r.toTasks(ctx, tasks)
 }
 
+func (r *taskRepository) FindAllByParentID(ctx context.Context, parentID int64) ([]todo.Task, error) {
+	tasks, err := r.queries.FindSubtasks(ctx, toNullID(parentID))
+	if err != nil {
+		return nil, err
+	}
+	return r.toTasks(ctx, tasks)
+}
+
+// FindByID locks the row of the task until the transaction ends if the repository is part of
+// a unit of work, so that the task can't change between being read and being written back.
 func (r *taskRepository) FindByID(ctx context.Context, id int64) (*todo.Task, error) {
-	task, err := r.queries.FindTask(ctx, id)
+	find := r.queries.FindTask
+	if r.inTx {
+		find = r.queries.FindTaskForUpdate
+	}
+	task, err := find(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrTaskNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &todo.Task{ID: task.ID, Name: task.Name, Done: task.Done.Bool}, nil
+
+	tasks, err := r.toTasks(ctx, []gen.Task{task})
+	if err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
 }
 
 func (r *taskRepository) Update(ctx context.Context, task *todo.Task) error {
-	return r.queries.UpdateTask(ctx, gen.UpdateTaskParams{
-		ID: task.ID, Name: task.Name, Done: sql.NullBool{Bool: task.Done},
+	return r.withTx(ctx, func(q *gen.Queries) error {
+		version, err := q.UpdateTask(ctx, gen.UpdateTaskParams{
+			ID:              task.ID,
+			Name:            task.Name,
+			Done:            sql.NullBool{Bool: task.Done, Valid: true},
+			DueDate:         toNullTime(task.DueDate),
+			Priority:        int16(task.Priority),
+			ChecklistID:     toNullID(task.ChecklistID),
+			ParentID:        toNullID(task.ParentID),
+			Recurrence:      toNullRecurrence(task.Recurrence),
+			UpdatedAt:       task.UpdatedAt,
+			CompletedAt:     toNullTime(task.CompletedAt),
+			ArchivedAt:      toNullTime(task.ArchivedAt),
+			Description:     task.Description,
+			Rank:            task.Rank,
+			Status:          string(task.Status),
+			AssigneeID:      toNullID(task.AssigneeID),
+			EstimateSeconds: int64(task.Estimate / time.Second),
+			Version:         sql.NullInt64{Int64: task.Version, Valid: task.Version != 0},
+		})
+		if err == sql.ErrNoRows {
+			// either there is no such task or its version didn't match
+			_, err := q.FindTask(ctx, task.ID)
+			if err == sql.ErrNoRows {
+				return todo.ErrTaskNotFound
+			}
+			if err != nil {
+				return err
+			}
+			return todo.ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+		task.Version = version
+		return setTags(ctx, q, task.ID, task.Tags)
+	})
+}
+
+func (r *taskRepository) DeleteByID(ctx context.Context, id, version int64, at time.Time) error {
+	return r.withTx(ctx, func(q *gen.Queries) error {
+		if version != 0 {
+			// locks the task so that it can't be updated before it's trashed
+			task, err := q.FindTaskForUpdate(ctx, id)
+			if err == sql.ErrNoRows {
+				return todo.ErrTaskNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if task.Version != version {
+				return todo.ErrVersionConflict
+			}
+		}
+
+		n, err := q.TrashTaskTree(ctx, gen.TrashTaskTreeParams{ID: id, DeletedAt: toNullTime(&at)})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return todo.ErrTaskNotFound
+		}
+		return nil
+	})
+}
+
+func (r *taskRepository) DeleteAllByChecklistID(ctx context.Context, checklistID int64) error {
+	return r.queries.DeleteTasksByChecklist(ctx, toNullID(checklistID))
+}
+
+func (r *taskRepository) FindAllDeleted(ctx context.Context) ([]todo.Task, error) {
+	tasks, err := r.queries.FindTrashedTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.toTasks(ctx, tasks)
+}
+
+func (r *taskRepository) RestoreByID(ctx context.Context, id int64) error {
+	return r.withTx(ctx, func(q *gen.Queries) error {
+		task, err := q.FindTrashedTask(ctx, id)
+		if err == sql.ErrNoRows {
+			return todo.ErrTaskNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if task.ParentID.Valid {
+			// FindTask leaves out trashed tasks, and the foreign key guarantees the parent exists
+			_, err := q.FindTask(ctx, task.ParentID.Int64)
+			if err == sql.ErrNoRows {
+				return todo.ErrParentTaskTrashed
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = q.RestoreTaskTree(ctx, id)
+		return err
 	})
 }
 
-func (r *taskRepository) DeleteByID(ctx context.Context, id int64) error {
-	return r.queries.DeleteTask(ctx, id)
+func (r *taskRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return r.queries.PurgeTasksTrashedBefore(ctx, sql.NullTime{Time: before, Valid: true})
+}
+
+// insertTask inserts the task along with its tags and sets its ID and Version.
+// A task given an ID that's already taken, even by a task in the trash, isn't inserted.
+func insertTask(ctx context.Context, q *gen.Queries, task *todo.Task) error {
+	if task.ID != 0 {
+		exists, err := q.TaskExists(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return todo.ErrTaskAlreadyExists
+		}
+	}
+
+	inserted, err := q.InsertTask(ctx, gen.InsertTaskParams{
+		ChecklistID:     toNullID(task.ChecklistID),
+		ParentID:        toNullID(task.ParentID),
+		AssigneeID:      toNullID(task.AssigneeID),
+		Name:            task.Name,
+		Description:     task.Description,
+		Done:            sql.NullBool{Bool: task.Done, Valid: true},
+		Status:          string(task.Status),
+		DueDate:         toNullTime(task.DueDate),
+		Priority:        int16(task.Priority),
+		Recurrence:      toNullRecurrence(task.Recurrence),
+		EstimateSeconds: int64(task.Estimate / time.Second),
+		CreatedAt:       task.CreatedAt,
+		UpdatedAt:       task.UpdatedAt,
+		CompletedAt:     toNullTime(task.CompletedAt),
+		Rank:            task.Rank,
+		ArchivedAt:      toNullTime(task.ArchivedAt),
+	})
+	if err != nil {
+		return err
+	}
+	task.ID = inserted.ID
+	task.Version = inserted.Version
+	return setTags(ctx, q, task.ID, task.Tags)
+}
+
+func (r *taskRepository) ArchiveCompletedBefore(ctx context.Context, before, at time.Time) (int64, error) {
+	return r.queries.ArchiveTasksCompletedBefore(ctx, gen.ArchiveTasksCompletedBeforeParams{
+		ArchivedAt:  sql.NullTime{Time: at, Valid: true},
+		CompletedAt: sql.NullTime{Time: before, Valid: true},
+	})
+}
+
+// withTx runs fn inside a database transaction, committing only if fn succeeds.
+// If the repository is part of a unit of work, fn runs inside the unit's transaction.
+func (r *taskRepository) withTx(ctx context.Context, fn func(*gen.Queries) error) error {
+	if r.inTx {
+		return fn(r.queries)
+	}
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(r.queries.WithTx(tx))
+	})
+}
+
+// toTasks maps database rows to domain tasks and loads their tags.
+func (r *taskRepository) toTasks(ctx context.Context, rows []gen.Task) ([]todo.Task, error) {
+	tasks := make([]todo.Task, 0, len(rows))
+	ids := make([]int64, 0, len(rows))
+	index := make(map[int64]int, len(rows))
+	for i, row := range rows {
+		task, err := toTask(row)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+		ids = append(ids, row.ID)
+		index[row.ID] = i
+	}
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+
+	tags, err := r.queries.FindTaskTags(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		task := &tasks[index[tag.TaskID]]
+		task.Tags = append(task.Tags, tag.Name)
+	}
+	return tasks, nil
+}
+
+// setTags replaces the tags of a task, creating any tags that don't exist yet.
+func setTags(ctx context.Context, q *gen.Queries, taskID int64, tags []string) error {
+	if err := q.DeleteTaskTags(ctx, taskID); err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		tagID, err := q.UpsertTag(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := q.InsertTaskTag(ctx, gen.InsertTaskTagParams{TaskID: taskID, TagID: tagID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toTask(task gen.Task) (todo.Task, error) {
+	recurrence, err := todo.ParseRecurrence(task.Recurrence.String)
+	if err != nil {
+		return todo.Task{}, errors.Wrapf(err, "could not parse recurrence of task %d", task.ID)
+	}
+
+	return todo.Task{
+		ID:          task.ID,
+		ChecklistID: task.ChecklistID.Int64,
+		ParentID:    task.ParentID.Int64,
+		AssigneeID:  task.AssigneeID.Int64,
+		Name:        task.Name,
+		Description: task.Description,
+		Done:        task.Done.Bool,
+		Status:      todo.Status(task.Status),
+		DueDate:     fromNullTime(task.DueDate),
+		Priority:    todo.Priority(task.Priority),
+		Recurrence:  recurrence,
+		Estimate:    time.Duration(task.EstimateSeconds) * time.Second,
+		Version:     task.Version,
+		DeletedAt:   fromNullTime(task.DeletedAt),
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		CompletedAt: fromNullTime(task.CompletedAt),
+		ArchivedAt:  fromNullTime(task.ArchivedAt),
+		Rank:        task.Rank,
+	}, nil
+}
+
+// likeEscaper escapes the wildcards of ILIKE patterns so that names are matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// toNullID maps the zero ID, which stands for "no reference", to NULL.
+func toNullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// toNullRecurrence stores a recurrence as its RRULE, or NULL if the task doesn't repeat.
+func toNullRecurrence(r *todo.Recurrence) sql.NullString {
+	if r == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: r.String(), Valid: true}
+}
+
+// toNullBound maps the zero time, which leaves a todo.TimeRange open, to NULL.
+func toNullBound(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func fromNullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
 }
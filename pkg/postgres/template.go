@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type templateRepository struct {
+	queries *gen.Queries
+}
+
+func NewTemplateRepository(db *sql.DB) todo.TemplateRepository {
+	return &templateRepository{queries: gen.New(db)}
+}
+
+func (r *templateRepository) Insert(ctx context.Context, template *todo.Template) error {
+	tasks, err := json.Marshal(toTemplateTaskRows(template.Tasks))
+	if err != nil {
+		return errors.Wrap(err, "could not encode template tasks")
+	}
+
+	id, err := r.queries.InsertTemplate(ctx, gen.InsertTemplateParams{Name: template.Name, Tasks: tasks})
+	if err != nil {
+		return err
+	}
+	template.ID = id
+	return nil
+}
+
+func (r *templateRepository) FindAll(ctx context.Context) ([]todo.Template, error) {
+	templates, err := r.queries.FindAllTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]todo.Template, 0, len(templates))
+	for _, template := range templates {
+		t, err := toTemplate(template)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+func (r *templateRepository) FindByID(ctx context.Context, id int64) (*todo.Template, error) {
+	template, err := r.queries.FindTemplate(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	found, err := toTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+func (r *templateRepository) Update(ctx context.Context, template *todo.Template) error {
+	tasks, err := json.Marshal(toTemplateTaskRows(template.Tasks))
+	if err != nil {
+		return errors.Wrap(err, "could not encode template tasks")
+	}
+
+	n, err := r.queries.UpdateTemplate(ctx, gen.UpdateTemplateParams{ID: template.ID, Name: template.Name, Tasks: tasks})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrTemplateNotFound
+	}
+	return nil
+}
+
+func (r *templateRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := r.queries.DeleteTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrTemplateNotFound
+	}
+	return nil
+}
+
+// templateTaskRow is how each of a template's tasks is stored in its JSON array of tasks.
+type templateTaskRow struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Estimate    int64    `json:"estimate_seconds,omitempty"`
+	DueOffset   *int64   `json:"due_offset_seconds,omitempty"`
+}
+
+func toTemplateTaskRows(tasks []todo.TemplateTask) []templateTaskRow {
+	rows := make([]templateTaskRow, 0, len(tasks))
+	for _, task := range tasks {
+		row := templateTaskRow{
+			Name:        task.Name,
+			Description: task.Description,
+			Priority:    int(task.Priority),
+			Tags:        task.Tags,
+			Estimate:    int64(task.Estimate / time.Second),
+		}
+		if task.DueOffset != nil {
+			offset := int64(*task.DueOffset / time.Second)
+			row.DueOffset = &offset
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func toTemplate(template gen.Template) (todo.Template, error) {
+	var rows []templateTaskRow
+	if err := json.Unmarshal(template.Tasks, &rows); err != nil {
+		return todo.Template{}, errors.Wrapf(err, "could not decode tasks of template %d", template.ID)
+	}
+
+	tasks := make([]todo.TemplateTask, 0, len(rows))
+	for _, row := range rows {
+		task := todo.TemplateTask{
+			Name:        row.Name,
+			Description: row.Description,
+			Priority:    todo.Priority(row.Priority),
+			Tags:        row.Tags,
+			Estimate:    time.Duration(row.Estimate) * time.Second,
+		}
+		if row.DueOffset != nil {
+			offset := time.Duration(*row.DueOffset) * time.Second
+			task.DueOffset = &offset
+		}
+		tasks = append(tasks, task)
+	}
+	return todo.Template{ID: template.ID, Name: template.Name, Tasks: tasks}, nil
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type timeEntryRepository struct {
+	queries *gen.Queries
+}
+
+func NewTimeEntryRepository(db *sql.DB) todo.TimeEntryRepository {
+	return &timeEntryRepository{queries: gen.New(db)}
+}
+
+func (r *timeEntryRepository) Insert(ctx context.Context, entry *todo.TimeEntry) error {
+	id, err := r.queries.InsertTimeEntry(ctx, gen.InsertTimeEntryParams{
+		TaskID:    entry.TaskID,
+		UserName:  entry.User,
+		StartedAt: entry.Start,
+		EndedAt:   toNullTime(entry.End),
+	})
+	if err == sql.ErrNoRows {
+		// the insert was skipped because the user already has a timer running
+		return todo.ErrTimerRunning
+	}
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+	return nil
+}
+
+func (r *timeEntryRepository) FindAllByTaskIDs(ctx context.Context, taskIDs []int64) ([]todo.TimeEntry, error) {
+	entries, err := r.queries.FindTimeEntriesByTasks(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]todo.TimeEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, toTimeEntry(entry))
+	}
+	return list, nil
+}
+
+func (r *timeEntryRepository) FindByID(ctx context.Context, id int64) (*todo.TimeEntry, error) {
+	entry, err := r.queries.FindTimeEntry(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrTimeEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	found := toTimeEntry(entry)
+	return &found, nil
+}
+
+func (r *timeEntryRepository) FindRunning(ctx context.Context, user string) (*todo.TimeEntry, error) {
+	entry, err := r.queries.FindRunningTimeEntry(ctx, user)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrTimeEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	found := toTimeEntry(entry)
+	return &found, nil
+}
+
+func (r *timeEntryRepository) Update(ctx context.Context, entry *todo.TimeEntry) error {
+	n, err := r.queries.UpdateTimeEntry(ctx, gen.UpdateTimeEntryParams{
+		ID:        entry.ID,
+		StartedAt: entry.Start,
+		EndedAt:   toNullTime(entry.End),
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrTimeEntryNotFound
+	}
+	return nil
+}
+
+func (r *timeEntryRepository) DeleteByID(ctx context.Context, id int64) error {
+	n, err := r.queries.DeleteTimeEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrTimeEntryNotFound
+	}
+	return nil
+}
+
+func (r *timeEntryRepository) DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error {
+	return r.queries.DeleteTimeEntriesByTasks(ctx, taskIDs)
+}
+
+func toTimeEntry(entry gen.TimeEntry) todo.TimeEntry {
+	return todo.TimeEntry{
+		ID:     entry.ID,
+		TaskID: entry.TaskID,
+		User:   entry.UserName,
+		Start:  entry.StartedAt.UTC(),
+		End:    fromNullTime(entry.EndedAt),
+	}
+}
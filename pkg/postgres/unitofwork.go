@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+// NewRepositories returns postgres implementations of all the repositories.
+func NewRepositories(db *sql.DB) todo.Repositories {
+	return todo.Repositories{
+		Tasks:        NewTaskRepository(db),
+		Checklists:   NewChecklistRepository(db),
+		History:      NewHistoryRepository(db),
+		Comments:     NewCommentRepository(db),
+		Dependencies: NewDependencyRepository(db),
+		Users:        NewUserRepository(db),
+		TimeEntries:  NewTimeEntryRepository(db),
+		Templates:    NewTemplateRepository(db),
+		Reminders:    NewReminderRepository(db),
+		Attachments:  NewAttachmentRepository(db),
+	}
+}
+
+// unitOfWorkLock is the key of the advisory lock every unit of work holds until its transaction ends.
+const unitOfWorkLock = 0x746f646f
+
+type unitOfWork struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork returns a todo.UnitOfWork whose repositories make their changes in one database transaction.
+// The transactions run at read committed, so a unit of work first takes an advisory lock that all units of
+// work share, which makes them run one at a time. Otherwise, checks that read several rows, like whether a
+// dependency would make a cycle or which rank comes last, could pass in two units of work at once and leave
+// the tasks in a state neither would have allowed.
+func NewUnitOfWork(db *sql.DB) todo.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(todo.Repositories) error) error {
+	return inTx(ctx, u.db, func(tx *sql.Tx) error {
+		queries := gen.New(u.db).WithTx(tx)
+		if err := queries.LockTasks(ctx, unitOfWorkLock); err != nil {
+			return errors.Wrap(err, "could not lock unit of work")
+		}
+		return fn(todo.Repositories{
+			Tasks:        &taskRepository{db: u.db, queries: queries, inTx: true},
+			Checklists:   &checklistRepository{queries: queries},
+			History:      &historyRepository{queries: queries},
+			Comments:     &commentRepository{queries: queries},
+			Dependencies: &dependencyRepository{queries: queries},
+			Users:        &userRepository{queries: queries},
+			TimeEntries:  &timeEntryRepository{queries: queries},
+			Templates:    &templateRepository{queries: queries},
+			Reminders:    &reminderRepository{queries: queries},
+			Attachments:  &attachmentRepository{queries: queries},
+		})
+	})
+}
+
+// inTx runs fn inside a database transaction, committing only if fn succeeds.
+func inTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Wrapf(err, "could not rollback transaction: %v", rbErr)
+		}
+		return err
+	}
+	return errors.Wrap(tx.Commit(), "could not commit transaction")
+}
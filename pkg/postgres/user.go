@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jarri-abidi/todo/pkg/postgres/gen"
+	"github.com/jarri-abidi/todo/pkg/todo"
+)
+
+type userRepository struct {
+	queries *gen.Queries
+}
+
+func NewUserRepository(db *sql.DB) todo.UserRepository {
+	return &userRepository{queries: gen.New(db)}
+}
+
+func (r *userRepository) Insert(ctx context.Context, user *todo.User) error {
+	id, err := r.queries.InsertUser(ctx, user.Name)
+	if err == sql.ErrNoRows {
+		// the insert was skipped because the name is taken
+		return todo.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+	user.ID = id
+	return nil
+}
+
+func (r *userRepository) FindAll(ctx context.Context) ([]todo.User, error) {
+	users, err := r.queries.FindAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]todo.User, 0, len(users))
+	for _, user := range users {
+		list = append(list, todo.User{ID: user.ID, Name: user.Name})
+	}
+	return list, nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id int64) (*todo.User, error) {
+	user, err := r.queries.FindUser(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &todo.User{ID: user.ID, Name: user.Name}, nil
+}
+
+func (r *userRepository) FindByName(ctx context.Context, name string) (*todo.User, error) {
+	user, err := r.queries.FindUserByName(ctx, name)
+	if err == sql.ErrNoRows {
+		return nil, todo.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &todo.User{ID: user.ID, Name: user.Name}, nil
+}
@@ -0,0 +1,50 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrAttachmentNotFound     = errors.New("attachment not found")
+	ErrInvalidAttachmentName  = errors.New("attachment must have a file name")
+	ErrAttachmentTooLarge     = errors.New("attachment is larger than allowed")
+	ErrUnsupportedContentType = errors.New("attachment type is not allowed")
+	ErrBlobNotFound           = errors.New("blob not found")
+)
+
+// Attachment describes a file, such as a screenshot or a log, attached to a task.
+// The content itself is kept in a BlobStore under Key.
+type Attachment struct {
+	ID          int64
+	TaskID      int64
+	Name        string // the name of the file as it was uploaded
+	ContentType string
+	Size        int64
+	Key         string
+	UploadedBy  string
+	CreatedAt   time.Time
+}
+
+// AttachmentRepository is the interface used to persist the Attachment(s) of tasks.
+type AttachmentRepository interface {
+	Insert(context.Context, *Attachment) error
+	// FindAllByTaskIDs returns the attachments of any of the tasks in the order they were added.
+	FindAllByTaskIDs(ctx context.Context, taskIDs []int64) ([]Attachment, error)
+	FindByID(ctx context.Context, id int64) (*Attachment, error)
+	DeleteByID(ctx context.Context, id int64) error
+	// DeleteAllByTaskIDs deletes the attachments of any of the tasks.
+	DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error
+}
+
+// BlobStore keeps the content of attachments, e.g. on disk or in an S3 bucket.
+type BlobStore interface {
+	// Put stores everything read from r under key and returns how many bytes that was.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (size int64, err error)
+	// Get returns the content stored under key, or ErrBlobNotFound. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete deletes the content stored under key, if there is any.
+	Delete(ctx context.Context, key string) error
+}
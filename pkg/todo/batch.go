@@ -0,0 +1,38 @@
+package todo
+
+import "errors"
+
+// MaxBatchSize is how many operations a batch may have at most.
+const MaxBatchSize = 100
+
+var (
+	ErrInvalidBatchSize = errors.New("batch must have at least one and at most 100 operations")
+	ErrInvalidBatchOp   = errors.New("batch operation must be one of create, update, toggle or delete")
+	ErrBatchRolledBack  = errors.New("operation was rolled back because another one in the batch failed")
+	ErrBatchSkipped     = errors.New("operation was skipped because another one in the batch failed")
+	ErrBatchNotAtomic   = errors.New("batch can't be run all or nothing without transactions")
+)
+
+// BatchOp is the kind of change a BatchOperation makes.
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchToggle BatchOp = "toggle"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchOperation is one of the changes to tasks that are made together in a batch.
+type BatchOperation struct {
+	Op   BatchOp
+	Task Task  // the task to create, or to replace if Op is BatchUpdate
+	ID   int64 // the task to toggle or delete
+}
+
+// BatchResult is the outcome of one of the operations of a batch.
+type BatchResult struct {
+	Task    *Task // the task as the operation left it, nil if it failed or deleted the task
+	Created bool  // whether an update created the task because it didn't exist
+	Err     error
+}
@@ -0,0 +1,23 @@
+package todo
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrChecklistNotFound = errors.New("checklist not found")
+
+// Checklist is a named list that groups related tasks, e.g. one per project.
+type Checklist struct {
+	ID   int64
+	Name string
+}
+
+// ChecklistRepository is the interface used to persist the Checklist(s).
+type ChecklistRepository interface {
+	Insert(context.Context, *Checklist) error
+	FindAll(context.Context) ([]Checklist, error)
+	FindByID(ctx context.Context, id int64) (*Checklist, error)
+	Update(context.Context, *Checklist) error
+	DeleteByID(ctx context.Context, id int64) error
+}
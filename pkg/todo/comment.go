@@ -0,0 +1,35 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrEmptyComment    = errors.New("comment must not be empty")
+)
+
+// Comment is a note left on a task as part of the discussion around it.
+type Comment struct {
+	ID        int64
+	TaskID    int64
+	Author    string
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CommentRepository is the interface used to persist the Comment(s).
+type CommentRepository interface {
+	Insert(context.Context, *Comment) error
+	// FindAllByTaskID returns the comments on the task, oldest first.
+	FindAllByTaskID(ctx context.Context, taskID int64) ([]Comment, error)
+	FindByID(ctx context.Context, id int64) (*Comment, error)
+	// Update replaces the body and update time of the comment.
+	Update(context.Context, *Comment) error
+	DeleteByID(ctx context.Context, id int64) error
+	// DeleteAllByTaskIDs deletes the comments on any of the tasks.
+	DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error
+}
@@ -0,0 +1,55 @@
+package todo
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrTaskBlocked        = errors.New("task is blocked by tasks that are not done")
+)
+
+// Dependency records that a task can't be done before its blocker is.
+type Dependency struct {
+	TaskID    int64
+	BlockerID int64
+}
+
+// DependencyRepository is the interface used to persist the Dependency(s) between tasks.
+type DependencyRepository interface {
+	// Insert adds the dependency unless it already exists.
+	Insert(context.Context, Dependency) error
+	FindAll(context.Context) ([]Dependency, error)
+	FindAllByTaskID(ctx context.Context, taskID int64) ([]Dependency, error)
+	Delete(context.Context, Dependency) error
+	// DeleteAllByTaskIDs deletes the dependencies of and on any of the tasks.
+	DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error
+}
+
+// CreatesCycle reports whether adding dep to deps would make a task depend on itself,
+// directly or through other tasks.
+func CreatesCycle(deps []Dependency, dep Dependency) bool {
+	blockers := make(map[int64][]int64)
+	for _, d := range deps {
+		blockers[d.TaskID] = append(blockers[d.TaskID], d.BlockerID)
+	}
+
+	// walk everything the new blocker transitively depends on, looking for the task
+	seen := make(map[int64]bool)
+	stack := []int64{dep.BlockerID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == dep.TaskID {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		stack = append(stack, blockers[id]...)
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"context"
+	"time"
+)
+
+// Operation is the kind of change a HistoryEntry records.
+type Operation string
+
+const (
+	OperationSave       Operation = "save"
+	OperationToggleDone Operation = "toggle_done"
+	OperationUpdate     Operation = "update"
+	OperationRemove     Operation = "remove"
+	OperationRestore    Operation = "restore"
+	OperationMove       Operation = "move"
+	OperationTransition Operation = "transition"
+	OperationAssign     Operation = "assign"
+	OperationUnassign   Operation = "unassign"
+)
+
+// AnonymousActor is who changes are attributed to when nobody else is known.
+const AnonymousActor = "anonymous"
+
+// HistoryEntry is an immutable record of a change made to a task.
+// Before is nil if the change created the task and After is nil if it removed it.
+type HistoryEntry struct {
+	ID        int64
+	TaskID    int64
+	Actor     string
+	Time      time.Time
+	Operation Operation
+	Before    *Task
+	After     *Task
+}
+
+// HistoryRepository is the interface used to persist HistoryEntry(s).
+// Entries are only ever added, never changed or deleted.
+type HistoryRepository interface {
+	// Insert adds the entry and sets its ID, which increases with every entry.
+	Insert(context.Context, *HistoryEntry) error
+	// FindAllByTaskID returns the task's entries with an ID greater than afterID,
+	// oldest first and no more than limit of them unless limit is zero.
+	FindAllByTaskID(ctx context.Context, taskID, afterID int64, limit int) ([]HistoryEntry, error)
+}
+
+type actorKey struct{}
+
+// ContextWithActor returns a copy of ctx that attributes the changes made with it to actor.
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns who the changes made with ctx are attributed to.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
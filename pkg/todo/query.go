@@ -0,0 +1,261 @@
+package todo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSortField = errors.New("invalid sort field")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+// SortField is what a list of tasks is ordered by. Ties are always broken by ID
+// so that every task has a stable position to resume pagination from.
+type SortField string
+
+const (
+	// SortByPriority lists higher priorities first, then earlier due dates, as Task.Less does.
+	SortByPriority SortField = "priority"
+	// SortByDueDate lists earlier due dates first, with tasks that have no due date last.
+	SortByDueDate   SortField = "due_date"
+	SortByName      SortField = "name"
+	SortByID        SortField = "id"
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	// SortByCompletedAt lists tasks completed earlier first, with pending tasks last.
+	SortByCompletedAt SortField = "completed_at"
+	// SortByPosition lists tasks in the order the user arranged them, by Rank.
+	SortByPosition SortField = "position"
+)
+
+// ParseSortField returns the SortField with the given name.
+// An empty name yields SortByPosition, so that tasks are listed as the user arranged them.
+func ParseSortField(name string) (SortField, error) {
+	switch f := SortField(name); f {
+	case "":
+		return SortByPosition, nil
+	case SortByPriority, SortByDueDate, SortByName, SortByID,
+		SortByCreatedAt, SortByUpdatedAt, SortByCompletedAt, SortByPosition:
+		return f, nil
+	}
+	return "", ErrInvalidSortField
+}
+
+// TaskQuery filters, orders and paginates the tasks returned by TaskRepository.FindAll.
+// Its zero value matches every task in the order of Task.Less.
+type TaskQuery struct {
+	Done       *bool  // nil matches both pending and completed tasks
+	Archived   *bool  // nil matches both archived and unarchived tasks
+	Status     Status // empty matches any status
+	AssigneeID int64  // zero matches tasks assigned to anyone or nobody
+	Name       string // case-insensitive substring of the task name, empty matches any name
+
+	ChecklistID *int64 // nil matches tasks of any checklist, zero those of none
+	ParentID    *int64 // nil matches tasks with any parent, zero top-level tasks
+
+	Created   TimeRange
+	Updated   TimeRange
+	Completed TimeRange // a bounded range never matches pending tasks
+	Due       TimeRange // a bounded range never matches tasks without a due date
+
+	Tags    []string // empty matches any tags, otherwise tasks carrying any of them
+	AllTags bool     // matches only the tasks carrying every one of Tags
+
+	IDs        []int64 // nil matches any task, otherwise only the listed ones
+	ExcludeIDs []int64 // never matched
+
+	// Overdue, DueToday and Blocked depend on the current time and on the dependencies
+	// between tasks, so the service resolves them into the filters above before the
+	// query reaches a repository, which disregards them.
+	Overdue  bool           // matches pending tasks that are past their due date
+	DueToday *time.Location // nil matches any day, otherwise tasks due on the current day there
+	Blocked  *bool          // nil matches any task, otherwise pending tasks that are blocked or not
+
+	SortBy SortField // empty sorts by priority
+	// Descending reverses the natural order of SortBy, including its tie-breakers.
+	Descending bool
+
+	Limit int     // zero means no limit
+	After *Cursor // nil starts from the first task
+}
+
+// Matches reports whether the task passes the query's filters, disregarding pagination.
+func (q TaskQuery) Matches(t Task) bool {
+	if q.Done != nil && t.Done != *q.Done || q.Status != "" && t.Status != q.Status {
+		return false
+	}
+	if q.Archived != nil && (t.ArchivedAt != nil) != *q.Archived {
+		return false
+	}
+	if q.AssigneeID != 0 && t.AssigneeID != q.AssigneeID {
+		return false
+	}
+	if q.ChecklistID != nil && t.ChecklistID != *q.ChecklistID || q.ParentID != nil && t.ParentID != *q.ParentID {
+		return false
+	}
+	if !q.Created.Contains(&t.CreatedAt) || !q.Updated.Contains(&t.UpdatedAt) || !q.Completed.Contains(t.CompletedAt) {
+		return false
+	}
+	if !q.Due.Contains(t.DueDate) || len(q.Tags) > 0 && !t.HasTags(q.Tags, q.AllTags) {
+		return false
+	}
+	if q.IDs != nil && !containsID(q.IDs, t.ID) || containsID(q.ExcludeIDs, t.ID) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(t.Name), strings.ToLower(q.Name))
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Less reports whether t is listed before u in the query's order.
+func (q TaskQuery) Less(t, u Task) bool {
+	if q.Descending {
+		t, u = u, t
+	}
+
+	switch q.SortBy {
+	case SortByDueDate:
+		if (t.DueDate == nil) != (u.DueDate == nil) {
+			return t.DueDate != nil
+		}
+		if t.DueDate != nil && !t.DueDate.Equal(*u.DueDate) {
+			return t.DueDate.Before(*u.DueDate)
+		}
+	case SortByName:
+		if t.Name != u.Name {
+			return t.Name < u.Name
+		}
+	case SortByCreatedAt:
+		if !t.CreatedAt.Equal(u.CreatedAt) {
+			return t.CreatedAt.Before(u.CreatedAt)
+		}
+	case SortByUpdatedAt:
+		if !t.UpdatedAt.Equal(u.UpdatedAt) {
+			return t.UpdatedAt.Before(u.UpdatedAt)
+		}
+	case SortByCompletedAt:
+		if (t.CompletedAt == nil) != (u.CompletedAt == nil) {
+			return t.CompletedAt != nil
+		}
+		if t.CompletedAt != nil && !t.CompletedAt.Equal(*u.CompletedAt) {
+			return t.CompletedAt.Before(*u.CompletedAt)
+		}
+	case SortByPosition:
+		if t.Rank != u.Rank {
+			return t.Rank < u.Rank
+		}
+	case SortByID:
+	default:
+		return t.Less(u)
+	}
+	return t.ID < u.ID
+}
+
+// IsAfterCursor reports whether the task comes after the query's cursor,
+// i.e. whether it belongs on the requested page or one following it.
+func (q TaskQuery) IsAfterCursor(t Task) bool {
+	return q.After == nil || q.Less(q.After.task(), t)
+}
+
+// TimeRange matches the times from After up to, but not including, Before.
+// A zero bound leaves that side of the range open.
+type TimeRange struct {
+	After  time.Time
+	Before time.Time
+}
+
+// IsZero reports whether the range is open on both sides and so matches any time.
+func (r TimeRange) IsZero() bool {
+	return r.After.IsZero() && r.Before.IsZero()
+}
+
+// Contains reports whether t lies within the range. A nil time
+// is only contained in a range that is open on both sides.
+func (r TimeRange) Contains(t *time.Time) bool {
+	if t == nil {
+		return r.IsZero()
+	}
+	return (r.After.IsZero() || !t.Before(r.After)) && (r.Before.IsZero() || t.Before(r.Before))
+}
+
+// Intersect returns the range of the times contained in both r and o.
+func (r TimeRange) Intersect(o TimeRange) TimeRange {
+	if o.After.After(r.After) {
+		r.After = o.After
+	}
+	if !o.Before.IsZero() && (r.Before.IsZero() || o.Before.Before(r.Before)) {
+		r.Before = o.Before
+	}
+	return r
+}
+
+// Cursor marks the position of a task within any order of tasks,
+// so that the next page can start right after it.
+type Cursor struct {
+	ID          int64      `json:"id"`
+	Priority    Priority   `json:"p"`
+	DueDate     *time.Time `json:"d,omitempty"`
+	Name        string     `json:"n"`
+	CreatedAt   time.Time  `json:"c"`
+	UpdatedAt   time.Time  `json:"u"`
+	CompletedAt *time.Time `json:"f,omitempty"`
+	Rank        string     `json:"r,omitempty"`
+}
+
+// CursorAfter returns the cursor that resumes listing right after the given task.
+func CursorAfter(t Task) Cursor {
+	return Cursor{
+		ID:          t.ID,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		Name:        t.Name,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		CompletedAt: t.CompletedAt,
+		Rank:        t.Rank,
+	}
+}
+
+// ParseCursor decodes a cursor previously formatted with Cursor.String.
+func ParseCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// String encodes the cursor as an opaque, URL-safe token.
+func (c Cursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (c Cursor) task() Task {
+	return Task{
+		ID:          c.ID,
+		Priority:    c.Priority,
+		DueDate:     c.DueDate,
+		Name:        c.Name,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		CompletedAt: c.CompletedAt,
+		Rank:        c.Rank,
+	}
+}
@@ -0,0 +1,84 @@
+package todo
+
+import "errors"
+
+// ErrInvalidMoveTarget is returned when a task is moved next to a task it doesn't share its
+// checklist and parent with, as the two are ranked among different tasks.
+var ErrInvalidMoveTarget = errors.New("task can only be moved next to a task of the same checklist and parent")
+
+// rankDigits are the digits ranks are made of, in ascending order. Ranks are compared
+// byte by byte, which is how postgres compares them with the "C" collation as well.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// rankWidth is how many digits RankAfter pads ranks to before counting up from them,
+// which leaves room for 36^7 appends before a rank has to grow any longer.
+const rankWidth = 8
+
+// RankAfter returns a rank that sorts right after a, for appending a task after the last one.
+// Unlike RankBetween(a, ""), which halves the room left after a on every call, it counts up
+// from a as a number of rankWidth digits or more, so that ranks don't grow with every append.
+// An empty a stands for the start of the order.
+func RankAfter(a string) string {
+	if a == "" {
+		return RankBetween("", "")
+	}
+
+	rank := []byte(a)
+	for len(rank) < rankWidth {
+		rank = append(rank, rankDigits[0])
+	}
+	// count up until the rank doesn't end in the lowest digit, as RankBetween requires
+	for {
+		i := len(rank) - 1
+		for ; i >= 0 && rank[i] == rankDigits[len(rankDigits)-1]; i-- {
+			rank[i] = rankDigits[0]
+		}
+		if i < 0 {
+			// every digit is the highest one, so only a longer rank sorts after a
+			return a + RankBetween("", "")
+		}
+		rank[i] = rankDigits[rankDigit(rank[i])+1]
+		if rank[len(rank)-1] != rankDigits[0] {
+			return string(rank)
+		}
+	}
+}
+
+// RankBetween returns a rank that sorts after a and before b, so that a task can be moved
+// between two others without renumbering any task. An empty a stands for the start of the
+// order and an empty b for its end; otherwise a must sort before b. The ranks it returns
+// never end in the lowest digit, which guarantees there is always room before them.
+func RankBetween(a, b string) string {
+	var rank []byte
+	for i := 0; ; i++ {
+		lo, hi := 0, len(rankDigits)
+		if i < len(a) {
+			lo = rankDigit(a[i])
+		}
+		if b != "" && i < len(b) {
+			hi = rankDigit(b[i])
+		}
+
+		if lo == hi {
+			// still within the prefix both ranks share
+			rank = append(rank, rankDigits[lo])
+			continue
+		}
+		if mid := (lo + hi) / 2; mid > lo {
+			return string(append(rank, rankDigits[mid]))
+		}
+		// no digit fits between the two, so keep a's digit and look for room after it,
+		// where anything sorts before b
+		rank = append(rank, rankDigits[lo])
+		b = ""
+	}
+}
+
+func rankDigit(c byte) int {
+	for i := 0; i < len(rankDigits); i++ {
+		if rankDigits[i] == c {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,335 @@
+package todo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRecurrence is returned for recurrence rules that can't be parsed or aren't supported.
+type ErrInvalidRecurrence struct{ Reason string }
+
+func (e ErrInvalidRecurrence) Error() string {
+	return fmt.Sprintf("invalid recurrence rule: %s", e.Reason)
+}
+
+// Frequency is the base unit of time a Recurrence repeats in.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence describes how a task repeats, using a subset of RFC 5545 RRULEs:
+// FREQ, INTERVAL, BYDAY (weekly only, without ordinals), BYMONTHDAY (monthly only),
+// COUNT and UNTIL.
+type Recurrence struct {
+	Frequency  Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int // negative values count back from the end of the month
+	Count      int   // occurrences left including the current one, zero if unlimited
+	Until      *time.Time
+}
+
+// ParseRecurrence parses an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+// The shorthands "daily", "weekly", "monthly" and "yearly" are accepted as well.
+// An empty rule yields a nil Recurrence.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, nil
+	}
+
+	switch f := Frequency(strings.ToUpper(rule)); f {
+	case Daily, Weekly, Monthly, Yearly:
+		return &Recurrence{Frequency: f, Interval: 1}, nil
+	}
+
+	r := Recurrence{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, ErrInvalidRecurrence{fmt.Sprintf("malformed part %q", part)}
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[key] {
+			return nil, ErrInvalidRecurrence{fmt.Sprintf("%s given more than once", key)}
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Frequency = Frequency(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			return nil, ErrInvalidRecurrence{fmt.Sprintf("%s is not supported", key)}
+		}
+		if err != nil {
+			return nil, ErrInvalidRecurrence{fmt.Sprintf("invalid %s %q", key, value)}
+		}
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func parseUntil(value string) (*time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown time format")
+}
+
+func parseByDay(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, code := range strings.Split(value, ",") {
+		found := false
+		for day, c := range weekdayCodes {
+			if c == code {
+				days = append(days, time.Weekday(day))
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown weekday %q", code)
+		}
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, s := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Validate reports whether the recurrence only uses the supported subset of RRULEs.
+func (r Recurrence) Validate() error {
+	switch r.Frequency {
+	case Daily, Weekly, Monthly, Yearly:
+	case "":
+		return ErrInvalidRecurrence{"FREQ is required"}
+	default:
+		return ErrInvalidRecurrence{fmt.Sprintf("unknown FREQ %q", r.Frequency)}
+	}
+	if r.Interval < 1 {
+		return ErrInvalidRecurrence{"INTERVAL must be positive"}
+	}
+	if r.Count < 0 {
+		return ErrInvalidRecurrence{"COUNT must be positive"}
+	}
+	if r.Count > 0 && r.Until != nil {
+		return ErrInvalidRecurrence{"COUNT and UNTIL are mutually exclusive"}
+	}
+	if len(r.ByDay) > 0 && r.Frequency != Weekly {
+		return ErrInvalidRecurrence{"BYDAY is only supported with FREQ=WEEKLY"}
+	}
+	if len(r.ByMonthDay) > 0 && r.Frequency != Monthly {
+		return ErrInvalidRecurrence{"BYMONTHDAY is only supported with FREQ=MONTHLY"}
+	}
+	for _, day := range r.ByMonthDay {
+		if day == 0 || day < -31 || day > 31 {
+			return ErrInvalidRecurrence{fmt.Sprintf("BYMONTHDAY %d is out of range", day)}
+		}
+	}
+	return nil
+}
+
+// String formats the recurrence as an RRULE, which is also how it is persisted.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			codes = append(codes, weekdayCodes[day])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after t, keeping t's time of day.
+// It returns false once the recurrence is exhausted by COUNT or UNTIL.
+func (r Recurrence) Next(t time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	switch r.Frequency {
+	case Daily:
+		next = t.AddDate(0, 0, r.Interval)
+	case Weekly:
+		next = r.nextWeekly(t)
+	case Monthly:
+		next = r.nextMonthly(t)
+	case Yearly:
+		next = addMonthsClamped(t, 12*r.Interval, t.Day())
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Advance returns the recurrence as it applies to the next occurrence,
+// i.e. with one occurrence less left if it is limited by COUNT.
+func (r Recurrence) Advance() Recurrence {
+	if r.Count > 0 {
+		r.Count--
+	}
+	return r
+}
+
+func (r Recurrence) nextWeekly(t time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return t.AddDate(0, 0, 7*r.Interval)
+	}
+
+	// weeks start on Monday as per the RFC 5545 default of WKST=MO
+	startOfWeek := func(t time.Time) time.Time {
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+	}
+	week := startOfWeek(t)
+	for candidate := t.AddDate(0, 0, 1); ; candidate = candidate.AddDate(0, 0, 1) {
+		weeks := int(startOfWeek(candidate).Sub(week).Hours()+12) / (7 * 24)
+		if weeks%r.Interval != 0 {
+			continue
+		}
+		for _, day := range r.ByDay {
+			if candidate.Weekday() == day {
+				return candidate
+			}
+		}
+	}
+}
+
+func (r Recurrence) nextMonthly(t time.Time) time.Time {
+	if len(r.ByMonthDay) == 0 {
+		return addMonthsClamped(t, r.Interval, t.Day())
+	}
+
+	for months := 0; ; months += r.Interval {
+		first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		last := daysIn(first)
+
+		var days []int
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last + day + 1
+			}
+			if day >= 1 && day <= last {
+				days = append(days, day)
+			}
+		}
+		sort.Ints(days)
+
+		for _, day := range days {
+			if candidate := first.AddDate(0, 0, day-1); candidate.After(t) {
+				return candidate
+			}
+		}
+	}
+}
+
+// addMonthsClamped adds months to t and moves to the given day of that month,
+// clamping it to the last day of shorter months instead of overflowing.
+func addMonthsClamped(t time.Time, months, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := daysIn(first); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func daysIn(month time.Time) int {
+	return time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location()).Day()
+}
+
+// NextOccurrence returns the pending task that continues t's recurrence once t is done.
+// It is due at the first occurrence after both t's due date and now, so that occurrences
+// missed while the task was overdue are skipped rather than piling up.
+// It returns false if t doesn't repeat or its recurrence is exhausted.
+func (t Task) NextOccurrence(now time.Time) (Task, bool) {
+	if t.Recurrence == nil {
+		return Task{}, false
+	}
+
+	from := now
+	if t.DueDate != nil {
+		from = *t.DueDate
+	}
+
+	r := *t.Recurrence
+	due, ok := r.Next(from)
+	for ok && !due.After(now) {
+		r = r.Advance()
+		due, ok = r.Next(due)
+	}
+	if !ok {
+		return Task{}, false
+	}
+
+	r = r.Advance()
+	return Task{
+		ChecklistID: t.ChecklistID,
+		ParentID:    t.ParentID,
+		Name:        t.Name,
+		Description: t.Description,
+		Status:      StatusTodo,
+		Estimate:    t.Estimate,
+		DueDate:     &due,
+		Priority:    t.Priority,
+		Tags:        append([]string(nil), t.Tags...),
+		Recurrence:  &r,
+	}, true
+}
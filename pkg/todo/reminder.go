@@ -0,0 +1,68 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrReminderNotFound = errors.New("reminder not found")
+	ErrInvalidReminder  = errors.New("reminder must have either a time or a non-negative duration before the due date")
+	ErrTaskNotDue       = errors.New("task has no due date to be reminded before")
+)
+
+// Reminder asks for a notification about a task, either at a fixed time or some
+// time before the task is due. A relative reminder follows the due date if it moves.
+type Reminder struct {
+	ID     int64
+	TaskID int64
+	At     *time.Time     // nil if the reminder is relative to the due date
+	Before *time.Duration // nil if the reminder is at a fixed time
+	// SentAt is when the reminder was delivered, nil while it is pending.
+	SentAt *time.Time
+	// Attempts counts the deliveries tried so far and LastError
+	// holds why the last one failed, if it did.
+	Attempts  int
+	LastError string
+}
+
+// FireAt returns when the reminder is due to be sent for the task it belongs to.
+// It reports false if the reminder is relative to a due date the task doesn't have.
+func (r Reminder) FireAt(task Task) (time.Time, bool) {
+	if r.At != nil {
+		return *r.At, true
+	}
+	if r.Before == nil || task.DueDate == nil {
+		return time.Time{}, false
+	}
+	return task.DueDate.Add(-*r.Before), true
+}
+
+// Notification is what a Notifier delivers when a reminder about a task fires.
+type Notification struct {
+	Reminder Reminder
+	Task     Task
+}
+
+// Notifier delivers notifications, e.g. by email or by calling a webhook.
+// Reminders are delivered at least once, so a notification may be repeated
+// if it couldn't be recorded as sent after it was delivered.
+type Notifier interface {
+	Notify(context.Context, Notification) error
+}
+
+// ReminderRepository is the interface used to persist the Reminder(s) of tasks.
+type ReminderRepository interface {
+	Insert(context.Context, *Reminder) error
+	// FindAllByTaskID returns the reminders of the task in the order they were added.
+	FindAllByTaskID(ctx context.Context, taskID int64) ([]Reminder, error)
+	FindByID(ctx context.Context, id int64) (*Reminder, error)
+	// FindAllPending returns the reminders that haven't been sent yet in the order they were added.
+	FindAllPending(context.Context) ([]Reminder, error)
+	// Update replaces the delivery state of the stored reminder, i.e. SentAt, Attempts and LastError.
+	Update(context.Context, *Reminder) error
+	DeleteByID(ctx context.Context, id int64) error
+	// DeleteAllByTaskIDs deletes the reminders of any of the tasks.
+	DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error
+}
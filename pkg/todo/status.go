@@ -0,0 +1,74 @@
+package todo
+
+import "errors"
+
+var (
+	ErrInvalidStatus     = errors.New("invalid task status")
+	ErrIllegalTransition = errors.New("task cannot move from its current status to the requested one")
+)
+
+// Status is the stage of the workflow a task is in.
+// A task is Done exactly when its status is StatusDone.
+type Status string
+
+const (
+	StatusTodo       Status = "todo"
+	StatusInProgress Status = "in_progress"
+	StatusReview     Status = "review"
+	StatusDone       Status = "done"
+	StatusWontDo     Status = "wont_do"
+)
+
+// ParseStatus returns the Status with the given name.
+// An empty name yields the empty Status, which means none was given.
+func ParseStatus(name string) (Status, error) {
+	s := Status(name)
+	if s != "" && !s.IsValid() {
+		return "", ErrInvalidStatus
+	}
+	return s, nil
+}
+
+// IsValid reports whether s is one of the defined statuses.
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusTodo, StatusInProgress, StatusReview, StatusDone, StatusWontDo:
+		return true
+	}
+	return false
+}
+
+// StatusOf returns the status a task has when all that is known is whether it is done.
+func StatusOf(done bool) Status {
+	if done {
+		return StatusDone
+	}
+	return StatusTodo
+}
+
+// Workflow lists the statuses a task may move to from each status.
+// Staying in the same status is always allowed.
+type Workflow map[Status][]Status
+
+// DefaultWorkflow moves tasks from todo through in progress and review to done,
+// lets them be given up on at any point and reopens finished ones as todo.
+var DefaultWorkflow = Workflow{
+	StatusTodo:       {StatusInProgress, StatusDone, StatusWontDo},
+	StatusInProgress: {StatusTodo, StatusReview, StatusDone, StatusWontDo},
+	StatusReview:     {StatusInProgress, StatusDone, StatusWontDo},
+	StatusDone:       {StatusTodo},
+	StatusWontDo:     {StatusTodo},
+}
+
+// Allows reports whether a task may move from one status to the other.
+func (w Workflow) Allows(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range w[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package todo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidTag = errors.New("invalid tag")
+
+// Tag is a label used to group tasks, e.g. by component or theme.
+// TaskCount is the number of tasks the tag is attached to.
+type Tag struct {
+	Name      string
+	TaskCount int
+}
+
+// NormalizeTags lowercases and trims the given tag names, removes duplicates
+// and sorts them so that equal sets of tags always compare equal.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.ContainsAny(tag, ",") {
+			return nil, ErrInvalidTag
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// HasTags reports whether the task carries any (or, if all is set, every) of the given tags.
+func (t Task) HasTags(tags []string, all bool) bool {
+	for _, want := range tags {
+		found := false
+		for _, have := range t.Tags {
+			if have == want {
+				found = true
+				break
+			}
+		}
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+	return all
+}
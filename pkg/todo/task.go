@@ -3,26 +3,163 @@ package todo
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 )
 
 var (
 	ErrTaskNotFound      = errors.New("task not found")
 	ErrTaskAlreadyExists = errors.New("task already exists")
+	ErrInvalidPriority   = errors.New("invalid task priority")
+	ErrVersionConflict   = errors.New("task has been modified since it was read")
+
+	ErrParentTaskNotFound = errors.New("parent task not found")
+	ErrSubtaskCycle       = errors.New("task cannot be a subtask of itself or of its subtasks")
+	ErrSubtaskTooDeep     = errors.New("subtask nesting is too deep")
+	ErrParentTaskTrashed  = errors.New("parent task is in the trash")
 )
 
+// Priority indicates how important a task is relative to others.
+// The zero value means no priority was given.
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = map[Priority]string{
+	PriorityLow:    "low",
+	PriorityNormal: "normal",
+	PriorityHigh:   "high",
+	PriorityUrgent: "urgent",
+}
+
+// ParsePriority returns the Priority with the given name.
+// An empty name yields the zero Priority.
+func ParsePriority(name string) (Priority, error) {
+	if name == "" {
+		return 0, nil
+	}
+	for p, n := range priorityNames {
+		if n == name {
+			return p, nil
+		}
+	}
+	return 0, ErrInvalidPriority
+}
+
+// IsValid reports whether p is one of the defined priorities.
+func (p Priority) IsValid() bool {
+	_, ok := priorityNames[p]
+	return ok
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
 // Task represents a task that may need to be performed.
 // It is purely a domain entity with no context of usecases or applications.
 type Task struct {
-	ID   int64
-	Name string
-	Done bool
+	ID          int64
+	ChecklistID int64 // zero if the task doesn't belong to any checklist
+	ParentID    int64 // zero if the task isn't a subtask
+	AssigneeID  int64 // zero if nobody is assigned to the task
+	Name        string
+	Description string // long-form notes in Markdown
+	Done        bool
+	Status      Status // kept in step with Done, see Status
+	DueDate     *time.Time
+	Priority    Priority
+	Tags        []string
+	Recurrence  *Recurrence // nil if the task doesn't repeat
+	// Estimate is how long the task is expected to take, zero if nobody estimated it.
+	Estimate time.Duration
+	// Rank is where the user placed the task relative to the others, see RankBetween.
+	Rank string
+	// Version is incremented whenever the task is updated, starting at 1 when it is inserted.
+	Version int64
+	// DeletedAt is when the task was moved to the trash, nil if it wasn't.
+	DeletedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// CompletedAt is when the task was last marked as done, nil while it is pending.
+	CompletedAt *time.Time
+	// ArchivedAt is when the task was archived for having been done for a while,
+	// nil if it wasn't. Reopening the task takes it out of the archive.
+	ArchivedAt *time.Time
+}
+
+// IsOverdue reports whether the task is still pending after its due date has passed.
+func (t Task) IsOverdue(now time.Time) bool {
+	return !t.Done && t.DueDate != nil && t.DueDate.Before(now)
+}
+
+// Less reports whether t should be listed before u: higher priorities come first,
+// then earlier due dates, with tasks that have no due date last. Ties are broken by ID.
+func (t Task) Less(u Task) bool {
+	if t.Priority != u.Priority {
+		return t.Priority > u.Priority
+	}
+	if (t.DueDate == nil) != (u.DueDate == nil) {
+		return t.DueDate != nil
+	}
+	if t.DueDate != nil && !t.DueDate.Equal(*u.DueDate) {
+		return t.DueDate.Before(*u.DueDate)
+	}
+	return t.ID < u.ID
 }
 
 // TaskRepository is the interface used to persist the Task(s).
+// Tasks in the trash are left out by every method unless it says otherwise.
 type TaskRepository interface {
 	Insert(context.Context, *Task) error
-	FindAll(context.Context) ([]Task, error)
+	// InsertAll inserts the tasks in a single transaction and sets their IDs,
+	// so that either all of them are stored or, if any fails, none is.
+	InsertAll(context.Context, []Task) error
+	// FindAll returns the tasks matching the query in the order it asks for,
+	// starting after its cursor and returning no more than its limit.
+	FindAll(context.Context, TaskQuery) ([]Task, error)
+	// FindAllDueBetween returns the tasks due in the half-open range [after, before).
+	// A zero time leaves that end of the range unbounded.
+	FindAllDueBetween(ctx context.Context, after, before time.Time) ([]Task, error)
+	// FindAllByTags returns the tasks carrying any of the given tags,
+	// or every one of them if all is set.
+	FindAllByTags(ctx context.Context, tags []string, all bool) ([]Task, error)
+	// FindAllTags returns every tag in use along with the number of tasks carrying it.
+	FindAllTags(context.Context) ([]Tag, error)
+	// FindAllByChecklistID returns the tasks that belong to the given checklist, in rank order.
+	FindAllByChecklistID(ctx context.Context, checklistID int64) ([]Task, error)
+	// FindAllByParentID returns the direct subtasks of the given task, in rank order.
+	FindAllByParentID(ctx context.Context, parentID int64) ([]Task, error)
 	FindByID(ctx context.Context, id int64) (*Task, error)
+	// Update replaces the stored task and sets its Version to the incremented one.
+	// Unless the given Version is zero, it fails with ErrVersionConflict if the task
+	// has been updated since that version was read.
 	Update(context.Context, *Task) error
-	DeleteByID(ctx context.Context, id int64) error
+	// DeleteByID moves the task along with all of its subtasks to the trash at the given time.
+	// Unless version is zero, it fails with ErrVersionConflict if the task has been updated
+	// since then.
+	DeleteByID(ctx context.Context, id, version int64, at time.Time) error
+	// DeleteAllByChecklistID permanently deletes the tasks of the checklist, trashed or not.
+	DeleteAllByChecklistID(ctx context.Context, checklistID int64) error
+
+	// FindAllDeleted returns the tasks in the trash, most recently trashed first.
+	FindAllDeleted(context.Context) ([]Task, error)
+	// RestoreByID takes the task out of the trash along with the subtasks that were trashed with it.
+	// It fails with ErrParentTaskTrashed if the task's parent is still in the trash.
+	RestoreByID(ctx context.Context, id int64) error
+	// PurgeDeletedBefore permanently deletes the tasks trashed before the given time
+	// and returns how many there were.
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// ArchiveCompletedBefore archives the tasks completed before the given time that
+	// aren't archived yet, stamping them with at, and returns how many there were.
+	ArchiveCompletedBefore(ctx context.Context, before, at time.Time) (int64, error)
 }
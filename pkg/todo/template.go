@@ -0,0 +1,63 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrEmptyTemplate    = errors.New("template must have at least one task")
+	ErrInvalidDueOffset = errors.New("due offset must be a duration such as 48h")
+)
+
+// Template is a named set of tasks that can be created together time and again,
+// e.g. the steps of a release or of onboarding a new colleague.
+type Template struct {
+	ID    int64
+	Name  string
+	Tasks []TemplateTask
+}
+
+// TemplateTask describes one of the tasks a Template creates.
+type TemplateTask struct {
+	Name        string
+	Description string
+	Priority    Priority
+	Tags        []string
+	Estimate    time.Duration
+	// DueOffset is how long after the template is instantiated the task is due,
+	// nil if the task has no due date.
+	DueOffset *time.Duration
+}
+
+// Instantiate returns the tasks the template describes as they are when it is
+// instantiated at start, in the order the template lists them.
+func (t Template) Instantiate(start time.Time) []Task {
+	tasks := make([]Task, 0, len(t.Tasks))
+	for _, tt := range t.Tasks {
+		task := Task{
+			Name:        tt.Name,
+			Description: tt.Description,
+			Priority:    tt.Priority,
+			Tags:        append([]string(nil), tt.Tags...),
+			Estimate:    tt.Estimate,
+		}
+		if tt.DueOffset != nil {
+			due := start.Add(*tt.DueOffset)
+			task.DueDate = &due
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// TemplateRepository is the interface used to persist the Template(s).
+type TemplateRepository interface {
+	Insert(context.Context, *Template) error
+	FindAll(context.Context) ([]Template, error)
+	FindByID(ctx context.Context, id int64) (*Template, error)
+	Update(context.Context, *Template) error
+	DeleteByID(ctx context.Context, id int64) error
+}
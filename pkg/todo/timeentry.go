@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTimeEntryNotFound = errors.New("time entry not found")
+	ErrTimerRunning      = errors.New("a timer is already running")
+	ErrNoTimerRunning    = errors.New("no timer is running on the task")
+	ErrInvalidTimeEntry  = errors.New("time entry must end after it starts")
+	ErrInvalidEstimate   = errors.New("estimate must be a non-negative duration such as 1h30m")
+)
+
+// TimeEntry is a stretch of time somebody spent working on a task, either
+// tracked with a timer or entered manually once the work was done.
+type TimeEntry struct {
+	ID     int64
+	TaskID int64
+	User   string // the actor who did the work
+	Start  time.Time
+	End    *time.Time // nil while the timer is running
+}
+
+// IsRunning reports whether the entry is a timer that hasn't been stopped yet.
+func (e TimeEntry) IsRunning() bool {
+	return e.End == nil
+}
+
+// Duration returns how long the entry lasted, or has lasted until now if it is still running.
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	if e.End == nil {
+		return now.Sub(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+// TimeSummary compares the time estimated for some tasks with the time spent on them.
+type TimeSummary struct {
+	Estimate time.Duration
+	Spent    time.Duration
+}
+
+// Summarize adds up the estimates of the tasks and the time spent on them according to the entries.
+func Summarize(tasks []Task, entries []TimeEntry, now time.Time) TimeSummary {
+	var summary TimeSummary
+	for _, task := range tasks {
+		summary.Estimate += task.Estimate
+	}
+	for _, entry := range entries {
+		summary.Spent += entry.Duration(now)
+	}
+	return summary
+}
+
+// TimeEntryRepository is the interface used to persist the TimeEntry(s) of tasks.
+type TimeEntryRepository interface {
+	// Insert adds the entry and sets its ID. A running entry fails with
+	// ErrTimerRunning if its user already has another timer running.
+	Insert(context.Context, *TimeEntry) error
+	// FindAllByTaskIDs returns the entries of any of the tasks, earliest first.
+	FindAllByTaskIDs(ctx context.Context, taskIDs []int64) ([]TimeEntry, error)
+	FindByID(ctx context.Context, id int64) (*TimeEntry, error)
+	// FindRunning returns the timer the user has running, or ErrTimeEntryNotFound if there is none.
+	FindRunning(ctx context.Context, user string) (*TimeEntry, error)
+	// Update replaces the start and end of the stored entry.
+	Update(context.Context, *TimeEntry) error
+	DeleteByID(ctx context.Context, id int64) error
+	// DeleteAllByTaskIDs deletes the entries of any of the tasks.
+	DeleteAllByTaskIDs(ctx context.Context, taskIDs []int64) error
+}
@@ -0,0 +1,29 @@
+package todo
+
+import "context"
+
+// Repositories are the repositories the data of the app is stored in, and those the
+// changes of a UnitOfWork are made through.
+type Repositories struct {
+	Tasks        TaskRepository
+	Checklists   ChecklistRepository
+	History      HistoryRepository
+	Comments     CommentRepository
+	Dependencies DependencyRepository
+	Users        UserRepository
+	TimeEntries  TimeEntryRepository
+	Templates    TemplateRepository
+	Reminders    ReminderRepository
+	Attachments  AttachmentRepository
+}
+
+// UnitOfWork makes several changes to the repositories atomically. Units of work run
+// one at a time, so what one reads can't be changed by another until it ends, and checks
+// made on its reads still hold when its changes are kept. Changes made to the repositories
+// outside any unit of work aren't held off, though.
+// A BlobStore isn't part of a unit of work, as blobs can't be rolled back.
+type UnitOfWork interface {
+	// Do calls fn with repositories whose changes are all kept if fn succeeds,
+	// or all discarded if it returns an error, which Do then returns.
+	Do(ctx context.Context, fn func(Repositories) error) error
+}
@@ -0,0 +1,40 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrInvalidUserName   = errors.New("user name must not be blank")
+	ErrAssigneeNotFound  = errors.New("assignee not found")
+)
+
+// User is someone tasks can be assigned to. Users are known by their Name,
+// which is also who changes made on their behalf are attributed to.
+type User struct {
+	ID   int64
+	Name string
+}
+
+// NormalizeUserName trims the name and checks that something is left of it.
+func NormalizeUserName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrInvalidUserName
+	}
+	return name, nil
+}
+
+// UserRepository is the interface used to persist the User(s).
+type UserRepository interface {
+	// Insert adds the user, failing with ErrUserAlreadyExists if the name is taken.
+	Insert(context.Context, *User) error
+	// FindAll returns every user ordered by name.
+	FindAll(context.Context) ([]User, error)
+	FindByID(ctx context.Context, id int64) (*User, error)
+	FindByName(ctx context.Context, name string) (*User, error)
+}